@@ -0,0 +1,338 @@
+// Package config loads the bot's settings from an optional YAML file overlaid with
+// environment variables, and checks them before anything starts.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"hrc-go/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix starts the variables that override file settings, named after their YAML
+// path: HRC_ECONOMY_STARTING_CHIPS sets economy.starting_chips
+const envPrefix = "HRC_"
+
+// tokenVars are the variables the bot token is read from, in order
+var tokenVars = []string{"BOT_TOKEN", "DISCORD_TOKEN", "DISCORD_BOT_TOKEN", "HRC_BOT_TOKEN"}
+
+// Config is everything the bot can be configured with. Secrets are only read from the
+// environment so they never end up in a config file.
+type Config struct {
+	Token       string      `yaml:"-"`
+	DatabaseURL string      `yaml:"-"`
+	TopGG       TopGGConfig `yaml:"topgg"`
+
+	Port                   string         `yaml:"port"` // health, metrics and webhook server
+	ForceCommandReregister bool           `yaml:"force_command_reregister"`
+	GuildMembersIntent     bool           `yaml:"guild_members_intent"` // privileged; enable it in the developer portal first
+	PrestigeTrackFile      string         `yaml:"prestige_track_file"`  // optional JSON prestige track
+	AchievementsFile       string         `yaml:"achievements_file"`    // replaces the built-in achievements
+	Shutdown               ShutdownConfig `yaml:"shutdown"`
+
+	IDs       utils.DiscordIDs     `yaml:"ids"`
+	Economy   utils.EconomyConfig  `yaml:"economy"`
+	Bonuses   utils.BonusConfig    `yaml:"bonuses"`
+	Jackpots  utils.JackpotConfig  `yaml:"jackpots"`
+	Games     utils.GameConfig     `yaml:"games"`
+	Transfers utils.TransferConfig `yaml:"transfers"`
+	Seasons   utils.SeasonConfig   `yaml:"seasons"`
+}
+
+// TopGGConfig connects the bot to Top.gg for vote checks and the vote webhook
+type TopGGConfig struct {
+	Token         string `yaml:"-"` // API token; vote checks are off without it
+	WebhookSecret string `yaml:"-"` // the webhook is off without it
+	WeekendDouble bool   `yaml:"weekend_double"`
+}
+
+// ShutdownConfig bounds a graceful shutdown; together the deadlines should stay inside
+// the orchestrator's grace period
+type ShutdownConfig struct {
+	GameDrain time.Duration `yaml:"game_drain"` // games in progress get this long to finish
+	TaskDrain time.Duration `yaml:"task_drain"` // queued background tasks get this long to run
+}
+
+// Default returns the built-in configuration
+func Default() *Config {
+	games := utils.Games
+	games.BetLimits = maps.Clone(games.BetLimits)
+	games.Timeouts = maps.Clone(games.Timeouts)
+
+	return &Config{
+		Port: "8080",
+		Shutdown: ShutdownConfig{
+			GameDrain: 20 * time.Second,
+			TaskDrain: 5 * time.Second,
+		},
+		IDs:       utils.IDs,
+		Economy:   utils.Economy,
+		Bonuses:   utils.DefaultBonusConfig(),
+		Jackpots:  utils.DefaultJackpotConfig(),
+		Games:     games,
+		Transfers: utils.DefaultTransferConfig(),
+		Seasons:   utils.DefaultSeasonConfig(),
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML file at path if one is
+// given, and the environment, in that order, and validates the result
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := cfg.decode(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.overlayEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// decode merges a YAML document over cfg; unknown keys are rejected so typos don't
+// silently fall back to defaults
+func (c *Config) decode(data []byte) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return nil
+}
+
+// overlayEnv applies the environment over cfg: the variables the bot has always read,
+// then an HRC_ variable for any scalar setting in the file
+func (c *Config) overlayEnv(lookup func(string) (string, bool)) error {
+	get := func(key string) string {
+		v, _ := lookup(key)
+		return strings.TrimSpace(v)
+	}
+	for _, key := range tokenVars {
+		if v := get(key); v != "" {
+			c.Token = v
+			break
+		}
+	}
+	if v := get("DATABASE_URL"); v != "" {
+		c.DatabaseURL = v
+	}
+	if v := get("TOPGG_TOKEN"); v != "" {
+		c.TopGG.Token = v
+	}
+	if v := get("TOPGG_WEBHOOK_SECRET"); v != "" {
+		c.TopGG.WebhookSecret = v
+	}
+	if v := get("PORT"); v != "" {
+		c.Port = v
+	}
+	if v := get("PRESTIGE_TRACK_FILE"); v != "" {
+		c.PrestigeTrackFile = v
+	}
+	if v := get("ACHIEVEMENTS_FILE"); v != "" {
+		c.AchievementsFile = v
+	}
+
+	var errs []error
+	for _, flag := range []struct {
+		key   string
+		field *bool
+	}{
+		{"TOPGG_WEEKEND_DOUBLE", &c.TopGG.WeekendDouble},
+		{"FORCE_COMMAND_REREGISTER", &c.ForceCommandReregister},
+	} {
+		if v := get(flag.key); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not a boolean", flag.key, v))
+				continue
+			}
+			*flag.field = b
+		}
+	}
+
+	errs = append(errs, overlayFields(reflect.ValueOf(c).Elem(), envPrefix, lookup)...)
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid environment: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// overlayFields sets each scalar field of v from the variable named by prefix and the
+// field's YAML path. Maps and lists can only be set from the file.
+func overlayFields(v reflect.Value, prefix string, lookup func(string) (string, bool)) []error {
+	var errs []error
+	for n := range v.NumField() {
+		name, _, _ := strings.Cut(v.Type().Field(n).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		field := v.Field(n)
+		key := prefix + strings.ToUpper(name)
+		if field.Kind() == reflect.Struct {
+			errs = append(errs, overlayFields(field, key+"_", lookup)...)
+			continue
+		}
+		raw, ok := lookup(key)
+		if !ok {
+			continue
+		}
+		if err := setScalar(field, strings.TrimSpace(raw)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	return errs
+}
+
+// setScalar parses raw into a string, bool, number or duration field
+func setScalar(field reflect.Value, raw string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration", raw)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", raw)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", raw)
+		}
+		field.SetInt(i)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("can only be set in the config file")
+	}
+	return nil
+}
+
+// Validate reports every setting that is out of range, joined into one error
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	port, err := strconv.Atoi(c.Port)
+	check(err == nil && port > 0 && port <= 65535, "port: %q is not a valid port", c.Port)
+	check(c.Shutdown.GameDrain > 0, "shutdown.game_drain must be positive")
+	check(c.Shutdown.TaskDrain > 0, "shutdown.task_drain must be positive")
+
+	for _, id := range []struct{ name, value string }{
+		{"bot_id", c.IDs.BotID},
+		{"home_guild_id", c.IDs.HomeGuildID},
+		{"dev_guild_id", c.IDs.DevGuildID},
+		{"premium_role_id", c.IDs.PremiumRoleID},
+		{"admin_role_id", c.IDs.AdminRoleID},
+		{"admin_log_channel_id", c.IDs.AdminLogChannelID},
+	} {
+		_, err := strconv.ParseUint(id.value, 10, 64)
+		check(id.value == "" || err == nil, "ids.%s: %q is not a Discord ID", id.name, id.value)
+	}
+	check(c.IDs.BotID != "", "ids.bot_id is required")
+	check(c.IDs.HomeGuildID != "", "ids.home_guild_id is required")
+
+	check(c.Economy.StartingChips > 0, "economy.starting_chips must be positive")
+	check(c.Economy.XPPerProfit >= 0, "economy.xp_per_profit can't be negative")
+
+	for _, bonusType := range []utils.BonusType{utils.BonusHourly, utils.BonusDaily, utils.BonusWeekly, utils.BonusVote, utils.BonusServer} {
+		rule, _ := c.Bonuses.Rule(bonusType)
+		check(rule.Base >= 0 && rule.PerPrestige >= 0 && rule.PerLevel >= 0 && rule.XP >= 0,
+			"bonuses.%s: amounts can't be negative", bonusType)
+		check(rule.Cooldown > 0, "bonuses.%s.cooldown must be positive", bonusType)
+	}
+	check(c.Bonuses.MaxRankMultiplier >= 1, "bonuses.max_rank_multiplier must be at least 1")
+	check(c.Bonuses.PrestigeMultiplier >= 0, "bonuses.prestige_multiplier can't be negative")
+	check(c.Bonuses.MaxPrestigeMultiplier >= 1, "bonuses.max_prestige_multiplier must be at least 1")
+
+	check(c.Jackpots.SlotsSeed >= 0, "jackpots.slots_seed can't be negative")
+	check(c.Jackpots.SlotsContributionRate >= 0 && c.Jackpots.SlotsContributionRate <= 1,
+		"jackpots.slots_contribution_rate must be between 0 and 1")
+	check(c.Jackpots.SlotsLossRate >= 0 && c.Jackpots.SlotsLossRate <= 1,
+		"jackpots.slots_loss_rate must be between 0 and 1")
+	check(c.Jackpots.MinimumAmount >= 0, "jackpots.minimum_amount can't be negative")
+
+	for _, game := range slices.Sorted(maps.Keys(c.Games.BetLimits)) {
+		limit := c.Games.BetLimits[game]
+		check(utils.IsGameType(game), "games.bet_limits: unknown game %q", game)
+		check(limit.Min >= 0 && limit.Max >= 0, "games.bet_limits.%s can't be negative", game)
+		check(limit.Max == 0 || limit.Max >= limit.Min, "games.bet_limits.%s: max is below min", game)
+	}
+	for _, game := range slices.Sorted(maps.Keys(c.Games.Timeouts)) {
+		check(utils.IsGameType(game), "games.timeouts: unknown game %q", game)
+		check(c.Games.Timeouts[game] > 0, "games.timeouts.%s must be positive", game)
+	}
+
+	check(c.Transfers.TaxRate >= 0 && c.Transfers.TaxRate < 1, "transfers.tax_rate must be at least 0 and below 1")
+	check(c.Transfers.MinAmount > 0, "transfers.min_amount must be positive")
+	check(c.Transfers.DailySendCap >= 0 && c.Transfers.DailyReceiveCap >= 0, "transfers: daily caps can't be negative")
+	check(c.Transfers.MinAccountAge >= 0, "transfers.min_account_age can't be negative")
+	check(c.Transfers.MinGamesPlayed >= 0, "transfers.min_games_played can't be negative")
+	check(c.Transfers.ConfirmThreshold >= 0, "transfers.confirm_threshold can't be negative")
+
+	check(c.Seasons.Length > 0, "seasons.length must be positive")
+	check(slices.Contains(utils.SeasonBoards, c.Seasons.RewardBoard), "seasons.reward_board: unknown board %q", c.Seasons.RewardBoard)
+	check(c.Seasons.ResultsKept > 0, "seasons.results_kept must be positive")
+	ranks := make(map[int]bool, len(c.Seasons.Rewards))
+	for _, r := range c.Seasons.Rewards {
+		check(r.Rank > 0, "seasons.rewards: rank %d must be positive", r.Rank)
+		check(!ranks[r.Rank], "seasons.rewards: rank %d is listed twice", r.Rank)
+		check(r.Chips >= 0, "seasons.rewards: rank %d pays negative chips", r.Rank)
+		ranks[r.Rank] = true
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Apply makes the settings read on every request active: the Discord IDs, the economy
+// and the game rules. It must run before the bot connects. Bonuses, jackpots, transfers
+// and seasons are not applied here; they are handed to the subsystems that own them.
+//
+// utils.IDs, utils.Economy and utils.Games are the only configuration kept in package
+// globals, because nearly every command and game reads them. Any new section should be
+// passed to the code that owns it, like Bonuses, rather than given a global set here.
+func (c *Config) Apply() {
+	utils.IDs = c.IDs
+	utils.Economy = c.Economy
+	utils.Games = c.Games
+}
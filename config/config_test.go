@@ -0,0 +1,143 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"hrc-go/utils"
+)
+
+func TestDefaultIsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("default configuration is invalid: %v", err)
+	}
+}
+
+func TestDecodeMergesOverDefaults(t *testing.T) {
+	cfg := Default()
+	err := cfg.decode([]byte(`
+economy:
+  starting_chips: 5000
+bonuses:
+  daily:
+    cooldown: 20h
+games:
+  timeouts:
+    mines: 2m
+  bet_limits:
+    slots: {min: 10, max: 1000}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Economy.StartingChips != 5000 || cfg.Economy.XPPerProfit != Default().Economy.XPPerProfit {
+		t.Errorf("economy = %+v", cfg.Economy)
+	}
+	if cfg.Bonuses.Daily.Cooldown != 20*time.Hour || cfg.Bonuses.Daily.Base != Default().Bonuses.Daily.Base {
+		t.Errorf("daily bonus = %+v", cfg.Bonuses.Daily)
+	}
+	if cfg.Games.Timeout("mines") != 2*time.Minute || cfg.Games.Timeout("blackjack") != Default().Games.Timeout("blackjack") {
+		t.Errorf("timeouts = %v", cfg.Games.Timeouts)
+	}
+	if limit := cfg.Games.BetLimits["slots"]; limit.Min != 10 || limit.Max != 1000 {
+		t.Errorf("slots limit = %+v", limit)
+	}
+}
+
+func TestDecodeRejectsUnknownKeys(t *testing.T) {
+	if err := Default().decode([]byte("economy:\n  startng_chips: 5000\n")); err == nil {
+		t.Error("misspelled key was accepted")
+	}
+}
+
+func TestOverlayEnv(t *testing.T) {
+	env := map[string]string{
+		"DISCORD_TOKEN":                " token ",
+		"TOPGG_WEEKEND_DOUBLE":         "true",
+		"HRC_ECONOMY_XP_PER_PROFIT":    "3",
+		"HRC_BONUSES_VOTE_COOLDOWN":    "6h",
+		"HRC_JACKPOTS_SLOTS_SEED":      "9000",
+		"HRC_SEASONS_REWARD_BOARD":     "wins",
+		"HRC_IDS_HOME_GUILD_ID":        "42",
+		"HRC_SHUTDOWN_GAME_DRAIN":      "10s",
+		"HRC_TRANSFERS_TAX_RATE":       "0.1",
+		"HRC_FORCE_COMMAND_REREGISTER": "1",
+	}
+	lookup := func(key string) (string, bool) { v, ok := env[key]; return v, ok }
+
+	cfg := Default()
+	if err := cfg.overlayEnv(lookup); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case cfg.Token != "token":
+		t.Errorf("token = %q", cfg.Token)
+	case !cfg.TopGG.WeekendDouble || !cfg.ForceCommandReregister:
+		t.Error("boolean flags were not set")
+	case cfg.Economy.XPPerProfit != 3, cfg.Bonuses.Vote.Cooldown != 6*time.Hour, cfg.Jackpots.SlotsSeed != 9000:
+		t.Errorf("tunables were not set: %+v %+v %+v", cfg.Economy, cfg.Bonuses.Vote, cfg.Jackpots)
+	case cfg.Seasons.RewardBoard != "wins", cfg.IDs.HomeGuildID != "42":
+		t.Errorf("strings were not set: %q %q", cfg.Seasons.RewardBoard, cfg.IDs.HomeGuildID)
+	case cfg.Shutdown.GameDrain != 10*time.Second, cfg.Transfers.TaxRate != 0.1:
+		t.Errorf("shutdown %+v, tax %v", cfg.Shutdown, cfg.Transfers.TaxRate)
+	}
+}
+
+func TestOverlayEnvRejectsBadValues(t *testing.T) {
+	env := map[string]string{
+		"HRC_ECONOMY_STARTING_CHIPS": "lots",
+		"HRC_BONUSES_DAILY_COOLDOWN": "a day",
+		"TOPGG_WEEKEND_DOUBLE":       "sometimes",
+	}
+	lookup := func(key string) (string, bool) { v, ok := env[key]; return v, ok }
+
+	err := Default().overlayEnv(lookup)
+	if err == nil {
+		t.Fatal("bad values were accepted")
+	}
+	for key := range env {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("error does not name %s: %v", key, err)
+		}
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := Default()
+	cfg.Port = "http"
+	cfg.IDs.AdminRoleID = "admins"
+	cfg.Economy.StartingChips = 0
+	cfg.Bonuses.Hourly.Cooldown = 0
+	cfg.Jackpots.SlotsContributionRate = 1.5
+	cfg.Games.Timeouts["poker"] = time.Minute
+	cfg.Games.BetLimits["slots"] = utils.BetLimit{Min: 500, Max: 100}
+	cfg.Seasons.RewardBoard = "luck"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("invalid configuration passed")
+	}
+	for _, want := range []string{"port", "ids.admin_role_id", "economy.starting_chips", "bonuses.hourly.cooldown",
+		"jackpots.slots_contribution_rate", `unknown game "poker"`, "games.bet_limits.slots", "seasons.reward_board"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %s: %v", want, err)
+		}
+	}
+}
+
+func TestExampleMatchesDefaults(t *testing.T) {
+	data, err := os.ReadFile("../config.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := Default()
+	if err := cfg.decode(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("config.example.yaml has drifted from the defaults:\n%+v\n%+v", cfg, Default())
+	}
+}
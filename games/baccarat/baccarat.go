@@ -10,7 +10,22 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-var activeBaccaratGames = make(map[int64]*Game)
+func init() {
+	utils.RegisterGameModule(utils.GameModule{
+		GameType:      "baccarat",
+		Command:       RegisterBaccaratCommand(),
+		HandleCommand: HandleBaccaratCommand,
+		Components:    []utils.Route{{Prefix: "baccarat_", Handle: HandleBaccaratButton}},
+	})
+}
+
+// userGame returns the user's active baccarat game, if any
+func userGame(userID int64) (*Game, bool) {
+	if g, ok := utils.GameStateMgr.GetUserGame("baccarat", userID); ok {
+		return g.(*Game), true
+	}
+	return nil, false
+}
 
 type Game struct {
 	*utils.BaseGame
@@ -38,7 +53,7 @@ func RegisterBaccaratCommand() *discordgo.ApplicationCommand {
 
 func HandleBaccaratCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	userID, _ := utils.ParseUserID(i.Member.User.ID)
-	if _, exists := activeBaccaratGames[userID]; exists {
+	if _, exists := userGame(userID); exists {
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Baccarat", "You already have an active baccarat game.", 0xFF0000), nil, true)
 		return
 	}
@@ -59,22 +74,49 @@ func HandleBaccaratCommand(s *discordgo.Session, i *discordgo.InteractionCreate)
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Error", "Invalid bet amount.", 0xFF0000), nil, true)
 		return
 	}
-	game := &Game{BaseGame: utils.NewBaseGame(s, i, betAmount, "baccarat"), Deck: utils.NewDeck(6, "baccarat"), CreatedAt: time.Now()}
+	game := &Game{BaseGame: utils.NewBaseGame(s, i, betAmount, "baccarat"), CreatedAt: time.Now()}
 	if err := game.BaseGame.ValidateBet(); err != nil {
-		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Error", err.Error(), 0xFF0000), nil, true)
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Error", utils.BetErrorMessage(err), 0xFF0000), nil, true)
+		return
+	}
+	game.Deck = game.BaseGame.NewFairDeck(6, "baccarat")
+	if _, ok := utils.GameStateMgr.RegisterUserGame(game); !ok {
+		game.BaseGame.RefundStake()
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Baccarat", "You already have an active baccarat game.", 0xFF0000), nil, true)
 		return
 	}
-	activeBaccaratGames[userID] = game
 	// Send selection embed with buttons
 	embed := baccaratStartEmbed(game)
 	components := baccaratChoiceComponents()
 	utils.SendInteractionResponse(s, i, embed, components, false)
 }
 
+// GetExpiresAt returns when an undecided game is refunded
+func (g *Game) GetExpiresAt() time.Time {
+	return g.CreatedAt.Add(utils.Games.Timeout("baccarat"))
+}
+
+// IsExpired reports whether the player has not picked a side within the baccarat timeout
+func (g *Game) IsExpired() bool {
+	return !g.Finished && g.Choice == "" && time.Now().After(g.GetExpiresAt())
+}
+
+// Cleanup refunds the stake if no side was chosen before the timeout
+func (g *Game) Cleanup() {
+	if g.Finished || g.Choice != "" {
+		return
+	}
+	g.Finished = true
+	if _, err := g.BaseGame.RefundStake(); err != nil {
+		utils.BotLogf("baccarat", "refund failed for user %d: %v", g.UserID, err)
+	}
+	_ = utils.EditOriginalInteraction(g.Session, g.BaseGame.Interaction, utils.GameRefundEmbed(g.Bet), []discordgo.MessageComponent{})
+}
+
 // HandleBaccaratButton handles side selection via buttons
 func HandleBaccaratButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	userID, _ := utils.ParseUserID(i.Member.User.ID)
-	game, exists := activeBaccaratGames[userID]
+	game, exists := userGame(userID)
 	if !exists {
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Baccarat", "No active baccarat game found.", 0xFF0000), nil, true)
 		return
@@ -191,7 +233,7 @@ func (g *Game) finishViaComponentUpdate(s *discordgo.Session, i *discordgo.Inter
 	updatedUser, _ := g.BaseGame.EndGame(g.Profit)
 	var xpGain int64
 	if g.Profit > 0 {
-		xpGain = g.Profit * utils.XPPerProfit
+		xpGain = g.Profit * utils.Economy.XPPerProfit
 	}
 	if g.BaseGame != nil && g.BaseGame.UserData != nil && !utils.ShouldShowXPGained(g.BaseGame.Interaction.Member, g.BaseGame.UserData) {
 		xpGain = 0
@@ -212,13 +254,14 @@ func (g *Game) finishViaComponentUpdate(s *discordgo.Session, i *discordgo.Inter
 		// As a secondary attempt, try sending an ephemeral ack if still possible.
 		_ = utils.TryEphemeralFollowup(s, i, "⚠️ Display update failed, showing result above.")
 	}
-	delete(activeBaccaratGames, g.UserID)
+	utils.GameStateMgr.UnregisterGame(g.GameType, g.GameID)
 }
 
 // Start phase embed
 func baccaratStartEmbed(g *Game) *discordgo.MessageEmbed {
 	msg := fmt.Sprintf("You are betting %s %s.\nChoose your side.", utils.FormatChips(g.Bet), utils.ChipsEmoji)
 	embed := utils.CreateBrandedEmbed("Baccarat", msg, utils.BotColor)
+	utils.AnnotateFairness(embed, g.BaseGame.Round)
 	return embed
 }
 
@@ -267,6 +310,7 @@ func baccaratResultEmbed(g *Game, newBalance int64, xpGain int64) *discordgo.Mes
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "XP Gained", Value: fmt.Sprintf("%s XP", utils.FormatChips(xpGain)), Inline: false})
 	}
 	embed.Footer.Text += " | Game Over"
+	utils.AnnotateFairness(embed, g.BaseGame.Round)
 	return embed
 }
 
@@ -1,6 +1,7 @@
 package blackjack
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -12,10 +13,6 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-// ActiveGames stores currently active blackjack games
-var ActiveGames = make(map[string]*BlackjackGame)
-var gamesMutex sync.RWMutex
-
 // Circuit breaker for blackjack commands to prevent overload
 type BlackjackCircuitBreaker struct {
 	failures    int64
@@ -32,45 +29,28 @@ const (
 	CircuitBreakerMaxGames         = 100              // Maximum concurrent games
 )
 
-// Cleanup configuration for game state management
-const (
-	GameTimeoutDuration = 10 * time.Minute // Games timeout after 10 minutes of inactivity
-	CleanupInterval     = 2 * time.Minute  // Run cleanup every 2 minutes
-)
-
-// Initialize cleanup routine - call this from main.go during bot startup
 func init() {
-	go startGameCleanup()
-}
-
-// startGameCleanup runs a background cleanup routine to remove stale games
-func startGameCleanup() {
-	ticker := time.NewTicker(CleanupInterval)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		cleanupExpiredGames()
-	}
+	utils.RegisterGameModule(utils.GameModule{
+		GameType:      "blackjack",
+		Command:       RegisterBlackjackCommands(),
+		HandleCommand: HandleBlackjackCommand,
+		Components: []utils.Route{
+			{Prefix: "blackjack_", Handle: HandleBlackjackInteraction},
+			{Prefix: "bjtable_", Handle: HandleTableInteraction},
+		},
+		Modals:   []utils.Route{{Prefix: "bjtable_bet_modal_", Handle: HandleTableModal}},
+		NewState: func() utils.GameState { return &BlackjackGame{BaseGame: &utils.BaseGame{}} },
+		Active:   activeTables,
+		Stop:     stopTables,
+	})
 }
 
-// cleanupExpiredGames removes games that have been inactive for too long
-func cleanupExpiredGames() {
-	gamesMutex.Lock()
-	defer gamesMutex.Unlock()
-
-	now := time.Now()
-	expiredCount := 0
-
-	for gameID, game := range ActiveGames {
-		// Check if game has been inactive for too long
-		if now.Sub(game.CreatedAt) > GameTimeoutDuration {
-			delete(ActiveGames, gameID)
-			expiredCount++
-		}
-	}
-
-	if expiredCount > 0 {
+// userGame returns the user's active blackjack game, if any
+func userGame(userID int64) *BlackjackGame {
+	if g, ok := utils.GameStateMgr.GetUserGame("blackjack", userID); ok {
+		return g.(*BlackjackGame)
 	}
+	return nil
 }
 
 // Circuit breaker methods
@@ -146,17 +126,17 @@ type GameResult struct {
 	Payout    float64
 }
 
-// NewBlackjackGame creates a new blackjack game instance
+// NewBlackjackGame creates a new blackjack game instance; the shoe is added once the stake is escrowed
 func NewBlackjackGame(session *discordgo.Session, interaction *discordgo.InteractionCreate, bet int64) *BlackjackGame {
 	baseGame := utils.NewBaseGame(session, interaction, bet, "blackjack")
 
 	gameID := fmt.Sprintf("blackjack_%d_%d", baseGame.UserID, time.Now().Unix())
+	baseGame.GameID = gameID
 
 	game := &BlackjackGame{
 		BaseGame:            baseGame,
 		GameID:              gameID,
 		Bets:                []int64{bet},
-		Deck:                utils.NewDeck(utils.DeckCount, "blackjack"),
 		PlayerHands:         []*utils.Hand{utils.NewHand("blackjack")},
 		DealerHand:          utils.NewHand("blackjack"),
 		CurrentHand:         0,
@@ -218,6 +198,9 @@ func (bg *BlackjackGame) captureMessageIDAsync() {
 		bg.ChannelID = m2.ChannelID
 		bg.MessageID = m2.ID
 	}
+	if bg.State == StateActive {
+		utils.SaveGameSnapshot(bg)
+	}
 }
 
 // HandleHit handles the player hitting
@@ -253,8 +236,11 @@ func (bg *BlackjackGame) HandleDouble() error {
 		return fmt.Errorf("game is already over")
 	}
 
-	// Check if player can afford to double
-	if bg.UserData.Chips < bg.Bets[bg.CurrentHand] {
+	// Escrow the extra stake for the double
+	if err := bg.AddStake(bg.Bets[bg.CurrentHand]); err != nil {
+		if utils.IsPlayBlocked(err) {
+			return errors.New(utils.BetErrorMessage(err))
+		}
 		return fmt.Errorf("insufficient chips to double down")
 	}
 
@@ -279,8 +265,11 @@ func (bg *BlackjackGame) HandleSplit() error {
 		return fmt.Errorf("cannot split this hand")
 	}
 
-	// Check if player can afford to split
-	if bg.UserData.Chips < bg.Bets[bg.CurrentHand] {
+	// Escrow the stake for the second hand
+	if err := bg.AddStake(bg.Bets[bg.CurrentHand]); err != nil {
+		if utils.IsPlayBlocked(err) {
+			return errors.New(utils.BetErrorMessage(err))
+		}
 		return fmt.Errorf("insufficient chips to split")
 	}
 
@@ -322,12 +311,11 @@ func (bg *BlackjackGame) HandleInsurance() error {
 	if cost <= 0 {
 		return fmt.Errorf("invalid insurance cost")
 	}
-	// Ensure user can afford (total committed + cost <= chips)
-	totalCommitted := int64(0)
-	for _, b := range bg.Bets {
-		totalCommitted += b
-	}
-	if bg.UserData.Chips < totalCommitted+cost {
+	// Escrow the insurance side bet
+	if err := bg.AddStake(cost); err != nil {
+		if utils.IsPlayBlocked(err) {
+			return errors.New(utils.BetErrorMessage(err))
+		}
 		return fmt.Errorf("insufficient chips for insurance")
 	}
 	bg.InsuranceBet = cost
@@ -393,9 +381,7 @@ func (bg *BlackjackGame) finishNaturalBlackjack() error {
 
 	// Mark game as finished and clean up
 	bg.State = StateFinished
-	gamesMutex.Lock()
-	delete(ActiveGames, bg.GameID)
-	gamesMutex.Unlock()
+	utils.GameStateMgr.UnregisterGame(bg.GameType, bg.GameID)
 
 	return nil
 }
@@ -461,9 +447,7 @@ func (bg *BlackjackGame) finishGame() error {
 
 	// Mark game as finished and clean up
 	bg.State = StateFinished
-	gamesMutex.Lock()
-	delete(ActiveGames, bg.GameID)
-	gamesMutex.Unlock()
+	utils.GameStateMgr.UnregisterGame(bg.GameType, bg.GameID)
 
 	return nil
 }
@@ -628,8 +612,13 @@ func (bg *BlackjackGame) playDealerHandInstant() error {
 
 // calculateHandResult calculates the result for a specific hand
 func (bg *BlackjackGame) calculateHandResult(hand *utils.Hand, handIndex int) GameResult {
+	return handResult(hand, bg.DealerHand, handIndex)
+}
+
+// handResult settles one player hand against the dealer's finished hand
+func handResult(hand, dealer *utils.Hand, handIndex int) GameResult {
 	playerValue := hand.GetValue()
-	dealerValue := bg.DealerHand.GetValue()
+	dealerValue := dealer.GetValue()
 
 	// Player bust
 	if hand.IsBust() {
@@ -641,13 +630,13 @@ func (bg *BlackjackGame) calculateHandResult(hand *utils.Hand, handIndex int) Ga
 	}
 	// Player blackjack scenarios
 	if hand.IsBlackjack() {
-		if bg.DealerHand.IsBlackjack() {
+		if dealer.IsBlackjack() {
 			return GameResult{HandIndex: handIndex, Result: "Push.", Payout: 1.0}
 		}
 		return GameResult{HandIndex: handIndex, Result: "Blackjack! You win!", Payout: 1.0 + utils.BlackjackPayout}
 	}
 	// Dealer bust
-	if bg.DealerHand.IsBust() {
+	if dealer.IsBust() {
 		return GameResult{HandIndex: handIndex, Result: "Dealer busts! You win!", Payout: 2.0}
 	}
 	// Compare values
@@ -655,7 +644,7 @@ func (bg *BlackjackGame) calculateHandResult(hand *utils.Hand, handIndex int) Ga
 		return GameResult{HandIndex: handIndex, Result: "You win!", Payout: 2.0}
 	}
 	if playerValue < dealerValue {
-		if bg.DealerHand.IsBlackjack() {
+		if dealer.IsBlackjack() {
 			return GameResult{HandIndex: handIndex, Result: "Dealer has Blackjack. You lose.", Payout: 0.0}
 		}
 		return GameResult{HandIndex: handIndex, Result: "Dealer wins.", Payout: 0.0}
@@ -675,34 +664,35 @@ func (bg *BlackjackGame) updateViewOptions() {
 	}
 
 	currentHand := bg.PlayerHands[bg.CurrentHand]
-
-	// Basic actions
-	bg.View.CanHit = !currentHand.IsBust()
-	bg.View.CanStand = true
-
-	// Double down: only on first two cards with value 9, 10, or 11, and if player can afford doubling that specific hand
-	handValue := currentHand.GetValue()
-	bg.View.CanDouble = currentHand.Size() == 2 &&
-		(handValue == 9 || handValue == 10 || handValue == 11) &&
-		bg.UserData.Chips >= (bg.Bets[bg.CurrentHand])
-
-	// Split: only on first two cards of same rank and only if still single original hand
-	bg.View.CanSplit = currentHand.CanSplit() && len(bg.PlayerHands) == 1 && bg.UserData.Chips >= bg.Bets[bg.CurrentHand]
+	setHandActions(bg.View, currentHand, bg.Bets[bg.CurrentHand], len(bg.PlayerHands), bg.UserData.Chips)
 
 	// Insurance: dealer shows Ace, first hand only, first two cards, insurance not already taken
 	if bg.InsuranceBet == 0 && len(bg.DealerHand.Cards) > 0 && bg.DealerHand.Cards[0].IsAce() && currentHand.Size() == 2 {
-		// ensure user can afford half bet in addition to current committed bets
+		// The balance is read after escrow, so the committed hands are already taken out
 		cost := bg.Bets[bg.CurrentHand] / 2
-		totalCommitted := int64(0)
-		for _, b := range bg.Bets {
-			totalCommitted += b
-		}
-		bg.View.CanInsure = cost > 0 && bg.UserData.Chips >= (totalCommitted+cost)
+		bg.View.CanInsure = cost > 0 && bg.UserData.Chips >= cost
 	} else {
 		bg.View.CanInsure = false
 	}
 }
 
+// setHandActions enables hit, stand, double and split for the hand being played, given
+// the player's remaining balance
+func setHandActions(view *utils.BlackjackView, hand *utils.Hand, bet int64, hands int, chips int64) {
+	// Basic actions
+	view.CanHit = !hand.IsBust()
+	view.CanStand = true
+
+	// Double down: only on first two cards with value 9, 10, or 11, and if player can afford doubling that specific hand
+	handValue := hand.GetValue()
+	view.CanDouble = hand.Size() == 2 &&
+		(handValue == 9 || handValue == 10 || handValue == 11) &&
+		chips >= bet
+
+	// Split: only on first two cards of same rank and only if still single original hand
+	view.CanSplit = hand.CanSplit() && hands == 1 && chips >= bet
+}
+
 // updateGameState updates the game state display with optimized Discord calls
 func (bg *BlackjackGame) updateGameState() error {
 	// Skip update if game is finished
@@ -804,7 +794,7 @@ func (bg *BlackjackGame) createNaturalBlackjackEmbed(dealerHasBlackjack bool) *d
 	// Compute premium-gated XP for display
 	xpGain := int64(0)
 	if bg.NetProfit > 0 {
-		xpGain = bg.NetProfit * utils.XPPerProfit
+		xpGain = bg.NetProfit * utils.Economy.XPPerProfit
 		if bg.BaseGame != nil && bg.BaseGame.UserData != nil && !utils.ShouldShowXPGained(bg.BaseGame.Interaction.Member, bg.BaseGame.UserData) {
 			xpGain = 0
 		}
@@ -822,6 +812,7 @@ func (bg *BlackjackGame) createNaturalBlackjackEmbed(dealerHasBlackjack bool) *d
 		xpGain,
 		hasAces,
 	)
+	utils.AnnotateFairness(embed, bg.BaseGame.Round)
 
 	return embed
 }
@@ -888,7 +879,7 @@ func (bg *BlackjackGame) createGameEmbed(gameOver bool) *discordgo.MessageEmbed
 	// Compute premium-gated XP for display
 	xpGain := int64(0)
 	if gameOver && profit > 0 {
-		xpGain = profit * utils.XPPerProfit
+		xpGain = profit * utils.Economy.XPPerProfit
 		if bg.BaseGame != nil && bg.BaseGame.UserData != nil && !utils.ShouldShowXPGained(bg.BaseGame.Interaction.Member, bg.BaseGame.UserData) {
 			xpGain = 0
 		}
@@ -906,6 +897,7 @@ func (bg *BlackjackGame) createGameEmbed(gameOver bool) *discordgo.MessageEmbed
 		xpGain,
 		hasAces,
 	)
+	utils.AnnotateFairness(embed, bg.BaseGame.Round)
 
 	return embed
 }
@@ -927,10 +919,7 @@ func HandleBlackjackCommand(s *discordgo.Session, i *discordgo.InteractionCreate
 	}
 
 	// Check game count limit to prevent resource exhaustion
-	gamesMutex.RLock()
-	gameCount := len(ActiveGames)
-	gamesMutex.RUnlock()
-	if gameCount >= CircuitBreakerMaxGames {
+	if gameCount := utils.GameStateMgr.CountGames("blackjack"); gameCount >= CircuitBreakerMaxGames {
 		circuitBreaker.recordFailure()
 		respondWithError(s, i, "Maximum number of concurrent games reached. Please try again later.")
 		return
@@ -945,6 +934,13 @@ func HandleBlackjackCommand(s *discordgo.Session, i *discordgo.InteractionCreate
 
 	// Parse bet amount immediately to fail fast on invalid input
 	options := i.ApplicationCommandData().Options
+	if len(options) > 0 && options[0].Type == discordgo.ApplicationCommandOptionSubCommand {
+		if options[0].Name == "table" {
+			HandleTableCommand(s, i)
+			return
+		}
+		options = options[0].Options
+	}
 	if len(options) == 0 {
 		circuitBreaker.recordFailure()
 		respondWithError(s, i, "No bet amount provided")
@@ -959,21 +955,14 @@ func HandleBlackjackCommand(s *discordgo.Session, i *discordgo.InteractionCreate
 		return
 	}
 
-	// CRITICAL FIX: Atomic check-and-set to prevent race conditions
-	gamesMutex.Lock()
-	// Check for existing game while holding the lock
-	for _, existingGame := range ActiveGames {
-		if existingGame.UserID == userID {
-			gamesMutex.Unlock()
-			respondWithError(s, i, "You already have an active blackjack game. Please finish it first.")
-			return
-		}
+	if userGame(userID) != nil {
+		respondWithError(s, i, "You already have an active blackjack game. Please finish it first.")
+		return
 	}
 
 	// OPTIMIZATION: Get user data and validate bet BEFORE deferring to fail fast
 	user, err := utils.GetCachedUser(userID)
 	if err != nil {
-		gamesMutex.Unlock()
 		circuitBreaker.recordFailure()
 		respondWithError(s, i, "Failed to get user data")
 		return
@@ -982,44 +971,61 @@ func HandleBlackjackCommand(s *discordgo.Session, i *discordgo.InteractionCreate
 	// Parse and validate bet
 	bet, err := utils.ParseBet(betStr, user.Chips)
 	if err != nil {
-		gamesMutex.Unlock()
 		respondWithError(s, i, "Invalid bet amount: "+err.Error())
 		return
 	}
 
 	if bet <= 0 {
-		gamesMutex.Unlock()
 		respondWithError(s, i, "Bet amount must be greater than 0")
 		return
 	}
 
 	if user.Chips < bet {
-		gamesMutex.Unlock()
 		embed := utils.InsufficientChipsEmbed(bet, user.Chips, "blackjack")
 		utils.SendInteractionResponse(s, i, embed, nil, false)
 		return
 	}
 
-	// Create game instance while still holding the lock
+	// Create game instance
 	game := NewBlackjackGame(s, i, bet)
 	if game == nil {
-		gamesMutex.Unlock()
 		circuitBreaker.recordFailure()
 		respondWithError(s, i, "Failed to create game instance")
 		return
 	}
 
-	game.UserData = user
-	// Store game immediately to prevent race condition
-	ActiveGames[game.GameID] = game
-	gamesMutex.Unlock()
+	// Escrow the stake so concurrent games cannot spend the same chips
+	if err := game.ValidateBet(); err != nil {
+		if errors.Is(err, utils.ErrInsufficientChips) {
+			if current, cerr := utils.GetCachedUser(userID); cerr == nil {
+				user = current
+			}
+			utils.SendInteractionResponse(s, i, utils.InsufficientChipsEmbed(bet, user.Chips, "blackjack"), nil, false)
+			return
+		}
+		if utils.IsPlayBlocked(err) {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		circuitBreaker.recordFailure()
+		respondWithError(s, i, "Failed to place bet")
+		return
+	}
+	game.TimeoutPolicy = utils.StakeForfeitOnTimeout
+	// The shoe's fair round is committed only once the stake is escrowed
+	game.Deck = game.NewFairDeck(utils.DeckCount, "blackjack")
+	// Track atomically; a concurrent /blackjack from the same user loses and is refunded
+	if _, ok := utils.GameStateMgr.RegisterUserGame(game); !ok {
+		game.RefundStake()
+		respondWithError(s, i, "You already have an active blackjack game. Please finish it first.")
+		return
+	}
 
 	// Now defer the interaction - all slow operations are done
 	if err := utils.DeferInteractionResponse(s, i, false); err != nil {
 		// Clean up game if defer fails
-		gamesMutex.Lock()
-		delete(ActiveGames, game.GameID)
-		gamesMutex.Unlock()
+		utils.GameStateMgr.UnregisterGame(game.GameType, game.GameID)
+		game.RefundStake()
 		circuitBreaker.recordFailure()
 		respondWithError(s, i, "Failed to acknowledge command")
 		return
@@ -1030,9 +1036,8 @@ func HandleBlackjackCommand(s *discordgo.Session, i *discordgo.InteractionCreate
 	// Start game immediately - no goroutine needed since slow work is done
 	if err := game.StartGame(); err != nil {
 		// Clean up failed game
-		gamesMutex.Lock()
-		delete(ActiveGames, game.GameID)
-		gamesMutex.Unlock()
+		utils.GameStateMgr.UnregisterGame(game.GameType, game.GameID)
+		game.RefundStake()
 		circuitBreaker.recordFailure()
 		respondWithDeferredError(s, i, "Failed to start game: "+err.Error())
 		return
@@ -1052,15 +1057,7 @@ func HandleBlackjackInteraction(s *discordgo.Session, i *discordgo.InteractionCr
 	}
 
 	// Find the user's active game
-	gamesMutex.RLock()
-	var game *BlackjackGame
-	for _, g := range ActiveGames {
-		if g.UserID == userID {
-			game = g
-			break
-		}
-	}
-	gamesMutex.RUnlock()
+	game := userGame(userID)
 
 	if game == nil {
 		respondWithError(s, i, "No active blackjack game found")
@@ -1099,8 +1096,49 @@ func HandleBlackjackInteraction(s *discordgo.Session, i *discordgo.InteractionCr
 		return
 	}
 
-	// Game action completed successfully
+	// Persist the hand while it still waits on the player
+	if game.State == StateActive {
+		utils.SaveGameSnapshot(game)
+	}
+}
+
+// GetExpiresAt returns when an unfinished hand is forfeited
+func (bg *BlackjackGame) GetExpiresAt() time.Time {
+	return bg.CreatedAt.Add(utils.Games.Timeout("blackjack"))
+}
+
+// IsExpired reports whether the hand has been open longer than the blackjack timeout
+func (bg *BlackjackGame) IsExpired() bool {
+	return time.Now().After(bg.GetExpiresAt())
+}
 
+// Cleanup forfeits the stake of an abandoned hand
+func (bg *BlackjackGame) Cleanup() {
+	if _, err := bg.ExpireStake(); err != nil {
+		utils.BotLogf("blackjack", "stake expiry failed for user %d: %v", bg.UserID, err)
+	}
+}
+
+// Resume reattaches a restored hand and refreshes its message. Only hands waiting on
+// the player can resume; anything mid-reveal is refunded.
+func (bg *BlackjackGame) Resume(s *discordgo.Session) error {
+	if bg.State != StateActive {
+		return fmt.Errorf("hand was not awaiting a player action")
+	}
+
+	bg.Session = s
+	bg.BaseGame.GameID = bg.GameID // shadowed by BlackjackGame.GameID when decoded
+	bg.Deck.Reattach(bg.FairSource())
+	user, err := utils.GetCachedUser(bg.UserID)
+	if err != nil {
+		return err
+	}
+	bg.UserData = user
+	bg.updateViewOptions()
+
+	embed := bg.createGameEmbed(false)
+	embed.Description = strings.TrimSpace(utils.GameResumedNotice + "\n" + embed.Description)
+	return bg.fallbackEdit(embed, bg.View.GetComponents())
 }
 
 // isWebhookExpired checks for Discord webhook expiration errors using fast-fail pattern
@@ -1211,13 +1249,25 @@ func respondWithDeferredError(s *discordgo.Session, i *discordgo.InteractionCrea
 func RegisterBlackjackCommands() *discordgo.ApplicationCommand {
 	return &discordgo.ApplicationCommand{
 		Name:        "blackjack",
-		Description: "Start a game of Blackjack",
+		Description: "Play Blackjack",
 		Options: []*discordgo.ApplicationCommandOption{
 			{
-				Type:        discordgo.ApplicationCommandOptionString,
-				Name:        "bet",
-				Description: "Chips to wager (e.g. 500, 10k, 50%)",
-				Required:    true,
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "play",
+				Description: "Start a game of Blackjack against the dealer",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "bet",
+						Description: "Chips to wager (e.g. 500, 10k, 50%)",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "table",
+				Description: fmt.Sprintf("Open a shared Blackjack table in this channel (up to %d players)", TableMaxSeats),
 			},
 		},
 	}
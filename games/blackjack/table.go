@@ -0,0 +1,738 @@
+package blackjack
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"hrc-go/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// TableMaxSeats is how many players can sit at a channel table
+	TableMaxSeats = 5
+	// TableBettingWindow is how long bets stay open before each round is dealt
+	TableBettingWindow = 30 * time.Second
+	// TableTurnTimeout is how long a seat may take to act before it stands automatically
+	TableTurnTimeout = 45 * time.Second
+)
+
+// TablePhase is where a table is in its round
+type TablePhase string
+
+const (
+	TableBetting TablePhase = "betting"
+	TablePlaying TablePhase = "playing"
+	TableClosed  TablePhase = "closed"
+)
+
+// Seat is one player at a table. Round state is reset between rounds; the seat and its
+// last bet persist until the player leaves or skips a round.
+type Seat struct {
+	UserID      int64
+	Name        string
+	LastBet     int64
+	Game        *utils.BaseGame // this round's escrowed stake; nil while sitting out
+	Bets        []int64
+	Hands       []*utils.Hand
+	CurrentHand int
+	Results     []GameResult
+	Profit      int64
+	View        *utils.BlackjackView
+}
+
+func (seat *Seat) playing() bool {
+	return seat.Game != nil
+}
+
+func (seat *Seat) done() bool {
+	return seat.CurrentHand >= len(seat.Hands)
+}
+
+// Table is a shared blackjack table in a channel. Every seat plays its own hands against
+// one dealer, dealt from a shoe that persists across rounds. Each shoe is a fair round
+// committed under the opener's client seed and revealed when the shoe is retired. Stakes
+// are escrowed per seat for the round; a restart mid-round refunds them through the
+// orphaned reservation sweep.
+type Table struct {
+	ID        string
+	ChannelID string
+	MessageID string
+	OwnerID   int64
+	Shoe      *utils.Deck
+	ShoeRound *utils.FairRound
+	Dealer    *utils.Hand
+	Seats     []*Seat
+	Phase     TablePhase
+	Round     int
+	Turn      int // index into Seats of the seat acting
+	ClosesAt  time.Time
+	TurnEnds  time.Time
+	LastRound string
+	session   *discordgo.Session
+	timer     *time.Timer
+	mu        sync.Mutex
+}
+
+var tables = struct {
+	sync.RWMutex
+	byChannel map[string]*Table
+}{byChannel: make(map[string]*Table)}
+
+func channelTable(channelID string) *Table {
+	tables.RLock()
+	defer tables.RUnlock()
+	return tables.byChannel[channelID]
+}
+
+// HandleTableCommand opens a table in the channel for /blackjack table
+func HandleTableCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i == nil || i.Member == nil || i.Member.User == nil {
+		respondWithError(s, i, "Invalid user data")
+		return
+	}
+
+	ownerID, err := parseUserID(i.Member.User.ID)
+	if err != nil {
+		respondWithError(s, i, "Failed to parse user ID")
+		return
+	}
+
+	tables.Lock()
+	if _, exists := tables.byChannel[i.ChannelID]; exists {
+		tables.Unlock()
+		respondWithError(s, i, "There is already a blackjack table in this channel.")
+		return
+	}
+	table := &Table{
+		ID:        i.ID,
+		ChannelID: i.ChannelID,
+		OwnerID:   ownerID,
+		Dealer:    utils.NewHand("blackjack"),
+		session:   s,
+	}
+	tables.byChannel[i.ChannelID] = table
+	tables.Unlock()
+
+	table.mu.Lock()
+	table.newShoe()
+	table.openBetting()
+	embed, components := table.render()
+	table.mu.Unlock()
+
+	if err := utils.SendInteractionResponse(s, i, embed, components, false); err != nil {
+		table.close()
+		return
+	}
+	if msg, err := s.InteractionResponse(i.Interaction); err == nil && msg != nil {
+		table.mu.Lock()
+		table.MessageID = msg.ID
+		table.mu.Unlock()
+	}
+}
+
+// HandleTableInteraction routes table buttons: betting, leaving and seat actions
+func HandleTableInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	table := channelTable(i.ChannelID)
+	if table == nil {
+		respondWithError(s, i, "This blackjack table has closed.")
+		return
+	}
+	userID, err := parseUserID(i.Member.User.ID)
+	if err != nil {
+		respondWithError(s, i, "Failed to parse user ID")
+		return
+	}
+
+	action := strings.TrimPrefix(i.MessageComponentData().CustomID, "bjtable_")
+	switch action {
+	case "bet":
+		table.mu.Lock()
+		phase := table.Phase
+		var lastBet int64
+		if seat := table.seat(userID); seat != nil {
+			lastBet = seat.LastBet
+		}
+		table.mu.Unlock()
+		if phase != TableBetting {
+			respondWithError(s, i, "Bets are closed until the round ends.")
+			return
+		}
+		_ = s.InteractionRespond(i.Interaction, betModal(i.ChannelID, lastBet))
+
+	case "leave":
+		table.handleLeave(s, i, userID)
+
+	case "hit", "stand", "double", "split":
+		table.handleSeatAction(s, i, userID, action)
+
+	default:
+		respondWithError(s, i, "Unknown blackjack action")
+	}
+}
+
+func betModal(channelID string, lastBet int64) *discordgo.InteractionResponse {
+	input := &discordgo.TextInput{CustomID: "bet_amount", Label: "Bet Amount", Style: discordgo.TextInputShort, Required: true, MinLength: 1, MaxLength: 10, Placeholder: "e.g. 500, 10k, 50%"}
+	if lastBet > 0 {
+		input.Value = fmt.Sprintf("%d", lastBet)
+	}
+	return &discordgo.InteractionResponse{Type: discordgo.InteractionResponseModal, Data: &discordgo.InteractionResponseData{
+		CustomID:   "bjtable_bet_modal_" + channelID,
+		Title:      "Place Your Bet",
+		Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: []discordgo.MessageComponent{input}}},
+	}}
+}
+
+// HandleTableModal takes a seat (if needed) and escrows the player's bet for the next round
+func HandleTableModal(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ModalSubmitData()
+	table := channelTable(strings.TrimPrefix(data.CustomID, "bjtable_bet_modal_"))
+	if table == nil {
+		respondWithError(s, i, "This blackjack table has closed.")
+		return
+	}
+	userID, err := parseUserID(i.Member.User.ID)
+	if err != nil {
+		respondWithError(s, i, "Failed to parse user ID")
+		return
+	}
+
+	var betStr string
+	for _, row := range data.Components {
+		if ar, ok := row.(*discordgo.ActionsRow); ok {
+			for _, c := range ar.Components {
+				if ti, ok := c.(*discordgo.TextInput); ok && ti.CustomID == "bet_amount" {
+					betStr = strings.TrimSpace(ti.Value)
+				}
+			}
+		}
+	}
+
+	user, err := utils.GetCachedUser(userID)
+	if err != nil {
+		respondWithError(s, i, "Failed to get user data")
+		return
+	}
+	bet, err := utils.ParseBet(betStr, user.Chips)
+	if err != nil || bet <= 0 {
+		respondWithError(s, i, "Invalid bet amount.")
+		return
+	}
+
+	table.mu.Lock()
+	if table.Phase != TableBetting {
+		table.mu.Unlock()
+		respondWithError(s, i, "Bets are closed until the round ends.")
+		return
+	}
+	seat := table.seat(userID)
+	if seat != nil && seat.playing() {
+		table.mu.Unlock()
+		respondWithError(s, i, fmt.Sprintf("You already bet %s chips this round.", utils.FormatChips(seat.Bets[0])))
+		return
+	}
+	if seat == nil && len(table.Seats) >= TableMaxSeats {
+		table.mu.Unlock()
+		respondWithError(s, i, "This table is full.")
+		return
+	}
+
+	game := utils.NewBaseGame(s, i, bet, "blackjack")
+	if err := game.ValidateBet(); err != nil {
+		table.mu.Unlock()
+		if errors.Is(err, utils.ErrInsufficientChips) {
+			utils.SendInteractionResponse(s, i, utils.InsufficientChipsEmbed(bet, user.Chips, "blackjack"), nil, true)
+			return
+		}
+		if utils.IsPlayBlocked(err) {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		respondWithError(s, i, "Failed to place bet")
+		return
+	}
+
+	if seat == nil {
+		seat = &Seat{UserID: userID, Name: i.Member.User.Username}
+		table.Seats = append(table.Seats, seat)
+	}
+	seat.Game = game
+	seat.LastBet = bet
+	seat.Bets = []int64{bet}
+
+	// Deal as soon as every seat is filled and has bet
+	if table.allSeatsBet() && len(table.Seats) == TableMaxSeats {
+		table.startRound()
+	}
+	embed, components := table.render()
+	table.mu.Unlock()
+
+	_ = utils.UpdateComponentInteraction(s, i, embed, components)
+}
+
+func (t *Table) handleLeave(s *discordgo.Session, i *discordgo.InteractionCreate, userID int64) {
+	t.mu.Lock()
+	seat := t.seat(userID)
+	if seat == nil {
+		t.mu.Unlock()
+		respondWithError(s, i, "You are not seated at this table.")
+		return
+	}
+	if t.Phase != TableBetting {
+		t.mu.Unlock()
+		respondWithError(s, i, "You can leave once the round ends.")
+		return
+	}
+	if seat.playing() {
+		if _, err := seat.Game.RefundStake(); err != nil {
+			utils.BotLogf("blackjack", "table refund failed for user %d: %v", seat.UserID, err)
+		}
+	}
+	t.removeSeat(seat)
+	embed, components := t.render()
+	t.mu.Unlock()
+
+	_ = utils.UpdateComponentInteraction(s, i, embed, components)
+}
+
+func (t *Table) handleSeatAction(s *discordgo.Session, i *discordgo.InteractionCreate, userID int64, action string) {
+	t.mu.Lock()
+	if t.Phase != TablePlaying || t.Turn >= len(t.Seats) {
+		t.mu.Unlock()
+		respondWithError(s, i, "No hand is in play.")
+		return
+	}
+	seat := t.Seats[t.Turn]
+	if seat.UserID != userID {
+		t.mu.Unlock()
+		respondWithError(s, i, "It's not your turn.")
+		return
+	}
+
+	if err := t.act(seat, action); err != nil {
+		t.mu.Unlock()
+		respondWithError(s, i, err.Error())
+		return
+	}
+	embed, components := t.render()
+	t.mu.Unlock()
+
+	_ = utils.UpdateComponentInteraction(s, i, embed, components)
+}
+
+// act applies a player action to the acting seat's current hand; callers hold t.mu
+func (t *Table) act(seat *Seat, action string) error {
+	hand := seat.Hands[seat.CurrentHand]
+	switch action {
+	case "hit":
+		hand.AddCard(t.Shoe.Deal())
+		if hand.IsBust() || hand.Size() >= 5 || hand.GetValue() == 21 {
+			t.standHand(seat)
+		}
+
+	case "stand":
+		t.standHand(seat)
+
+	case "double":
+		if !seat.View.CanDouble {
+			return fmt.Errorf("you can't double this hand")
+		}
+		if err := seat.Game.AddStake(seat.Bets[seat.CurrentHand]); err != nil {
+			if utils.IsPlayBlocked(err) {
+				return errors.New(utils.BetErrorMessage(err))
+			}
+			return fmt.Errorf("insufficient chips to double down")
+		}
+		seat.Bets[seat.CurrentHand] *= 2
+		hand.AddCard(t.Shoe.Deal())
+		t.standHand(seat)
+
+	case "split":
+		if !seat.View.CanSplit {
+			return fmt.Errorf("you can't split this hand")
+		}
+		if err := seat.Game.AddStake(seat.Bets[seat.CurrentHand]); err != nil {
+			if utils.IsPlayBlocked(err) {
+				return errors.New(utils.BetErrorMessage(err))
+			}
+			return fmt.Errorf("insufficient chips to split")
+		}
+		first, second := hand.Split()
+		first.AddCard(t.Shoe.Deal())
+		second.AddCard(t.Shoe.Deal())
+		seat.Hands[seat.CurrentHand] = first
+		seat.Hands = append(seat.Hands, second)
+		seat.Bets = append(seat.Bets, seat.Bets[seat.CurrentHand])
+		t.updateSeatActions(seat)
+	}
+	return nil
+}
+
+// standHand finishes the seat's current hand and passes the turn when it has no more
+func (t *Table) standHand(seat *Seat) {
+	seat.CurrentHand++
+	if seat.done() {
+		t.advanceTurn()
+		return
+	}
+	t.updateSeatActions(seat)
+}
+
+func (t *Table) updateSeatActions(seat *Seat) {
+	if seat.done() {
+		seat.View.DisableAllButtons()
+		return
+	}
+	chips := int64(0)
+	if seat.Game.UserData != nil {
+		chips = seat.Game.UserData.Chips
+	}
+	setHandActions(seat.View, seat.Hands[seat.CurrentHand], seat.Bets[seat.CurrentHand], len(seat.Hands), chips)
+}
+
+// openBetting starts a betting window; callers hold t.mu
+func (t *Table) openBetting() {
+	t.Phase = TableBetting
+	t.ClosesAt = time.Now().Add(TableBettingWindow)
+	round := t.Round
+	t.schedule(TableBettingWindow, func() {
+		t.mu.Lock()
+		if t.Phase != TableBetting || t.Round != round {
+			t.mu.Unlock()
+			return
+		}
+		if !t.anyBets() {
+			t.mu.Unlock()
+			t.close()
+			return
+		}
+		t.startRound()
+		embed, components := t.render()
+		t.mu.Unlock()
+		t.edit(embed, components)
+	})
+}
+
+// startRound stands up seats that did not bet, deals, and hands the turn to the first
+// seat; callers hold t.mu
+func (t *Table) startRound() {
+	seated := t.Seats[:0]
+	for _, seat := range t.Seats {
+		if seat.playing() {
+			seated = append(seated, seat)
+		}
+	}
+	t.Seats = seated
+
+	t.Round++
+	t.Phase = TablePlaying
+
+	t.Dealer = utils.NewHand("blackjack")
+	for _, seat := range t.Seats {
+		seat.Hands = []*utils.Hand{utils.NewHand("blackjack")}
+		seat.CurrentHand = 0
+		seat.Results = nil
+		seat.Profit = 0
+		seat.View = utils.NewBlackjackView(seat.UserID, t.ID)
+		seat.View.Prefix = "bjtable_"
+	}
+	for pass := 0; pass < 2; pass++ {
+		for _, seat := range t.Seats {
+			seat.Hands[0].AddCard(t.Shoe.Deal())
+		}
+		t.Dealer.AddCard(t.Shoe.Deal())
+	}
+
+	// A dealer blackjack ends the round before anyone acts
+	if t.Dealer.IsBlackjack() {
+		t.resolve()
+		return
+	}
+
+	t.Turn = -1
+	t.advanceTurn()
+}
+
+// advanceTurn passes the turn to the next seat with a hand to play, resolving the round
+// when everyone is done; callers hold t.mu
+func (t *Table) advanceTurn() {
+	for t.Turn++; t.Turn < len(t.Seats); t.Turn++ {
+		seat := t.Seats[t.Turn]
+		// Naturals stand automatically
+		if seat.Hands[0].IsBlackjack() {
+			seat.CurrentHand = len(seat.Hands)
+			continue
+		}
+		t.updateSeatActions(seat)
+
+		round, turn := t.Round, t.Turn
+		t.TurnEnds = time.Now().Add(TableTurnTimeout)
+		t.schedule(TableTurnTimeout, func() {
+			t.mu.Lock()
+			if t.Phase != TablePlaying || t.Round != round || t.Turn != turn {
+				t.mu.Unlock()
+				return
+			}
+			// Stand every remaining hand of the idle seat
+			idle := t.Seats[turn]
+			for !idle.done() && t.Turn == turn {
+				t.standHand(idle)
+			}
+			embed, components := t.render()
+			t.mu.Unlock()
+			t.edit(embed, components)
+		})
+		return
+	}
+	t.resolve()
+}
+
+// resolve plays the dealer, settles every seat and opens betting for the next round;
+// callers hold t.mu
+func (t *Table) resolve() {
+	for t.Dealer.GetValue() < utils.DealerStandValue {
+		t.Dealer.AddCard(t.Shoe.Deal())
+	}
+
+	var summary []string
+	for _, seat := range t.Seats {
+		seat.Results = seat.Results[:0]
+		seat.Profit = 0
+		for idx, hand := range seat.Hands {
+			result := handResult(hand, t.Dealer, idx)
+			seat.Results = append(seat.Results, result)
+			seat.Profit += int64(float64(seat.Bets[idx])*result.Payout) - seat.Bets[idx]
+		}
+		if _, err := seat.Game.EndGame(seat.Profit); err != nil {
+			utils.BotLogf("blackjack", "table settlement failed for user %d: %v", seat.UserID, err)
+		}
+		summary = append(summary, fmt.Sprintf("**%s** %s", seat.Name, formatProfit(seat.Profit)))
+		seat.Game = nil
+		seat.View.DisableAllButtons()
+	}
+	t.LastRound = t.renderHands(true) + "\n" + strings.Join(summary, "\n")
+
+	// Retire a spent shoe between rounds so its seed can be revealed straight away
+	if t.Shoe.ShouldShuffle() {
+		if t.ShoeRound.ID > 0 {
+			t.LastRound += fmt.Sprintf("\n🔀 Shoe reshuffled · verify the last one with `/fairness verify round:%d`", t.ShoeRound.ID)
+		}
+		t.newShoe()
+	}
+
+	t.openBetting()
+}
+
+// newShoe reveals the current shoe's fair round, if any, and commits a fresh one;
+// callers hold t.mu
+func (t *Table) newShoe() {
+	if t.ShoeRound != nil {
+		t.ShoeRound.Reveal()
+	}
+	t.ShoeRound = utils.NewFairRound(t.OwnerID, "blackjack", t.ID, utils.JSONB{"decks": utils.DeckCount, "table": t.ChannelID})
+	t.Shoe = utils.NewDeckWithSource(utils.DeckCount, "blackjack", t.ShoeRound.Source())
+}
+
+// close tears the table down, refunding any stake escrowed for a round that never dealt
+func (t *Table) close() {
+	t.closeWith("No bets were placed, so the table has closed. Use `/blackjack table` to open a new one.")
+}
+
+// closeWith tears the table down, refunding every stake still escrowed, and leaves
+// description on the table message
+func (t *Table) closeWith(description string) {
+	t.mu.Lock()
+	t.Phase = TableClosed
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	for _, seat := range t.Seats {
+		if seat.playing() {
+			if _, err := seat.Game.RefundStake(); err != nil {
+				utils.BotLogf("blackjack", "table refund failed for user %d: %v", seat.UserID, err)
+			}
+		}
+	}
+	t.Seats = nil
+	t.ShoeRound.Reveal()
+	shoe := t.ShoeRound
+	t.mu.Unlock()
+
+	tables.Lock()
+	if tables.byChannel[t.ChannelID] == t {
+		delete(tables.byChannel, t.ChannelID)
+	}
+	tables.Unlock()
+
+	embed := utils.CreateBrandedEmbed("🃏 Blackjack Table", description, 0x95A5A6)
+	utils.AnnotateFairness(embed, shoe)
+	t.edit(embed, []discordgo.MessageComponent{})
+}
+
+// activeTables counts tables with a round being dealt or played
+func activeTables() int {
+	tables.RLock()
+	defer tables.RUnlock()
+	n := 0
+	for _, t := range tables.byChannel {
+		t.mu.Lock()
+		if t.Phase == TablePlaying {
+			n++
+		}
+		t.mu.Unlock()
+	}
+	return n
+}
+
+// stopTables closes every table at shutdown, refunding the round in progress
+func stopTables(s *discordgo.Session) {
+	tables.RLock()
+	open := make([]*Table, 0, len(tables.byChannel))
+	for _, t := range tables.byChannel {
+		open = append(open, t)
+	}
+	tables.RUnlock()
+
+	for _, t := range open {
+		t.closeWith(utils.ShutdownNotice + " Bets on the current round were refunded.")
+	}
+}
+
+// schedule replaces the table's pending timer; callers hold t.mu
+func (t *Table) schedule(d time.Duration, fn func()) {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	t.timer = time.AfterFunc(d, fn)
+}
+
+// edit updates the table message outside an interaction (timers and closing); callers
+// must not hold t.mu
+func (t *Table) edit(embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) {
+	t.mu.Lock()
+	messageID := t.MessageID
+	t.mu.Unlock()
+	if messageID == "" {
+		return
+	}
+	embeds := []*discordgo.MessageEmbed{embed}
+	edit := &discordgo.MessageEdit{ID: messageID, Channel: t.ChannelID, Embeds: &embeds, Components: &components}
+	if _, err := t.session.ChannelMessageEditComplex(edit); err != nil {
+		utils.BotLogf("blackjack", "table message edit failed in %s: %v", t.ChannelID, err)
+	}
+}
+
+func (t *Table) seat(userID int64) *Seat {
+	for _, seat := range t.Seats {
+		if seat.UserID == userID {
+			return seat
+		}
+	}
+	return nil
+}
+
+func (t *Table) removeSeat(target *Seat) {
+	for idx, seat := range t.Seats {
+		if seat == target {
+			t.Seats = append(t.Seats[:idx], t.Seats[idx+1:]...)
+			return
+		}
+	}
+}
+
+func (t *Table) anyBets() bool {
+	for _, seat := range t.Seats {
+		if seat.playing() {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Table) allSeatsBet() bool {
+	for _, seat := range t.Seats {
+		if !seat.playing() {
+			return false
+		}
+	}
+	return len(t.Seats) > 0
+}
+
+// render builds the table message for the current phase; callers hold t.mu
+func (t *Table) render() (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	embed := utils.CreateBrandedEmbed("🃏 Blackjack Table", "", 0x1E5631)
+	embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: "https://res.cloudinary.com/dfoeiotel/image/upload/v1753042166/3_vxurig.png"}
+	utils.AnnotateFairness(embed, t.ShoeRound)
+
+	switch t.Phase {
+	case TableBetting:
+		lines := []string{fmt.Sprintf("**Betting closes <t:%d:R>.** Place a bet to take a seat (%d/%d).", t.ClosesAt.Unix(), len(t.Seats), TableMaxSeats)}
+		for _, seat := range t.Seats {
+			if seat.playing() {
+				lines = append(lines, fmt.Sprintf("• **%s** — %s %s", seat.Name, utils.FormatChips(seat.Bets[0]), utils.ChipsEmoji))
+			} else {
+				lines = append(lines, fmt.Sprintf("• **%s** — no bet yet", seat.Name))
+			}
+		}
+		embed.Description = strings.Join(lines, "\n")
+		if t.LastRound != "" {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: fmt.Sprintf("Round %d", t.Round), Value: t.LastRound})
+		}
+		return embed, []discordgo.MessageComponent{utils.CreateActionRow(
+			utils.CreateButton("bjtable_bet", "Place Bet", discordgo.SuccessButton, len(t.Seats) >= TableMaxSeats && t.allSeatsBet(), &discordgo.ComponentEmoji{Name: "🪙"}),
+			utils.CreateButton("bjtable_leave", "Leave Seat", discordgo.DangerButton, len(t.Seats) == 0, nil),
+		)}
+
+	case TablePlaying:
+		seat := t.Seats[t.Turn]
+		embed.Description = fmt.Sprintf("Round %d · <@%d>'s turn — acts automatically <t:%d:R>.\n\n%s",
+			t.Round, seat.UserID, t.TurnEnds.Unix(), t.renderHands(false))
+		return embed, seat.View.GetComponents()
+	}
+
+	return embed, []discordgo.MessageComponent{}
+}
+
+// renderHands lists every seat's hands and the dealer; the hole card stays hidden until
+// the round is resolved
+func (t *Table) renderHands(revealed bool) string {
+	var lines []string
+	for idx, seat := range t.Seats {
+		for h, hand := range seat.Hands {
+			marker := ""
+			if !revealed && idx == t.Turn && h == seat.CurrentHand {
+				marker = "▶ "
+			}
+			label := seat.Name
+			if len(seat.Hands) > 1 {
+				label = fmt.Sprintf("%s (hand %d)", seat.Name, h+1)
+			}
+			line := fmt.Sprintf("%s**%s** `%s` — %d", marker, label, hand.String(), hand.GetValue())
+			if revealed && h < len(seat.Results) {
+				line += " · " + seat.Results[h].Result
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	if revealed {
+		lines = append(lines, fmt.Sprintf("**Dealer** `%s` — %d", t.Dealer.String(), t.Dealer.GetValue()))
+	} else if len(t.Dealer.Cards) > 0 {
+		lines = append(lines, fmt.Sprintf("**Dealer** `%s ??` — %d", t.Dealer.Cards[0].String(), t.Dealer.Cards[0].GetValue("blackjack")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatProfit(profit int64) string {
+	switch {
+	case profit > 0:
+		return fmt.Sprintf("won %s %s", utils.FormatChips(profit), utils.ChipsEmoji)
+	case profit < 0:
+		return fmt.Sprintf("lost %s %s", utils.FormatChips(-profit), utils.ChipsEmoji)
+	}
+	return "pushed"
+}
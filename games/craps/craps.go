@@ -1,14 +1,15 @@
 package craps
 
 import (
+	"errors"
 	"fmt"
 	"math"
-	"math/rand"
 	"sort"
 	"strings"
 	"time"
 
 	"hrc-go/utils"
+	"hrc-go/utils/rng"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -21,8 +22,27 @@ var payoutRatios = map[string]float64{
 	"hard_4": 7.0, "hard_10": 7.0, "hard_6": 9.0, "hard_8": 9.0,
 }
 
-// Active games keyed by userID
-var activeGames = map[int64]*Game{}
+func init() {
+	utils.RegisterGameModule(utils.GameModule{
+		GameType:      "craps",
+		Command:       RegisterCrapsCommand(),
+		HandleCommand: HandleCrapsCommand,
+		Components: []utils.Route{
+			{Prefix: "craps_bet_select", Handle: HandleCrapsSelect},
+			{Prefix: "craps_", Handle: HandleCrapsButton},
+		},
+		Modals:   []utils.Route{{Prefix: "craps_bet_modal_", Handle: HandleCrapsModal}},
+		NewState: func() utils.GameState { return &Game{BaseGame: &utils.BaseGame{}} },
+	})
+}
+
+// userGame returns the user's active craps game, if any
+func userGame(userID int64) (*Game, bool) {
+	if g, ok := utils.GameStateMgr.GetUserGame("craps", userID); ok {
+		return g.(*Game), true
+	}
+	return nil, false
+}
 
 // Custom dice emojis for Discord server
 var diceEmoji = map[int]string{
@@ -40,9 +60,6 @@ const (
 	phasePoint   = "point"
 )
 
-// Inactivity timeout duration for craps games
-const inactivityTimeout = 2 * time.Minute
-
 // Hard termination after remaining timed out beyond this duration
 const hardTimeout = 8 * time.Minute
 
@@ -55,7 +72,7 @@ type Game struct {
 	SessionProfit    int64
 	CreatedAt        time.Time
 	MessageID        string // primary game message
-	rng              *rand.Rand
+	rng              rng.Source
 	LastRollDisplay  string
 	PendingDecisions map[string]int64 // betType -> winnings awaiting keep/down decision
 	LastAction       time.Time
@@ -98,7 +115,7 @@ func HandleCrapsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	}
 
 	// Check for existing game immediately
-	if _, exists := activeGames[userID]; exists {
+	if _, exists := userGame(userID); exists {
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Craps", "You already have an active Craps game.", 0xFF0000), nil, true)
 		return
 	}
@@ -143,17 +160,26 @@ func HandleCrapsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	}
 
 	// Create and validate game
-	game := &Game{BaseGame: utils.NewBaseGame(s, i, betAmount, "craps"), Phase: phaseComeOut, Bets: map[string]int64{"pass_line": betAmount}, ComePoints: map[int]int64{}, CreatedAt: time.Now(), rng: rand.New(rand.NewSource(time.Now().UnixNano())), PendingDecisions: map[string]int64{}, LastAction: time.Now()}
+	game := &Game{BaseGame: utils.NewBaseGame(s, i, betAmount, "craps"), Phase: phaseComeOut, Bets: map[string]int64{"pass_line": betAmount}, ComePoints: map[int]int64{}, CreatedAt: time.Now(), PendingDecisions: map[string]int64{}, LastAction: time.Now()}
 	if err := game.BaseGame.ValidateBet(); err != nil {
-		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Craps", err.Error(), 0xFF0000), nil, true)
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Craps", utils.BetErrorMessage(err), 0xFF0000), nil, true)
+		return
+	}
+	if _, ok := utils.GameStateMgr.RegisterUserGame(game); !ok {
+		game.BaseGame.RefundStake()
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Craps", "You already have an active Craps game.", 0xFF0000), nil, true)
 		return
 	}
+	game.rng = game.BaseGame.StartFairRound(nil)
 
-	// Store game and send response
-	activeGames[userID] = game
+	// Send response
 	embed := game.buildEmbed("Game started. Place additional bets with buttons.", "Waiting to roll...")
 	components := game.components()
 	utils.SendInteractionResponse(s, i, embed, components, false)
+	if resp, err := s.InteractionResponse(i.Interaction); err == nil && resp != nil {
+		game.MessageID = resp.ID
+	}
+	utils.SaveGameSnapshot(game)
 
 	// Start simplified timeout watcher asynchronously
 	go game.startTimeoutWatcher(s)
@@ -162,7 +188,7 @@ func HandleCrapsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 // HandleCrapsButton processes button interactions
 func HandleCrapsButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	userID, _ := utils.ParseUserID(i.Member.User.ID)
-	game, exists := activeGames[userID]
+	game, exists := userGame(userID)
 	if !exists {
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Craps", "No active game.", 0xFF0000), nil, true)
 		return
@@ -245,7 +271,7 @@ func HandleCrapsSelect(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 	userID, _ := utils.ParseUserID(i.Member.User.ID)
-	game, ok := activeGames[userID]
+	game, ok := userGame(userID)
 	if !ok {
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Craps", "No active game.", 0xFF0000), nil, true)
 		return
@@ -274,22 +300,18 @@ func (g *Game) addBet(betType string, amount int64) error {
 	if strings.HasPrefix(betType, "place_") && g.Phase != phasePoint {
 		return fmt.Errorf("place bets only after point")
 	}
-	// Chips check
-	totalCommitted := amount
-	for _, v := range g.Bets {
-		totalCommitted += v
-	}
-	for _, v := range g.ComePoints {
-		totalCommitted += v
-	}
-	if g.UserData.Chips < totalCommitted {
-		return fmt.Errorf("insufficient chips")
+	// Escrow the new wager; settlement returns the stake plus session profit
+	if err := g.BaseGame.AddStake(amount); err != nil {
+		return errors.New(utils.BetErrorMessage(err))
 	}
 	g.Bets[betType] = amount
 	return nil
 }
 
-func (g *Game) rollDice() (int, int) { return g.rng.Intn(6) + 1, g.rng.Intn(6) + 1 }
+func (g *Game) rollDice() (int, int) { return RollDice(g.rng) }
+
+// RollDice throws two dice from src; successive calls replay a session's rolls in order
+func RollDice(src rng.Source) (int, int) { return src.Intn(6) + 1, src.Intn(6) + 1 }
 
 // handleRoll executes a dice roll
 func (g *Game) handleRoll(s *discordgo.Session, i *discordgo.InteractionCreate) {
@@ -507,6 +529,7 @@ func (g *Game) resolveRoll(total, d1, d2 int) (string, int64, map[string]int64)
 // endGame finalizes profit with BaseGame
 func (g *Game) endGame() {
 	_, _ = g.BaseGame.EndGame(g.SessionProfit)
+	utils.GameStateMgr.UnregisterGame(g.GameType, g.GameID)
 }
 
 // buildEmbed builds the main game embed
@@ -558,6 +581,7 @@ func (g *Game) buildEmbed(outcome, rollDisplay string) *discordgo.MessageEmbed {
 	} else {
 		embed.Footer.Text += " | Active"
 	}
+	utils.AnnotateFairness(embed, g.BaseGame.Round)
 	return embed
 }
 
@@ -783,7 +807,7 @@ func formatBetKey(k string) string {
 // HandleCrapsModal processes bet amount modal submissions
 func HandleCrapsModal(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	userID, _ := utils.ParseUserID(i.Member.User.ID)
-	game, ok := activeGames[userID]
+	game, ok := userGame(userID)
 	if !ok {
 		// Respond ephemeral game missing
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Craps", "No active game.", 0xFF0000), nil, true)
@@ -886,12 +910,13 @@ func (g *Game) updateOriginalAfterBet(outcome string) {
 	g.BaseGame.UpdateOriginalResponse(embed, g.components())
 }
 
-// updateLastAction records latest interaction time (skip if already timed out)
+// updateLastAction records latest interaction time and persists the game (skip if already timed out)
 func (g *Game) updateLastAction() {
 	if g.TimedOut || g.BaseGame.IsGameOver() {
 		return
 	}
 	g.LastAction = time.Now()
+	utils.SaveGameSnapshot(g)
 }
 
 // startTimeoutWatcher starts a simplified timeout watcher to avoid conflicts with Discord interactions
@@ -903,14 +928,14 @@ func (g *Game) startTimeoutWatcher(s *discordgo.Session) {
 	}()
 
 	// Use a single timeout check instead of continuous polling to reduce complexity
-	time.Sleep(inactivityTimeout)
+	time.Sleep(utils.Games.Timeout("craps"))
 
 	// Check if game is still active and hasn't had recent activity
 	if g.BaseGame.IsGameOver() || g.TimedOut {
 		return
 	}
 
-	if time.Since(g.LastAction) > inactivityTimeout {
+	if time.Since(g.LastAction) > utils.Games.Timeout("craps") {
 		// Mark as timed out but don't interfere with ongoing interactions
 		g.TimedOut = true
 		g.TimedOutAt = time.Now()
@@ -926,23 +951,65 @@ func (g *Game) startTimeoutWatcher(s *discordgo.Session) {
 			embed := g.buildEmbed(outcome, rollDisp)
 			g.BaseGame.UpdateOriginalResponse(embed, g.components())
 		}()
+	}
+}
 
-		// Schedule final cleanup without continuous polling
-		go func() {
-			defer func() { recover() }()
-			time.Sleep(hardTimeout)
-			if g.TimedOut && !g.AutoClosed && !g.BaseGame.IsGameOver() {
-				g.AutoClosed = true
-				g.endGame()
-				delete(activeGames, g.BaseGame.UserID)
-				outcome := "Game auto-closed after extended inactivity."
-				rollDisp := g.LastRollDisplay
-				if rollDisp == "" {
-					rollDisp = "Waiting to roll..."
-				}
-				embed := g.buildEmbed(outcome, rollDisp)
-				g.BaseGame.UpdateOriginalResponse(embed, g.components())
-			}
-		}()
+// GetExpiresAt returns when a timed-out game will be auto-closed
+func (g *Game) GetExpiresAt() time.Time {
+	if !g.TimedOut {
+		return g.LastAction.Add(utils.Games.Timeout("craps") + hardTimeout)
 	}
+	return g.TimedOutAt.Add(hardTimeout)
+}
+
+// IsExpired reports whether the game has stayed timed out beyond hardTimeout
+func (g *Game) IsExpired() bool {
+	return g.TimedOut && !g.AutoClosed && !g.BaseGame.IsGameOver() && time.Since(g.TimedOutAt) > hardTimeout
+}
+
+// Resume reattaches a restored game, refreshes its message and restarts the inactivity watcher
+func (g *Game) Resume(s *discordgo.Session) error {
+	if g.MessageID == "" {
+		return fmt.Errorf("game message was never sent")
+	}
+
+	g.Session = s
+	g.BaseGame.CreatedAt = g.CreatedAt // shadowed by Game.CreatedAt when decoded
+	g.rng = g.FairSource()
+	g.Rolling = false
+	if g.PendingDecisions == nil {
+		g.PendingDecisions = map[string]int64{}
+	}
+	if g.ComePoints == nil {
+		g.ComePoints = map[int]int64{}
+	}
+
+	rollDisp := g.LastRollDisplay
+	if rollDisp == "" {
+		rollDisp = "Waiting to roll..."
+	}
+	embeds := []*discordgo.MessageEmbed{g.buildEmbed(utils.GameResumedNotice, rollDisp)}
+	components := g.components()
+	if _, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{ID: g.MessageID, Channel: g.Interaction.ChannelID, Embeds: &embeds, Components: &components}); err != nil {
+		return err
+	}
+
+	if !g.TimedOut {
+		g.LastAction = time.Now()
+		go g.startTimeoutWatcher(s)
+	}
+	return nil
+}
+
+// Cleanup auto-closes the game after extended inactivity
+func (g *Game) Cleanup() {
+	g.AutoClosed = true
+	g.endGame()
+	outcome := "Game auto-closed after extended inactivity."
+	rollDisp := g.LastRollDisplay
+	if rollDisp == "" {
+		rollDisp = "Waiting to roll..."
+	}
+	embed := g.buildEmbed(outcome, rollDisp)
+	g.BaseGame.UpdateOriginalResponse(embed, g.components())
 }
@@ -5,7 +5,6 @@ import (
 	"math"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"hrc-go/utils"
@@ -16,17 +15,25 @@ import (
 // Streak multipliers (index = streak-1)
 var streakMultipliers = []float64{0.5, 1.0, 1.5, 2.0, 2.5, 3.0, 4.0, 5.0, 7.0, 10.0}
 
-// Active games (userID -> game)
-var (
-	activeGames   = map[int64]*Game{}
-	activeGamesMu sync.RWMutex
-)
+const gameType = "higher_or_lower"
 
-const (
-	gameType            = "higher_or_lower"
-	inactivityThreshold = 5 * time.Minute // matches python view timeout (300s)
-	checkInterval       = 15 * time.Second
-)
+func init() {
+	utils.RegisterGameModule(utils.GameModule{
+		GameType:      gameType,
+		Command:       RegisterHigherOrLowerCommand(),
+		HandleCommand: HandleHigherOrLowerCommand,
+		Components:    []utils.Route{{Prefix: "horl_", Handle: HandleHigherOrLowerInteraction}},
+		NewState:      func() utils.GameState { return &Game{BaseGame: &utils.BaseGame{}} },
+	})
+}
+
+// userGame returns the user's active Higher or Lower game, if any
+func userGame(userID int64) (*Game, bool) {
+	if g, ok := utils.GameStateMgr.GetUserGame(gameType, userID); ok {
+		return g.(*Game), true
+	}
+	return nil, false
+}
 
 // Game represents a Higher or Lower game state
 type Game struct {
@@ -58,13 +65,10 @@ func RegisterHigherOrLowerCommand() *discordgo.ApplicationCommand {
 // HandleHigherOrLowerCommand starts a new game.
 func HandleHigherOrLowerCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	userID, _ := utils.ParseUserID(i.Member.User.ID)
-	activeGamesMu.Lock()
-	if _, exists := activeGames[userID]; exists {
-		activeGamesMu.Unlock()
+	if _, exists := userGame(userID); exists {
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Higher or Lower", "You already have an active game.", 0xFF0000), nil, true)
 		return
 	}
-	activeGamesMu.Unlock()
 
 	// Extract bet option
 	betStr := ""
@@ -88,9 +92,15 @@ func HandleHigherOrLowerCommand(s *discordgo.Session, i *discordgo.InteractionCr
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Error", msg, 0xFF0000), nil, true)
 		return
 	}
-	game := &Game{BaseGame: utils.NewBaseGame(s, i, betAmt, gameType), Deck: utils.NewDeck(1, "poker"), CreatedAt: time.Now(), LastAction: time.Now(), Phase: "playing"}
+	game := &Game{BaseGame: utils.NewBaseGame(s, i, betAmt, gameType), CreatedAt: time.Now(), LastAction: time.Now(), Phase: "playing"}
 	if err := game.BaseGame.ValidateBet(); err != nil {
-		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Error", err.Error(), 0xFF0000), nil, true)
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Error", utils.BetErrorMessage(err), 0xFF0000), nil, true)
+		return
+	}
+	game.Deck = game.BaseGame.NewFairDeck(1, "poker")
+	if _, ok := utils.GameStateMgr.RegisterUserGame(game); !ok {
+		game.BaseGame.RefundStake()
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Higher or Lower", "You already have an active game.", 0xFF0000), nil, true)
 		return
 	}
 	// Deal first card
@@ -99,10 +109,7 @@ func HandleHigherOrLowerCommand(s *discordgo.Session, i *discordgo.InteractionCr
 	embed := game.buildEmbed("playing", "", false)
 	msgID := game.sendInitialFollowup(s, i, embed)
 	game.MessageID = msgID
-	activeGamesMu.Lock()
-	activeGames[userID] = game
-	activeGamesMu.Unlock()
-	go game.watchTimeout(s)
+	utils.SaveGameSnapshot(game)
 }
 
 // sendInitialFollowup sends the initial followup message, returns message ID.
@@ -121,9 +128,7 @@ func HandleHigherOrLowerInteraction(s *discordgo.Session, i *discordgo.Interacti
 		return
 	}
 	userID, _ := utils.ParseUserID(i.Member.User.ID)
-	activeGamesMu.RLock()
-	game, ok := activeGames[userID]
-	activeGamesMu.RUnlock()
+	game, ok := userGame(userID)
 	if !ok {
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Higher or Lower", "No active game.", 0xFF0000), nil, true)
 		return
@@ -232,7 +237,7 @@ func (g *Game) endGame(s *discordgo.Session, i *discordgo.InteractionCreate, los
 	updatedUser, _ := g.BaseGame.EndGame(profit)
 	xpGain := int64(0)
 	if profit > 0 {
-		xpGain = profit * utils.XPPerProfit
+		xpGain = profit * utils.Economy.XPPerProfit
 	}
 	if g.BaseGame != nil && g.BaseGame.UserData != nil && !utils.ShouldShowXPGained(g.BaseGame.Interaction.Member, g.BaseGame.UserData) {
 		xpGain = 0
@@ -247,9 +252,7 @@ func (g *Game) endGame(s *discordgo.Session, i *discordgo.InteractionCreate, los
 		g.editMessage(s, embed, components)
 	}
 	_ = updatedUser // embed already reflects new balance via builder
-	activeGamesMu.Lock()
-	delete(activeGames, g.UserID)
-	activeGamesMu.Unlock()
+	utils.GameStateMgr.UnregisterGame(g.GameType, g.GameID)
 }
 
 func (g *Game) endGameWithOverride(s *discordgo.Session, profitOverride int64, outcome string) {
@@ -263,9 +266,7 @@ func (g *Game) endGameWithOverride(s *discordgo.Session, profitOverride int64, o
 	_ = updatedUser
 	embed := g.buildEmbed("final", outcome, profit > 0)
 	g.editMessage(s, embed, nil)
-	activeGamesMu.Lock()
-	delete(activeGames, g.UserID)
-	activeGamesMu.Unlock()
+	utils.GameStateMgr.UnregisterGame(g.GameType, g.GameID)
 }
 
 // buildEmbed recreates python create_higher_or_lower_embed function (subset used states: playing/final)
@@ -335,7 +336,7 @@ func (g *Game) buildEmbed(state string, outcomeText string, won bool) *discordgo
 			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "💰 Total Winnings", Value: fmt.Sprintf("%s %s", utils.FormatChips(g.currentWinnings()), utils.ChipsEmoji), Inline: true})
 			profit := g.currentWinnings() - g.Bet
 			if profit > 0 {
-				xpGain := profit * utils.XPPerProfit
+				xpGain := profit * utils.Economy.XPPerProfit
 				if g.BaseGame != nil && g.BaseGame.UserData != nil && !utils.ShouldShowXPGained(g.BaseGame.Interaction.Member, g.BaseGame.UserData) {
 					xpGain = 0
 				}
@@ -351,6 +352,7 @@ func (g *Game) buildEmbed(state string, outcomeText string, won bool) *discordgo
 	} else {
 		embed.Footer.Text += " | Choose wisely!"
 	}
+	utils.AnnotateFairness(embed, g.BaseGame.Round)
 	return embed
 }
 
@@ -376,6 +378,7 @@ func (g *Game) updateMessageResult(s *discordgo.Session, i *discordgo.Interactio
 	g.LastAction = time.Now()
 	embed := g.buildEmbed("result", outcome, true)
 	utils.UpdateComponentInteraction(s, i, embed, g.components(false))
+	utils.SaveGameSnapshot(g)
 }
 
 func (g *Game) editMessage(s *discordgo.Session, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) {
@@ -390,19 +393,44 @@ func (g *Game) editMessage(s *discordgo.Session, embed *discordgo.MessageEmbed,
 	s.ChannelMessageEditComplex(edit)
 }
 
-// watchTimeout monitors inactivity
-func (g *Game) watchTimeout(s *discordgo.Session) {
-	ticker := time.NewTicker(checkInterval)
-	defer ticker.Stop()
-	for range ticker.C {
-		if g.Finished {
-			return
-		}
-		if time.Since(g.LastAction) > inactivityThreshold {
-			g.handleTimeout(s)
-			return
-		}
+// GetExpiresAt returns when the game times out if the player stays idle
+func (g *Game) GetExpiresAt() time.Time {
+	return g.LastAction.Add(utils.Games.Timeout(gameType))
+}
+
+// IsExpired reports whether the player has been idle past the game's timeout
+func (g *Game) IsExpired() bool {
+	return !g.Finished && time.Now().After(g.GetExpiresAt())
+}
+
+// Cleanup cashes out an idle game at its current winnings
+func (g *Game) Cleanup() {
+	g.handleTimeout(g.Session)
+}
+
+// Resume reattaches a restored game and refreshes its message. The idle timer restarts
+// so the player has a full window to continue.
+func (g *Game) Resume(s *discordgo.Session) error {
+	if g.Finished {
+		return fmt.Errorf("game already finished")
 	}
+	if g.MessageID == "" {
+		return fmt.Errorf("game message was never sent")
+	}
+
+	g.Session = s
+	g.BaseGame.CreatedAt = g.CreatedAt // shadowed by Game.CreatedAt when decoded
+	g.Deck.Reattach(g.FairSource())
+	g.LastAction = time.Now()
+
+	embed := g.buildEmbed(g.Phase, utils.GameResumedNotice, false)
+	if g.Phase == "playing" {
+		embed.Description = utils.GameResumedNotice + "\n" + embed.Description
+	}
+	embeds := []*discordgo.MessageEmbed{embed}
+	components := g.components(false)
+	_, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{ID: g.MessageID, Channel: g.Interaction.ChannelID, Embeds: &embeds, Components: &components})
+	return err
 }
 
 // Utility functions
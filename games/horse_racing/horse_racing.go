@@ -1,8 +1,8 @@
 package horse_racing
 
 import (
+	"errors"
 	"fmt"
-	"math/rand"
 	"sort"
 	"strconv"
 	"strings"
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"hrc-go/utils"
+	"hrc-go/utils/rng"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -49,12 +50,15 @@ type Horse struct {
 }
 
 type Bet struct {
-	UserID   int64
-	UserName string
-	HorseID  int
-	Amount   int64
+	UserID      int64
+	UserName    string
+	HorseID     int
+	Amount      int64
+	Reservation *utils.BetReservation
 }
 
+const gameType = "derby"
+
 type RaceStatus string
 
 const (
@@ -66,6 +70,7 @@ const (
 )
 
 type Race struct {
+	ID            string
 	ChannelID     string
 	MessageID     string
 	Initiator     int64
@@ -83,6 +88,60 @@ var races = struct {
 	byChannel map[string]*Race
 }{byChannel: make(map[string]*Race)}
 
+func init() {
+	utils.RegisterGameModule(utils.GameModule{
+		GameType:      gameType,
+		Command:       RegisterHorseRacingCommand(),
+		HandleCommand: HandleHorseRacingCommand,
+		Components:    []utils.Route{{Prefix: "derby_", Handle: HandleHorseRacingInteraction}},
+		Modals:        []utils.Route{{Prefix: "derby_bet_modal_", Handle: HandleHorseRacingModal}},
+		Active:        runningRaces,
+		Stop:          stopRaces,
+	})
+}
+
+// runningRaces counts races that are underway
+func runningRaces() int {
+	races.RLock()
+	defer races.RUnlock()
+	n := 0
+	for _, r := range races.byChannel {
+		r.mu.RLock()
+		if r.Status == StatusRunning {
+			n++
+		}
+		r.mu.RUnlock()
+	}
+	return n
+}
+
+// stopRaces cancels every race at shutdown and refunds its bets. A race still running
+// cannot settle afterwards, since its refunded reservations are closed.
+func stopRaces(s *discordgo.Session) {
+	races.Lock()
+	open := make([]*Race, 0, len(races.byChannel))
+	for chID, r := range races.byChannel {
+		open = append(open, r)
+		delete(races.byChannel, chID)
+	}
+	races.Unlock()
+
+	for _, r := range open {
+		r.mu.Lock()
+		r.Status = StatusCancelled
+		bets := append([]Bet(nil), r.Bets...)
+		chID, msgID := r.ChannelID, r.MessageID
+		r.mu.Unlock()
+
+		refundBets(bets)
+		if msgID != "" {
+			embeds := []*discordgo.MessageEmbed{utils.CreateBrandedEmbed("🏇 Race Cancelled", utils.ShutdownNotice+" All bets have been refunded.", 0x95A5A6)}
+			comps := []discordgo.MessageComponent{}
+			_, _ = s.ChannelMessageEditComplex(&discordgo.MessageEdit{Channel: chID, ID: msgID, Embeds: &embeds, Components: &comps})
+		}
+	}
+}
+
 // Command registration
 func RegisterHorseRacingCommand() *discordgo.ApplicationCommand {
 	return &discordgo.ApplicationCommand{
@@ -120,7 +179,7 @@ func HandleHorseRacingCommand(s *discordgo.Session, i *discordgo.InteractionCrea
 	}
 
 	races.Lock()
-	race := &Race{ChannelID: chID, Initiator: userID, InitiatorName: i.Member.User.Username, Participants: map[int64]string{userID: i.Member.User.Mention()}, Status: StatusLobby, CreatedAt: time.Now()}
+	race := &Race{ID: i.ID, ChannelID: chID, Initiator: userID, InitiatorName: i.Member.User.Username, Participants: map[int64]string{userID: i.Member.User.Mention()}, Status: StatusLobby, CreatedAt: time.Now()}
 	race.Horses = pickHorses(6)
 	races.byChannel[chID] = race
 	races.Unlock()
@@ -140,7 +199,7 @@ func HandleHorseRacingCommand(s *discordgo.Session, i *discordgo.InteractionCrea
 }
 
 func pickHorses(n int) []*Horse {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	r := rng.Default()
 	idx := r.Perm(len(horseNames))[:n]
 	horses := make([]*Horse, 0, n)
 	for i, j := range idx {
@@ -266,7 +325,9 @@ func HandleHorseRacingInteraction(s *discordgo.Session, i *discordgo.Interaction
 			return
 		}
 		race.Status = StatusCancelled
+		bets := append([]Bet(nil), race.Bets...)
 		race.mu.Unlock()
+		refundBets(bets)
 		_ = utils.UpdateComponentInteraction(s, i, utils.CreateBrandedEmbed("🏇 Race Cancelled 🏇", "The race was cancelled by the initiator.", 0xE74C3C), []discordgo.MessageComponent{})
 		races.Lock()
 		delete(races.byChannel, chID)
@@ -387,15 +448,49 @@ func HandleHorseRacingModal(s *discordgo.Session, i *discordgo.InteractionCreate
 		_ = utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Bet Error", "You have already placed a bet in this race.", 0xE74C3C), nil, true)
 		return
 	}
-	// debit
-	if _, err := utils.UpdateUser(userID, utils.UserUpdateData{ChipsIncrement: -betAmt}); err != nil {
+	// escrow the stake until the race settles
+	race.mu.RLock()
+	raceID := race.ID
+	race.mu.RUnlock()
+	var reservation *utils.BetReservation
+	err = utils.CheckGuildGame(i.GuildID, i.ChannelID, gameType, betAmt)
+	if err == nil {
+		reservation, _, err = utils.ReserveBet(userID, gameType, raceID, betAmt)
+	}
+	if errors.Is(err, utils.ErrInsufficientChips) {
+		_ = utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Not Enough Chips", "You don't have enough chips for that bet.", 0xE74C3C), nil, true)
+		return
+	}
+	if utils.IsPlayBlocked(err) {
+		_ = utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Bet Blocked", err.Error(), 0xE74C3C), nil, true)
+		return
+	}
+	if err != nil {
 		_ = utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Error", "Failed to place bet.", 0xE74C3C), nil, true)
 		return
 	}
-	// record bet
+	// record bet, rechecking under the lock: the race may have started or been cancelled
+	// while the stake was escrowed, and a second submit may have got in first
 	race.mu.Lock()
-	race.Bets = append(race.Bets, Bet{UserID: userID, UserName: i.Member.User.Username, HorseID: horseNum, Amount: betAmt})
+	rejected := ""
+	if race.Status != StatusBetting {
+		rejected = "You can no longer place bets."
+	}
+	for _, b := range race.Bets {
+		if b.UserID == userID {
+			rejected = "You have already placed a bet in this race."
+			break
+		}
+	}
+	if rejected == "" {
+		race.Bets = append(race.Bets, Bet{UserID: userID, UserName: i.Member.User.Username, HorseID: horseNum, Amount: betAmt, Reservation: reservation})
+	}
 	race.mu.Unlock()
+	if rejected != "" {
+		_, _ = utils.RefundBet(reservation)
+		_ = utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Bet Error", rejected, 0xE74C3C), nil, true)
+		return
+	}
 	_ = utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Bet Placed!", fmt.Sprintf("You bet %s on Horse #%d.", utils.FormatChips(betAmt), horseNum), 0x2ECC71), nil, true)
 	// update message to show bets
 	if race.MessageID != "" {
@@ -409,13 +504,13 @@ func runRace(s *discordgo.Session, r *Race) {
 	// simulation
 	winnerFound := false
 	step := 0
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	src := rng.Default()
 	var winner *Horse
 	phase := "start"
 	// Preselect commentary strings similar to Python
-	startText := commentary["start"][rng.Intn(len(commentary["start"]))]
-	middleText := commentary["middle"][rng.Intn(len(commentary["middle"]))]
-	endText := commentary["end"][rng.Intn(len(commentary["end"]))]
+	startText := commentary["start"][src.Intn(len(commentary["start"]))]
+	middleText := commentary["middle"][src.Intn(len(commentary["middle"]))]
+	endText := commentary["end"][src.Intn(len(commentary["end"]))]
 	for !winnerFound && step < 100 {
 		r.mu.Lock()
 		// phase selection similar to Python: start -> middle -> end when final stretch
@@ -453,12 +548,12 @@ func runRace(s *discordgo.Session, r *Race) {
 				p = 0.35
 			}
 			baseMove := 0
-			if rng.Float64() < p {
+			if src.Float64() < p {
 				baseMove = 1
 			}
 			bonusMove := 0
 			if baseMove > 0 {
-				bonusMove = rng.Intn(3) // 0-2
+				bonusMove = src.Intn(3) // 0-2
 			}
 			h.Position += baseMove + bonusMove
 			if h.Position > trackLength-1 {
@@ -473,7 +568,7 @@ func runRace(s *discordgo.Session, r *Race) {
 		}
 		// If no horse moved this tick, randomly nudge one forward to keep the race visually active
 		if !movedAny {
-			idx := rng.Intn(len(r.Horses))
+			idx := src.Intn(len(r.Horses))
 			if r.Horses[idx].Position < trackLength-1 {
 				r.Horses[idx].Position++
 			}
@@ -551,33 +646,32 @@ func payoutWinners(s *discordgo.Session, r *Race, winner *Horse) {
 
 	// compute payouts
 	type winEntry struct {
-		Payout int64
-		Profit int64
-		UserID int64
-		Name   string
+		Payout      int64
+		Profit      int64
+		UserID      int64
+		Name        string
+		Reservation *utils.BetReservation
 	}
 	wins := []winEntry{}
 	totalPaid := int64(0)
 	uniqueBettors := map[int64]struct{}{}
-	winnerIDs := map[int64]struct{}{}
 	for _, b := range bets {
 		uniqueBettors[b.UserID] = struct{}{}
 		if b.HorseID == winner.ID {
 			// Python credits bet*odds (stake was already debited), so payout equals winnings
 			winnings := b.Amount * int64(winner.Odds)
-			wins = append(wins, winEntry{Payout: winnings, Profit: winnings, UserID: b.UserID, Name: b.UserName})
+			wins = append(wins, winEntry{Payout: winnings, Profit: winnings, UserID: b.UserID, Name: b.UserName, Reservation: b.Reservation})
 			totalPaid += winnings
-			winnerIDs[b.UserID] = struct{}{}
 		}
 	}
 
-	// DB updates: winners get chips + XP and a win; losers get a loss
+	// Settle escrowed stakes: winners get chips + XP and a win; losers get a loss
 	for _, w := range wins {
-		_, _ = utils.UpdateUser(w.UserID, utils.UserUpdateData{ChipsIncrement: w.Payout, TotalXPIncrement: w.Profit * utils.XPPerProfit, CurrentXPIncrement: w.Profit * utils.XPPerProfit, WinsIncrement: 1})
+		_, _ = utils.SettleBet(w.Reservation, w.Payout, utils.UserUpdateData{TotalXPIncrement: w.Profit * utils.Economy.XPPerProfit, CurrentXPIncrement: w.Profit * utils.Economy.XPPerProfit, WinsIncrement: 1, Reason: utils.TxReasonPayout}, nil, nil)
 	}
-	for uid := range uniqueBettors {
-		if _, ok := winnerIDs[uid]; !ok {
-			_, _ = utils.UpdateUser(uid, utils.UserUpdateData{LossesIncrement: 1})
+	for _, b := range bets {
+		if b.HorseID != winner.ID {
+			_, _ = utils.SettleBet(b.Reservation, 0, utils.UserUpdateData{LossesIncrement: 1}, nil, nil)
 		}
 	}
 
@@ -610,6 +704,18 @@ func payoutWinners(s *discordgo.Session, r *Race, winner *Horse) {
 	races.Unlock()
 }
 
+// refundBets returns escrowed stakes for a race that never ran
+func refundBets(bets []Bet) {
+	for _, b := range bets {
+		if b.Reservation == nil {
+			continue
+		}
+		if _, err := utils.RefundBet(b.Reservation); err != nil {
+			utils.BotLogf("derby", "refund failed for user %d: %v", b.UserID, err)
+		}
+	}
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -1,14 +1,15 @@
 package mines
 
 import (
+	"errors"
 	"fmt"
-	"math/rand"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"hrc-go/utils"
+	"hrc-go/utils/rng"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -21,6 +22,14 @@ var payoutMultipliers = map[int]float64{
 	23: 3.33, 24: 4.00,
 }
 
+const gameType = "mines"
+
+// Grid dimensions
+const (
+	gridRows = 4
+	gridCols = 5
+)
+
 // Tile represents a single cell in the grid
 type Tile struct {
 	Row        int
@@ -31,22 +40,39 @@ type Tile struct {
 
 // Game represents a Mines game instance
 type Game struct {
-	UserID    int64
-	ChannelID string
-	MessageID string
-	Bet       int64
-	MineCount int
-	Grid      [][]*Tile // 4x5
-	Revealed  int
-	CreatedAt time.Time
-	IsOver    bool
-	mu        sync.RWMutex
+	ID          string
+	UserID      int64
+	ChannelID   string
+	MessageID   string
+	Bet         int64
+	Reservation *utils.BetReservation
+	Round       *utils.FairRound
+	MineCount   int
+	Session     *discordgo.Session `json:"-"`
+	Grid        [][]*Tile          // 4x5
+	Revealed    int
+	CreatedAt   time.Time
+	IsOver      bool
+	mu          sync.RWMutex
 }
 
-var active = struct {
-	sync.RWMutex
-	byUser map[int64]*Game
-}{byUser: make(map[int64]*Game)}
+func init() {
+	utils.RegisterGameModule(utils.GameModule{
+		GameType:      gameType,
+		Command:       RegisterMinesCommand(),
+		HandleCommand: HandleMinesCommand,
+		Components:    []utils.Route{{Prefix: "mines_", Handle: HandleMinesButton}},
+		NewState:      func() utils.GameState { return &Game{} },
+	})
+}
+
+// userGame returns the user's active Mines game, if any
+func userGame(userID int64) *Game {
+	if g, ok := utils.GameStateMgr.GetUserGame(gameType, userID); ok {
+		return g.(*Game)
+	}
+	return nil
+}
 
 // RegisterMinesCommand registers the /mines command
 func RegisterMinesCommand() *discordgo.ApplicationCommand {
@@ -108,13 +134,10 @@ func HandleMinesCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 
-	active.RLock()
-	if _, exists := active.byUser[uid]; exists {
-		active.RUnlock()
+	if userGame(uid) != nil {
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Mines", "You already have an active game.", 0xE74C3C), nil, true)
 		return
 	}
-	active.RUnlock()
 
 	if err := utils.DeferInteractionResponse(s, i, false); err != nil {
 		return
@@ -137,14 +160,27 @@ func HandleMinesCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 
-	// Debit bet upfront (Python does this before EndGame profits)
-	if _, err := utils.UpdateCachedUser(uid, utils.UserUpdateData{ChipsIncrement: -betAmt}); err != nil {
+	// Escrow bet upfront; settlement returns the cash-out amount
+	var reservation *utils.BetReservation
+	err = utils.CheckGuildGame(i.GuildID, i.ChannelID, gameType, betAmt)
+	if err == nil {
+		reservation, _, err = utils.ReserveBet(uid, gameType, i.ID, betAmt)
+	}
+	if errors.Is(err, utils.ErrInsufficientChips) {
+		_ = utils.EditOriginalInteraction(s, i, utils.InsufficientChipsEmbed(betAmt, user.Chips, "this bet"), nil)
+		return
+	}
+	if utils.IsPlayBlocked(err) {
+		_ = utils.EditOriginalInteraction(s, i, utils.CreateBrandedEmbed("Mines", err.Error(), 0xE74C3C), nil)
+		return
+	}
+	if err != nil {
 		_ = utils.EditOriginalInteraction(s, i, utils.CreateBrandedEmbed("Mines", "Could not place your bet.", 0xE74C3C), nil)
 		return
 	}
 
 	// Create game and grid
-	g := &Game{UserID: uid, ChannelID: i.ChannelID, Bet: betAmt, MineCount: minesCount, CreatedAt: time.Now()}
+	g := &Game{ID: i.ID, UserID: uid, ChannelID: i.ChannelID, Bet: betAmt, Reservation: reservation, MineCount: minesCount, Session: s, CreatedAt: time.Now()}
 	g.Grid = make([][]*Tile, 4)
 	for r := 0; r < 4; r++ {
 		g.Grid[r] = make([]*Tile, 5)
@@ -152,11 +188,15 @@ func HandleMinesCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			g.Grid[r][c] = &Tile{Row: r, Col: c}
 		}
 	}
+	g.Round = utils.NewFairRound(uid, gameType, g.ID, utils.JSONB{"mines": minesCount})
 	placeMines(g)
 
-	active.Lock()
-	active.byUser[uid] = g
-	active.Unlock()
+	if _, ok := utils.GameStateMgr.RegisterUserGame(g); !ok {
+		// Lost a race with another /mines from the same user
+		_, _ = utils.RefundBet(reservation)
+		_ = utils.EditOriginalInteraction(s, i, utils.CreateBrandedEmbed("Mines", "You already have an active game.", 0xE74C3C), nil)
+		return
+	}
 
 	// Initial embed and view
 	embed := createMinesEmbed(g, "playing", "", 0, 0, 0)
@@ -169,20 +209,36 @@ func HandleMinesCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		g.MessageID = resp.ID
 		g.mu.Unlock()
 	}
+	utils.SaveGameSnapshot(g)
 }
 
-// placeMines randomly marks tiles as mines
+// placeMines marks tiles as mines from the game's fair round
 func placeMines(g *Game) {
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	layout := PlaceMines(g.Round.Source(), g.MineCount)
+	for r := range layout {
+		for c := range layout[r] {
+			g.Grid[r][c].IsMine = layout[r][c]
+		}
+	}
+}
+
+// PlaceMines returns the mine layout drawn from src; each draw picks a row then a
+// column, skipping tiles that already hold a mine
+func PlaceMines(src rng.Source, mineCount int) [][]bool {
+	layout := make([][]bool, gridRows)
+	for r := range layout {
+		layout[r] = make([]bool, gridCols)
+	}
 	placed := 0
-	for placed < g.MineCount {
-		r := rng.Intn(4)
-		c := rng.Intn(5)
-		if !g.Grid[r][c].IsMine {
-			g.Grid[r][c].IsMine = true
+	for placed < mineCount {
+		r := src.Intn(gridRows)
+		c := src.Intn(gridCols)
+		if !layout[r][c] {
+			layout[r][c] = true
 			placed++
 		}
 	}
+	return layout
 }
 
 // currentMultiplier returns 1.0 + baseMultiplier*revealed
@@ -213,9 +269,7 @@ func HandleMinesButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	}
 	uid, _ := utils.ParseUserID(i.Member.User.ID)
 
-	active.RLock()
-	g := active.byUser[uid]
-	active.RUnlock()
+	g := userGame(uid)
 	if g == nil {
 		_ = utils.TryEphemeralFollowup(s, i, "No active Mines game.")
 		return
@@ -281,9 +335,10 @@ func handleReveal(s *discordgo.Session, i *discordgo.InteractionCreate, g *Game,
 		// Apply profit and XP
 		xp := int64(0)
 		if profit > 0 {
-			xp = profit * utils.XPPerProfit
+			xp = profit * utils.Economy.XPPerProfit
 		}
-		userAfter, _ := utils.UpdateCachedUserWithNotification(g.UserID, utils.UserUpdateData{ChipsIncrement: profit, TotalXPIncrement: xp, CurrentXPIncrement: xp}, s, i)
+		g.Round.Reveal()
+		userAfter, _ := utils.SettleBet(g.Reservation, g.Bet+profit, utils.UserUpdateData{TotalXPIncrement: xp, CurrentXPIncrement: xp}, s, i)
 		newBal := int64(0)
 		if userAfter != nil {
 			newBal = userAfter.Chips
@@ -300,10 +355,7 @@ func handleReveal(s *discordgo.Session, i *discordgo.InteractionCreate, g *Game,
 		embed := createMinesEmbed(g, "final", reason, profit, xp, newBal)
 		_ = utils.UpdateComponentInteraction(s, i, embed, comps)
 
-		// Clear active map
-		active.Lock()
-		delete(active.byUser, g.UserID)
-		active.Unlock()
+		utils.GameStateMgr.UnregisterGame(gameType, g.ID)
 		return
 	}
 
@@ -311,6 +363,7 @@ func handleReveal(s *discordgo.Session, i *discordgo.InteractionCreate, g *Game,
 	embed := createMinesEmbed(g, "playing", "", 0, 0, 0)
 	comps := buildComponents(g)
 	_ = utils.UpdateComponentInteraction(s, i, embed, comps)
+	utils.SaveGameSnapshot(g)
 }
 
 func handleCashout(s *discordgo.Session, i *discordgo.InteractionCreate, g *Game) {
@@ -327,9 +380,10 @@ func handleCashout(s *discordgo.Session, i *discordgo.InteractionCreate, g *Game
 	reason := "You cashed out."
 	xp := int64(0)
 	if profit > 0 {
-		xp = profit * utils.XPPerProfit
+		xp = profit * utils.Economy.XPPerProfit
 	}
-	userAfter, _ := utils.UpdateCachedUserWithNotification(g.UserID, utils.UserUpdateData{ChipsIncrement: profit, TotalXPIncrement: xp, CurrentXPIncrement: xp}, s, i)
+	g.Round.Reveal()
+	userAfter, _ := utils.SettleBet(g.Reservation, g.Bet+profit, utils.UserUpdateData{TotalXPIncrement: xp, CurrentXPIncrement: xp}, s, i)
 	newBal := int64(0)
 	if userAfter != nil {
 		newBal = userAfter.Chips
@@ -345,9 +399,7 @@ func handleCashout(s *discordgo.Session, i *discordgo.InteractionCreate, g *Game
 	embed := createMinesEmbed(g, "final", reason, profit, xp, newBal)
 	_ = utils.UpdateComponentInteraction(s, i, embed, comps)
 
-	active.Lock()
-	delete(active.byUser, g.UserID)
-	active.Unlock()
+	utils.GameStateMgr.UnregisterGame(gameType, g.ID)
 }
 
 // buildComponents constructs the 4x5 grid and cashout button
@@ -427,6 +479,7 @@ func createMinesEmbed(g *Game, state, outcome string, profit, xp int64, newBalan
 	}
 
 	if state == "playing" {
+		embed.Description = outcome
 		// Show live game info
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Revealed Gems", Value: fmt.Sprintf("%d", g.Revealed), Inline: true})
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Multiplier", Value: fmt.Sprintf("x%.2f", g.currentMultiplier()), Inline: true})
@@ -453,38 +506,64 @@ func createMinesEmbed(g *Game, state, outcome string, profit, xp int64, newBalan
 			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "XP Gained", Value: fmt.Sprintf("+%s XP", utils.FormatChips(xp)), Inline: true})
 		}
 	}
+	utils.AnnotateFairness(embed, g.Round)
 	return embed
 }
 
-// Cleanup goroutine (run periodically from main registration once)
-var cleanupOnce sync.Once
-
-func StartCleanupLoop(s *discordgo.Session) {
-	cleanupOnce.Do(func() {
-		go func() {
-			ticker := time.NewTicker(5 * time.Minute)
-			defer ticker.Stop()
-			for range ticker.C {
-				now := time.Now()
-				active.Lock()
-				for uid, g := range active.byUser {
-					if now.Sub(g.CreatedAt) > 10*time.Minute {
-						// Timeout: forfeit bet
-						delete(active.byUser, uid)
-						// Build cleanup embed
-						embed := utils.GameCleanupEmbed(g.Bet)
-						// Disable components and update message
-						if g.MessageID != "" {
-							comps := []discordgo.MessageComponent{}
-							embeds := []*discordgo.MessageEmbed{embed}
-							_, _ = s.ChannelMessageEditComplex(&discordgo.MessageEdit{Channel: g.ChannelID, ID: g.MessageID, Embeds: &embeds, Components: &comps})
-						}
-					}
-				}
-				active.Unlock()
-			}
-		}()
-	})
+// GetGameID returns the game ID
+func (g *Game) GetGameID() string { return g.ID }
+
+// GetUserID returns the player's user ID
+func (g *Game) GetUserID() int64 { return g.UserID }
+
+// GetGameType returns the game type
+func (g *Game) GetGameType() string { return gameType }
+
+// GetCreatedAt returns when the game started
+func (g *Game) GetCreatedAt() time.Time { return g.CreatedAt }
+
+// GetExpiresAt returns when an unfinished game is forfeited
+func (g *Game) GetExpiresAt() time.Time { return g.CreatedAt.Add(utils.Games.Timeout("mines")) }
+
+// IsExpired reports whether the game has run past the mines timeout
+func (g *Game) IsExpired() bool { return time.Now().After(g.GetExpiresAt()) }
+
+// GetReservation returns the escrowed stake
+func (g *Game) GetReservation() *utils.BetReservation { return g.Reservation }
+
+// Resume reattaches a restored game and refreshes its grid message
+func (g *Game) Resume(s *discordgo.Session) error {
+	if g.MessageID == "" {
+		return errors.New("game message was never sent")
+	}
+	g.Session = s
+
+	embeds := []*discordgo.MessageEmbed{createMinesEmbed(g, "playing", utils.GameResumedNotice, 0, 0, 0)}
+	comps := buildComponents(g)
+	_, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{Channel: g.ChannelID, ID: g.MessageID, Embeds: &embeds, Components: &comps})
+	return err
+}
+
+// Cleanup forfeits the stake of a timed-out game and closes its message
+func (g *Game) Cleanup() {
+	g.mu.Lock()
+	if g.IsOver {
+		g.mu.Unlock()
+		return
+	}
+	g.IsOver = true
+	g.mu.Unlock()
+
+	if _, err := utils.ForfeitBet(g.Reservation); err != nil {
+		utils.BotLogf("mines", "forfeit failed for user %d: %v", g.UserID, err)
+	}
+	g.Round.Reveal()
+
+	if g.MessageID != "" && g.Session != nil {
+		embeds := []*discordgo.MessageEmbed{utils.GameCleanupEmbed(g.Bet)}
+		comps := []discordgo.MessageComponent{}
+		_, _ = g.Session.ChannelMessageEditComplex(&discordgo.MessageEdit{Channel: g.ChannelID, ID: g.MessageID, Embeds: &embeds, Components: &comps})
+	}
 }
 
 func round2(f float64) float64 { return float64(int(f*100+0.5)) / 100 }
@@ -1,12 +1,13 @@
 package roulette
 
 import (
-	"math/rand"
+	"errors"
 	"strconv"
 	"strings"
 	"time"
 
 	"hrc-go/utils"
+	"hrc-go/utils/rng"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -14,7 +15,23 @@ import (
 var redNumbers = map[int]struct{}{1: {}, 3: {}, 5: {}, 7: {}, 9: {}, 12: {}, 14: {}, 16: {}, 18: {}, 19: {}, 21: {}, 23: {}, 25: {}, 27: {}, 30: {}, 32: {}, 34: {}, 36: {}}
 var blackNumbers = map[int]struct{}{2: {}, 4: {}, 6: {}, 8: {}, 10: {}, 11: {}, 13: {}, 15: {}, 17: {}, 20: {}, 22: {}, 24: {}, 26: {}, 28: {}, 29: {}, 31: {}, 33: {}, 35: {}}
 
-var activeRouletteGames = make(map[int64]*RouletteGame)
+func init() {
+	utils.RegisterGameModule(utils.GameModule{
+		GameType:      "roulette",
+		Command:       RegisterRouletteCommand(),
+		HandleCommand: HandleRouletteCommand,
+		Components:    []utils.Route{{Prefix: "roulette_", Handle: HandleRouletteInteraction}},
+		Modals:        []utils.Route{{Prefix: "roulette_bet_modal_", Handle: HandleRouletteModal}},
+	})
+}
+
+// userGame returns the user's active roulette table, if any
+func userGame(userID int64) (*RouletteGame, bool) {
+	if g, ok := utils.GameStateMgr.GetUserGame("roulette", userID); ok {
+		return g.(*RouletteGame), true
+	}
+	return nil, false
+}
 
 type RouletteGame struct {
 	*utils.BaseGame
@@ -49,23 +66,63 @@ func HandleRouletteCommand(s *discordgo.Session, i *discordgo.InteractionCreate)
 	}
 
 	// Check for existing game immediately
-	if _, exists := activeRouletteGames[userID]; exists {
+	if _, exists := userGame(userID); exists {
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Roulette", "You already have an active roulette game.", 0xFF0000), nil, true)
 		return
 	}
 
 	// Create and start game
 	game := &RouletteGame{BaseGame: utils.NewBaseGame(s, i, 0, "roulette"), Bets: make(map[string]int64), State: "betting"}
-	activeRouletteGames[userID] = game
-	embed := utils.RouletteGameEmbed("betting", game.Bets, 0, "", 0, 0, 0)
+	game.BaseGame.StartFairRound(nil)
+	if _, ok := utils.GameStateMgr.RegisterUserGame(game); !ok {
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Roulette", "You already have an active roulette game.", 0xFF0000), nil, true)
+		return
+	}
+	embed := game.embed("betting", 0, "", 0, 0, 0)
 	if err := utils.SendInteractionResponseWithTimeout(s, i, embed, game.buildComponents(), false, 3*time.Second); err != nil {
 		// Clean up so user can retry
-		delete(activeRouletteGames, userID)
+		utils.GameStateMgr.UnregisterGame(game.GameType, game.GameID)
 		// Attempt a simple ephemeral fallback if interaction still valid
 		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseChannelMessageWithSource, Data: &discordgo.InteractionResponseData{Content: "❌ Failed to start roulette (Discord error). Please try /roulette again.", Flags: discordgo.MessageFlagsEphemeral}})
+		return
 	}
 }
 
+// GetExpiresAt returns when an unspun table is refunded
+func (rg *RouletteGame) GetExpiresAt() time.Time {
+	return rg.CreatedAt.Add(utils.Games.Timeout("roulette"))
+}
+
+// IsExpired reports whether the table has sat in the betting state past the roulette timeout
+func (rg *RouletteGame) IsExpired() bool {
+	return rg.State == "betting" && time.Now().After(rg.GetExpiresAt())
+}
+
+// Cleanup refunds escrowed bets if the wheel was never spun
+func (rg *RouletteGame) Cleanup() {
+	if rg.State != "betting" {
+		return
+	}
+	rg.State = "final"
+	total := rg.totalBet()
+	if _, err := rg.BaseGame.RefundStake(); err != nil {
+		utils.BotLogf("roulette", "refund failed for user %d: %v", rg.UserID, err)
+	}
+	_ = utils.EditOriginalInteraction(rg.Session, rg.BaseGame.Interaction, utils.GameRefundEmbed(total), []discordgo.MessageComponent{})
+}
+
+// SpinWheel draws a pocket number (0-36) from src
+func SpinWheel(src rng.Source) int {
+	return src.Intn(37)
+}
+
+// embed renders the game state with the fair round's commitment in the footer
+func (rg *RouletteGame) embed(state string, num int, color string, profit, newBalance, xpGain int64) *discordgo.MessageEmbed {
+	embed := utils.RouletteGameEmbed(state, rg.Bets, num, color, profit, newBalance, xpGain)
+	utils.AnnotateFairness(embed, rg.BaseGame.Round)
+	return embed
+}
+
 func (rg *RouletteGame) buildComponents() []discordgo.MessageComponent {
 	if rg.State == "final" {
 		return nil
@@ -97,7 +154,7 @@ func (rg *RouletteGame) buildComponents() []discordgo.MessageComponent {
 
 func HandleRouletteInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	userID, _ := utils.ParseUserID(i.Member.User.ID)
-	game, exists := activeRouletteGames[userID]
+	game, exists := userGame(userID)
 	if !exists {
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Roulette", "No active roulette game.", 0xFF0000), nil, true)
 		return
@@ -109,7 +166,7 @@ func HandleRouletteInteraction(s *discordgo.Session, i *discordgo.InteractionCre
 			return
 		}
 		game.State = "spinning"
-		utils.UpdateComponentInteractionWithTimeout(s, i, utils.RouletteGameEmbed("spinning", game.Bets, 0, "", 0, 0, 0), game.buildComponents(), 3*time.Second)
+		utils.UpdateComponentInteractionWithTimeout(s, i, game.embed("spinning", 0, "", 0, 0, 0), game.buildComponents(), 3*time.Second)
 		go game.resolveSpin(s)
 		return
 	}
@@ -153,14 +210,13 @@ func (rg *RouletteGame) resolveSpin(s *discordgo.Session) {
 	defer func() {
 		if r := recover(); r != nil {
 			// Silently handle panics to prevent affecting main game state
-			delete(activeRouletteGames, rg.UserID)
+			utils.GameStateMgr.UnregisterGame(rg.GameType, rg.GameID)
 		}
 	}()
 
 	// Animation delay preserved but game completion is now async
 	time.Sleep(2 * time.Second)
-	rand.Seed(time.Now().UnixNano())
-	num := rand.Intn(37)
+	num := SpinWheel(rg.BaseGame.Round.Source())
 	color := "green"
 	if num != 0 {
 		if _, ok := redNumbers[num]; ok {
@@ -177,7 +233,7 @@ func (rg *RouletteGame) resolveSpin(s *discordgo.Session) {
 	newBalance := updatedUser.Chips
 	var xpGain int64
 	if profit > 0 {
-		xpGain = profit * utils.XPPerProfit
+		xpGain = profit * utils.Economy.XPPerProfit
 	}
 	// Premium gating for XP display
 	if updatedUser != nil && !utils.ShouldShowXPGained(rg.BaseGame.Interaction.Member, updatedUser) {
@@ -191,7 +247,7 @@ func (rg *RouletteGame) resolveSpin(s *discordgo.Session) {
 		// Use existing function with async wrapper for timeout protection
 		done := make(chan error, 1)
 		go func() {
-			done <- utils.EditOriginalInteraction(s, rg.BaseGame.Interaction, utils.RouletteGameEmbed("final", rg.Bets, num, color, profit, newBalance, xpGain), nil)
+			done <- utils.EditOriginalInteraction(s, rg.BaseGame.Interaction, rg.embed("final", num, color, profit, newBalance, xpGain), nil)
 		}()
 		select {
 		case <-done:
@@ -201,7 +257,7 @@ func (rg *RouletteGame) resolveSpin(s *discordgo.Session) {
 		}
 	}()
 
-	delete(activeRouletteGames, rg.UserID)
+	utils.GameStateMgr.UnregisterGame(rg.GameType, rg.GameID)
 }
 
 func (rg *RouletteGame) totalBet() int64 {
@@ -223,7 +279,7 @@ func HandleRouletteModal(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	}
 	betType := strings.TrimPrefix(customID, "roulette_bet_modal_")
 	userID, _ := utils.ParseUserID(i.Member.User.ID)
-	game, exists := activeRouletteGames[userID]
+	game, exists := userGame(userID)
 	if !exists {
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Roulette", "No active roulette game.", 0xFF0000), nil, true)
 		return
@@ -251,17 +307,13 @@ func HandleRouletteModal(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Error", "Failed to load user.", 0xFF0000), nil, true)
 		return
 	}
-	// Remaining chips after existing bets
-	remaining := user.Chips
-	for _, v := range game.Bets {
-		remaining -= v
-	}
+	// Existing bets are already escrowed, so the balance is what remains available
 	betAmount, err := utils.ParseBet(wagerStr, user.Chips)
 	if err != nil || betAmount <= 0 {
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Error", "Invalid bet amount.", 0xFF0000), nil, true)
 		return
 	}
-	if betAmount > remaining {
+	if betAmount > user.Chips {
 		utils.SendInteractionResponse(s, i, utils.InsufficientChipsEmbed(betAmount, user.Chips, "that wager"), nil, true)
 		return
 	}
@@ -278,9 +330,25 @@ func HandleRouletteModal(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		}
 		betType = "single_" + strconv.Itoa(n)
 	}
+	if game.State != "betting" {
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Roulette", "Betting is closed for this spin.", 0xFF0000), nil, true)
+		return
+	}
+	if err := utils.CheckGuildGame(i.GuildID, i.ChannelID, "roulette", betAmount); err != nil {
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Roulette", err.Error(), 0xFF0000), nil, true)
+		return
+	}
+	if err := game.BaseGame.AddStake(betAmount); err != nil {
+		if errors.Is(err, utils.ErrInsufficientChips) {
+			utils.SendInteractionResponse(s, i, utils.InsufficientChipsEmbed(betAmount, user.Chips, "that wager"), nil, true)
+			return
+		}
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Roulette", utils.BetErrorMessage(err), 0xFF0000), nil, true)
+		return
+	}
 	// Accumulate if user places same bet multiple times
 	game.Bets[betType] += betAmount
-	utils.UpdateComponentInteraction(s, i, utils.RouletteGameEmbed("betting", game.Bets, 0, "", 0, 0, 0), game.buildComponents())
+	utils.UpdateComponentInteraction(s, i, game.embed("betting", 0, "", 0, 0, 0), game.buildComponents())
 }
 func (rg *RouletteGame) calculateProfit() int64 {
 	num := rg.ResultNumber
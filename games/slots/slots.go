@@ -2,11 +2,11 @@ package slots
 
 import (
 	"fmt"
-	"math/rand"
 	"strings"
 	"time"
 
 	"hrc-go/utils"
+	"hrc-go/utils/rng"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -20,15 +20,16 @@ var (
 		"jackpot":  {"🎰"},
 	}
 	symbolWeights = map[string]float64{"common": 0.75, "uncommon": 0.10, "rare": 0.13, "jackpot": 0.02}
-	payouts       = map[string]int64{"🍒": 3, "🍋": 3, "🍊": 3, "🍉": 3, "🔔": 5, "⭐": 5, "💎": 10, "🎰": 15}
+	// rarityOrder fixes the weight walk order so a seeded RNG replays identical reels
+	rarityOrder = []string{"common", "uncommon", "rare", "jackpot"}
+	payouts     = map[string]int64{"🍒": 3, "🍋": 3, "🍊": 3, "🍉": 3, "🔔": 5, "⭐": 5, "💎": 10, "🎰": 15}
 )
 
 const (
-	payLines                    = 5
-	minBet                      = payLines
-	jackpotSymbol               = "🎰"
-	jackpotLossContributionRate = 0.10 // 10% of net loss feeds jackpot
-	spinFrames                  = 20
+	payLines      = 5
+	minBet        = payLines
+	jackpotSymbol = "🎰"
+	spinFrames    = 20
 )
 
 type phase string
@@ -48,8 +49,17 @@ type Game struct {
 	ChannelID    string
 	Phase        phase
 	BetNote      string
-	Rand         *rand.Rand
-	UsedOriginal bool // true if using original interaction message instead of followup
+	Rand         rng.Source // Cosmetic draws only (spin animation); outcomes come from the fair round
+	UsedOriginal bool       // true if using original interaction message instead of followup
+}
+
+func init() {
+	utils.RegisterGameModule(utils.GameModule{
+		GameType:      "slots",
+		Command:       RegisterSlotsCommand(),
+		HandleCommand: HandleSlotsCommand,
+		Components:    []utils.Route{{Prefix: "slots_", Handle: HandleSlotsInteraction}},
+	})
 }
 
 // RegisterSlotsCommand config
@@ -127,10 +137,10 @@ func HandleSlotsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			}
 		}()
 
-		game := &Game{BaseGame: utils.NewBaseGame(s, i, adjusted, "slots"), Session: s, Phase: phaseInitial, BetNote: note, Rand: rand.New(rand.NewSource(time.Now().UnixNano())), UsedOriginal: true}
+		game := &Game{BaseGame: utils.NewBaseGame(s, i, adjusted, "slots"), Session: s, Phase: phaseInitial, BetNote: note, Rand: rng.Default(), UsedOriginal: true}
 		game.BaseGame.CountWinLossMinRatio = 0.20
 		if err := game.ValidateBet(); err != nil {
-			utils.UpdateInteractionResponse(s, i, utils.CreateBrandedEmbed("Slots", err.Error(), 0xFF0000), nil)
+			utils.UpdateInteractionResponse(s, i, utils.CreateBrandedEmbed("Slots", utils.BetErrorMessage(err), 0xFF0000), nil)
 			return
 		}
 
@@ -179,6 +189,8 @@ func (g *Game) play() {
 		}
 		return 0
 	}())
+	// Commit the round before anything is shown so the hash precedes the outcome
+	src := g.StartFairRound(nil)
 	// Handle initial deferred response with spinning state
 	initial := g.buildEmbed("", 0, 0, false, 0)
 	if err := utils.UpdateInteractionResponse(g.Session, g.Interaction, initial, nil); err != nil {
@@ -196,9 +208,10 @@ func (g *Game) play() {
 			g.ChannelID = orig.ChannelID
 		}
 	}
-	final := g.createReels()
+	final := CreateReels(src)
 	g.animateSpin(final)
 	g.Reels = final
+	g.Round.Reveal()
 	totalWinnings, jackpotLine := g.calculateResults()
 	jackpotPayout := int64(0)
 	if jackpotLine && utils.JackpotMgr != nil {
@@ -206,15 +219,18 @@ func (g *Game) play() {
 		if won {
 			jackpotPayout = amount
 			totalWinnings += amount
+			g.BaseGame.LedgerReason = utils.TxReasonJackpot
+			utils.EmitAchievementEvent(g.Session, g.Interaction, utils.AchievementEvent{Type: utils.EventJackpotWon, UserID: g.UserID, GameType: g.GameType, Bet: g.Bet, Profit: amount})
 		}
 	}
 	profit := totalWinnings - g.Bet
-	// Pre-compute new balance locally (avoid DB wait before showing user)
+	// Pre-compute new balance locally (avoid DB wait before showing user). The balance was
+	// read after the stake was escrowed, so only the winnings are still to be credited.
 	preBalance := int64(0)
 	if g.BaseGame.UserData != nil {
 		preBalance = g.BaseGame.UserData.Chips
 	}
-	newBalance := preBalance + profit
+	newBalance := preBalance + totalWinnings
 	// Fetch jackpot amount BEFORE launching any async writes to avoid lock contention
 	jackpotAmount := int64(0)
 	if utils.JackpotMgr != nil {
@@ -227,12 +243,12 @@ func (g *Game) play() {
 		loss := -profit
 		go func(l int64) {
 			recover()
-			utils.JackpotMgr.AddJackpotAmount(utils.JackpotSlots, int64(float64(l)*jackpotLossContributionRate))
+			utils.JackpotMgr.AddJackpotAmount(utils.JackpotSlots, utils.JackpotMgr.LossContribution(l))
 		}(loss)
 	}
 	xpGain := int64(0)
 	if profit > 0 {
-		xpGain = profit * utils.XPPerProfit
+		xpGain = profit * utils.Economy.XPPerProfit
 	}
 	if g.BaseGame != nil && g.BaseGame.UserData != nil && !utils.ShouldShowXPGained(g.BaseGame.Interaction.Member, g.BaseGame.UserData) {
 		xpGain = 0
@@ -318,10 +334,11 @@ func (g *Game) play() {
 	}(profit, xpGain, beforeRank, initialJackpot)
 }
 
-func getRandomSymbol(r *rand.Rand) string {
+func getRandomSymbol(r rng.Source) string {
 	all := []string{}
 	weights := []float64{}
-	for rarity, syms := range symbols {
+	for _, rarity := range rarityOrder {
+		syms := symbols[rarity]
 		per := symbolWeights[rarity] / float64(len(syms))
 		for range syms {
 			weights = append(weights, per)
@@ -339,12 +356,13 @@ func getRandomSymbol(r *rand.Rand) string {
 	return all[len(all)-1]
 }
 
-func (g *Game) createReels() [][]string {
+// CreateReels draws the 3x3 result grid from src, row by row
+func CreateReels(src rng.Source) [][]string {
 	reels := make([][]string, 3)
 	for r := 0; r < 3; r++ {
 		row := make([]string, 3)
 		for c := 0; c < 3; c++ {
-			row[c] = getRandomSymbol(g.Rand)
+			row[c] = getRandomSymbol(src)
 		}
 		reels[r] = row
 	}
@@ -446,6 +464,7 @@ func (g *Game) buildEmbed(reels string, winnings int64, xpGain int64, final bool
 		}
 		embed.Footer = &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("You bet %s chips.", utils.FormatChips(g.Bet))}
 	}
+	utils.AnnotateFairness(embed, g.Round)
 	return embed
 }
 
@@ -645,10 +664,10 @@ func HandleSlotsInteraction(s *discordgo.Session, i *discordgo.InteractionCreate
 			}
 		}()
 
-		game := &Game{BaseGame: utils.NewBaseGame(s, i, adjusted, "slots"), Session: s, Phase: phaseInitial, Rand: rand.New(rand.NewSource(time.Now().UnixNano())), MessageID: i.Message.ID, ChannelID: i.ChannelID, UsedOriginal: true}
+		game := &Game{BaseGame: utils.NewBaseGame(s, i, adjusted, "slots"), Session: s, Phase: phaseInitial, Rand: rng.Default(), MessageID: i.Message.ID, ChannelID: i.ChannelID, UsedOriginal: true}
 		game.BaseGame.CountWinLossMinRatio = 0.20
 		if err := game.ValidateBet(); err != nil {
-			utils.TryEphemeralFollowup(s, i, err.Error())
+			utils.TryEphemeralFollowup(s, i, utils.BetErrorMessage(err))
 			return
 		}
 		if utils.JackpotMgr != nil {
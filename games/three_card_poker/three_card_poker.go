@@ -10,17 +10,30 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-const (
-	tcpGameType = "three_card_poker"
-	tcpTimeout  = 90 * time.Second
-)
+const tcpGameType = "three_card_poker"
 
 var (
 	anteBonusPayouts = map[string]int64{"Straight Flush": 5, "Three of a Kind": 4, "Straight": 1}
 	pairPlusPayouts  = map[string]int64{"Straight Flush": 40, "Three of a Kind": 30, "Straight": 6, "Flush": 3, "Pair": 1}
-	activeTCPGames   = make(map[int64]*TCPGame)
 )
 
+func init() {
+	utils.RegisterGameModule(utils.GameModule{
+		GameType:      tcpGameType,
+		Command:       RegisterThreeCardPokerCommand(),
+		HandleCommand: HandleThreeCardPokerCommand,
+		Components:    []utils.Route{{Prefix: "tcp_", Handle: HandleThreeCardPokerInteraction}},
+	})
+}
+
+// userGame returns the user's active Three Card Poker hand, if any
+func userGame(userID int64) (*TCPGame, bool) {
+	if g, ok := utils.GameStateMgr.GetUserGame(tcpGameType, userID); ok {
+		return g.(*TCPGame), true
+	}
+	return nil, false
+}
+
 type TCPGame struct {
 	*utils.BaseGame // BaseGame.Bet represents Ante
 	PairPlusBet     int64
@@ -49,7 +62,7 @@ func RegisterThreeCardPokerCommand() *discordgo.ApplicationCommand {
 
 func HandleThreeCardPokerCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	userID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
-	if _, exists := activeTCPGames[userID]; exists {
+	if _, exists := userGame(userID); exists {
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Three Card Poker", "You already have an active game.", 0xFF0000), nil, true)
 		return
 	}
@@ -85,14 +98,20 @@ func HandleThreeCardPokerCommand(s *discordgo.Session, i *discordgo.InteractionC
 		utils.SendInteractionResponse(s, i, utils.InsufficientChipsEmbed(totalPotential, user.Chips, "all bets (Ante, Pair Plus, and Play)"), nil, true)
 		return
 	}
-	game := &TCPGame{BaseGame: utils.NewBaseGame(s, i, ante, tcpGameType), PairPlusBet: pairPlus, Deck: utils.NewDeck(1, "poker"), StartedAt: time.Now()}
+	game := &TCPGame{BaseGame: utils.NewBaseGame(s, i, ante, tcpGameType), PairPlusBet: pairPlus, StartedAt: time.Now()}
 	game.BaseGame.Bet = totalPotential
 	if err := game.BaseGame.ValidateBet(); err != nil {
-		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Error", err.Error(), 0xFF0000), nil, true)
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Error", utils.BetErrorMessage(err), 0xFF0000), nil, true)
 		return
 	}
+	// The round is committed only once the stake is escrowed
+	game.Deck = game.BaseGame.NewFairDeck(1, "poker")
 	game.BaseGame.Bet = ante
-	activeTCPGames[userID] = game
+	if _, ok := utils.GameStateMgr.RegisterUserGame(game); !ok {
+		game.BaseGame.RefundStake()
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Three Card Poker", "You already have an active game.", 0xFF0000), nil, true)
+		return
+	}
 	utils.DeferInteractionResponse(s, i, false)
 	game.start(s, i)
 }
@@ -104,6 +123,7 @@ func (g *TCPGame) start(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	g.DealerEval = evaluateThreeCardHand(g.DealerHand)
 	// Pass placeholder dealer eval during initial state (will be revealed on finish)
 	embed := utils.ThreeCardPokerEmbed("initial", cardsToStrings(g.PlayerHand), cardsToStrings(g.DealerHand), g.PlayerEval.Name, "Hidden", g.Bet, g.PairPlusBet, 0, "", nil, 0, 0, 0)
+	utils.AnnotateFairness(embed, g.BaseGame.Round)
 	utils.SendFollowupMessage(s, i, embed, g.buildComponents(), false)
 }
 
@@ -120,7 +140,7 @@ func (g *TCPGame) buildComponents() []discordgo.MessageComponent {
 func HandleThreeCardPokerInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	cid := i.MessageComponentData().CustomID
 	userID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
-	game, ok := activeTCPGames[userID]
+	game, ok := userGame(userID)
 	if !ok {
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Three Card Poker", "No active game.", 0xFF0000), nil, true)
 		return
@@ -138,12 +158,7 @@ func HandleThreeCardPokerInteraction(s *discordgo.Session, i *discordgo.Interact
 }
 
 func (g *TCPGame) handlePlay(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	totalNeeded := g.Bet + g.PairPlusBet + g.Bet
-	user, _ := utils.GetCachedUser(g.UserID)
-	if user.Chips < totalNeeded {
-		g.handleFold(s, i, true)
-		return
-	}
+	// The Play bet was escrowed with the Ante and Pair Plus at the start of the hand
 	g.PlayBet = g.Bet
 	g.finish(s, i, false)
 }
@@ -200,14 +215,37 @@ func (g *TCPGame) finish(s *discordgo.Session, i *discordgo.InteractionCreate, f
 	updatedUser, _ := g.BaseGame.EndGame(profit)
 	var xpGain int64
 	if profit > 0 {
-		xpGain = profit * utils.XPPerProfit
+		xpGain = profit * utils.Economy.XPPerProfit
 	}
 	if g.BaseGame != nil && g.BaseGame.UserData != nil && !utils.ShouldShowXPGained(g.BaseGame.Interaction.Member, g.BaseGame.UserData) {
 		xpGain = 0
 	}
 	embed := utils.ThreeCardPokerEmbed("final", cardsToStrings(g.PlayerHand), cardsToStrings(g.DealerHand), g.PlayerEval.Name, g.DealerEval.Name, g.Bet, g.PairPlusBet, g.PlayBet, outcome, payoutLines, updatedUser.Chips, profit, xpGain)
-	utils.UpdateComponentInteraction(s, i, embed, nil)
-	delete(activeTCPGames, g.UserID)
+	utils.AnnotateFairness(embed, g.BaseGame.Round)
+	if i != nil {
+		utils.UpdateComponentInteraction(s, i, embed, nil)
+	} else {
+		_ = g.BaseGame.UpdateOriginalResponse(embed, nil)
+	}
+	utils.GameStateMgr.UnregisterGame(g.GameType, g.GameID)
+}
+
+// GetExpiresAt returns when an undecided hand is folded
+func (g *TCPGame) GetExpiresAt() time.Time {
+	return g.StartedAt.Add(utils.Games.Timeout(tcpGameType))
+}
+
+// IsExpired reports whether the player has not acted within the game's timeout
+func (g *TCPGame) IsExpired() bool {
+	return !g.Finished && time.Now().After(g.GetExpiresAt())
+}
+
+// Cleanup folds the hand when the player does not act in time
+func (g *TCPGame) Cleanup() {
+	if g.Finished {
+		return
+	}
+	g.finish(g.Session, nil, true)
 }
 
 func evaluateThreeCardHand(hand []utils.Card) HandEval {
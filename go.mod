@@ -5,6 +5,7 @@ go 1.25
 require (
 	github.com/bwmarrin/discordgo v0.29.0
 	github.com/jackc/pgx/v5 v5.7.5
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
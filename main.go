@@ -6,26 +6,30 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
-	baccarat "hrc-go/games/baccarat"
-	blackjack "hrc-go/games/blackjack"
+	"hrc-go/config"
+	_ "hrc-go/games/baccarat"
+	_ "hrc-go/games/blackjack"
 	craps "hrc-go/games/craps"
-	higherorlower "hrc-go/games/higher_or_lower"
-	horseracing "hrc-go/games/horse_racing"
+	_ "hrc-go/games/higher_or_lower"
+	_ "hrc-go/games/horse_racing"
 	mines "hrc-go/games/mines"
 	roulette "hrc-go/games/roulette"
 	slots "hrc-go/games/slots"
-	threecardpoker "hrc-go/games/three_card_poker"
+	_ "hrc-go/games/three_card_poker"
 	"hrc-go/utils"
+	"hrc-go/utils/rng"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -34,20 +38,35 @@ var session *discordgo.Session
 var botStatus = "starting"
 var readyCh = make(chan struct{}, 1)
 
-const devGuildID = "1262162191923023882" // fast registration guild
-const (
-	// Admin configuration (as requested)
-	AdminLogChannelID = "1396996421340626954"
-	AdminGuildID      = "1396567190102347776"
-	AdminRoleID       = "1396615290015453195"
-)
+// cfg is the validated configuration loaded at startup
+var cfg *config.Config
 
 func main() {
+	// Settings come from the optional file named by CONFIG_FILE, overlaid with the
+	// environment; a bad value stops the bot before it touches anything
+	var err error
+	cfg, err = config.Load(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatalf("Configuration error: %v", err)
+	}
+	cfg.Apply()
+	utils.InitializeBonusManager(cfg.Bonuses)
+
+	// Achievement definitions are checked up front; a broken file must not reach players
+	if err := utils.LoadAchievementDefinitions(cfg.AchievementsFile); err != nil {
+		log.Fatalf("Achievements error: %v", err)
+	}
+
+	// Schema management runs without starting the bot
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrateCommand(os.Args[2:]))
+	}
+
 	// Start HTTP server for health checks
 	go startHealthServer()
 
 	// Initialize database
-	if err := utils.SetupDatabase(); err != nil {
+	if err := utils.SetupDatabase(cfg.DatabaseURL); err != nil {
 		log.Printf("Database setup failed: %v", err)
 		log.Println("Bot will continue without database features")
 	} else {
@@ -56,10 +75,24 @@ func main() {
 		defer utils.CloseDatabase()
 	}
 
+	// Optional prestige track override
+	if cfg.PrestigeTrackFile != "" {
+		if err := utils.LoadPrestigeTrack(cfg.PrestigeTrackFile); err != nil {
+			log.Printf("Prestige track load failed, using defaults: %v", err)
+		}
+	}
+
 	// Initialize cache system (5 minute TTL for better responsiveness)
 	utils.InitializeCache(5 * time.Minute)
 	defer utils.CloseCache()
 
+	// Return stakes escrowed by games that were still running when the last process exited
+	if n, err := utils.RefundOrphanedReservations(); err != nil {
+		log.Printf("Orphaned bet refund failed: %v", err)
+	} else if n > 0 {
+		log.Printf("Refunded %d orphaned bet reservations", n)
+	}
+
 	// Initialize centralized game state management
 	utils.InitializeGameManager()
 	defer utils.CloseGameManager()
@@ -73,17 +106,8 @@ func main() {
 
 	// Heavy subsystems deferred until after READY to reduce startup latency
 
-	// Get bot token from environment (common variable names)
-	var token string
-	for _, key := range []string{"BOT_TOKEN", "DISCORD_TOKEN", "DISCORD_BOT_TOKEN", "HRC_BOT_TOKEN"} {
-		if val := strings.TrimSpace(os.Getenv(key)); val != "" {
-			token = val
-			break
-		}
-	}
-
 	// Sanitize token (remove quotes, leading Bot prefix, accidental export, etc.)
-	token = sanitizeToken(token)
+	token := sanitizeToken(cfg.Token)
 	if token == "" {
 		log.Println("Bot token missing (BOT_TOKEN). Exiting idle.")
 		botStatus = "no_token"
@@ -102,7 +126,6 @@ func main() {
 	// Token validated and sanitized
 
 	// Create Discord session
-	var err error
 	session, err = discordgo.New("Bot " + token)
 	if err != nil {
 		log.Printf("Failed to create Discord session: %v", err)
@@ -120,6 +143,15 @@ func main() {
 	session.AddHandler(onInteractionCreate)
 	session.AddHandler(onButtonInteraction)
 
+	// GuildMembers is privileged and must also be enabled in the developer portal; it keeps
+	// server leaderboards in step with joins and leaves. Without it members are learned
+	// from their interactions and expire once they stop playing in a server.
+	if cfg.GuildMembersIntent {
+		session.Identify.Intents |= discordgo.IntentsGuildMembers
+		session.AddHandler(onGuildMemberAdd)
+		session.AddHandler(onGuildMemberRemove)
+	}
+
 	// Open Discord connection
 	if err := session.Open(); err != nil {
 		log.Printf("Failed to open Discord connection: %v", err)
@@ -134,8 +166,6 @@ func main() {
 	case <-time.After(30 * time.Second):
 		// Continue without logging timeout
 	}
-	defer session.Close()
-
 	botStatus = "running"
 
 	// (Removed verbose heartbeat logging)
@@ -147,6 +177,35 @@ func main() {
 
 	log.Println("Gracefully shutting down...")
 	botStatus = "shutting_down"
+	shutdown(session)
+}
+
+// shutdown refuses new games, lets running ones finish or stops them, drains background
+// work and flushes jackpots before closing the gateway. The deferred closers in main
+// then stop the cache, game manager and database.
+func shutdown(s *discordgo.Session) {
+	utils.BeginShutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Shutdown.GameDrain)
+	utils.DrainGames(ctx, s)
+	cancel()
+
+	ctx, cancel = context.WithTimeout(context.Background(), cfg.Shutdown.TaskDrain)
+	if !utils.TaskMgr.Drain(ctx) {
+		log.Printf("Shutdown: %d background tasks were still queued", utils.TaskMgr.QueueDepth())
+	}
+	cancel()
+
+	if utils.JackpotMgr != nil {
+		if err := utils.JackpotMgr.Flush(); err != nil {
+			log.Printf("Shutdown: jackpot flush failed: %v", err)
+		}
+	}
+
+	if err := s.Close(); err != nil {
+		log.Printf("Shutdown: failed to close gateway: %v", err)
+	}
+	log.Println("Shutdown complete")
 }
 
 // (Removed network preflight test to reduce noise)
@@ -169,11 +228,22 @@ func onReady(s *discordgo.Session, event *discordgo.Ready) {
 
 	// Initialize heavy systems in background
 	go func() {
-		// Start background cleanup loops (mines)
-		mines.StartCleanupLoop(s)
+		// Resume games that were in flight when the last process exited
+		utils.RestoreGames(s)
+
+		// Start background work for registered games
+		utils.StartGameModules(s)
+
+		// Close finished seasons and open the next one
+		utils.StartSeasonScheduler(5*time.Minute, cfg.Seasons)
+
+		// Leaves are only reported with the guild members intent
+		if !cfg.GuildMembersIntent {
+			utils.StartGuildMemberExpiry(6 * time.Hour)
+		}
 
 		// Initialize Top.gg client for voting
-		utils.InitializeTopGGClient("1396564026233983108")
+		utils.InitializeTopGGClient(utils.IDs.BotID, cfg.TopGG.Token)
 
 		// Initialize achievement system
 		if err := utils.InitializeAchievementManager(); err != nil {
@@ -181,7 +251,7 @@ func onReady(s *discordgo.Session, event *discordgo.Ready) {
 		}
 
 		// Initialize jackpot system
-		if err := utils.InitializeJackpotManager(); err != nil {
+		if err := utils.InitializeJackpotManager(cfg.Jackpots); err != nil {
 			log.Printf("Jackpot manager init failed: %v", err)
 		} else {
 			log.Println("Jackpot system initialized")
@@ -193,7 +263,7 @@ func registerSlashCommands(s *discordgo.Session) error {
 	log.Println("🔄 Starting slash command registration...")
 
 	// Check if we should force re-registration (bypass hash check)
-	forceReregister := os.Getenv("FORCE_COMMAND_REREGISTER") == "true"
+	forceReregister := cfg.ForceCommandReregister
 	if forceReregister {
 		log.Println("⚠️  Force re-registration enabled")
 	}
@@ -203,7 +273,9 @@ func registerSlashCommands(s *discordgo.Session) error {
 		return fmt.Errorf("bot user state not available - commands cannot be registered")
 	}
 
-	// Base commands (global)
+	// Base commands (global). Server settings are enforced when a game starts, not here:
+	// the commands are shared by every server, and hiding one per server would take a
+	// command permissions edit made with an admin's OAuth token, which the bot doesn't hold.
 	globalCommands := []*discordgo.ApplicationCommand{
 		{
 			Name:        "ping",
@@ -287,32 +359,329 @@ func registerSlashCommands(s *discordgo.Session) error {
 			Name:        "leaderboard",
 			Description: "View the server leaderboards",
 			Options: []*discordgo.ApplicationCommandOption{
+				leaderboardSubcommand("chips", "Top players by chips"),
+				leaderboardSubcommand("xp", "Top players by total XP"),
+				leaderboardSubcommand("prestige", "Top players by prestige"),
+				leaderboardSubcommand("profit", "Top players by net profit across all games"),
+				leaderboardSubcommand("winrate", fmt.Sprintf("Top players by win rate (%d+ games)", utils.LeaderboardMinGames)),
+				leaderboardSubcommand("biggestwin", "Top players by biggest single win"),
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "season",
+					Description: "Standings for the current season",
+					Options:     []*discordgo.ApplicationCommandOption{seasonBoardOption},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "history",
+					Description: "Final standings of a past season",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "season",
+							Description: "Season number",
+							Required:    true,
+							MinValue:    &optionMinOne,
+						},
+						seasonBoardOption,
+					},
+				},
+			},
+		},
+		{
+			Name:        "stats",
+			Description: "View per-game statistics",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "game",
+					Description: "Game to show in detail (defaults to an overview of every game)",
+					Required:    false,
+					Choices:     statsGameChoices,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "user",
+					Description: "User to view (defaults to yourself)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "give",
+			Description: "Send chips to another player",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "user",
+					Description: "Player to receive the chips",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "amount",
+					Description: "Chips to send (e.g. 5000, 10k, 25%)",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "limits",
+			Description: "View or set your responsible-play limits",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "view",
+					Description: "See your limits and how much of them you've used",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "set",
+					Description: "Set a loss or wager limit (raising one takes 24 hours)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "limit",
+							Description: "Limit to change",
+							Required:    true,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "Daily loss", Value: string(utils.LimitDailyLoss)},
+								{Name: "Weekly loss", Value: string(utils.LimitWeeklyLoss)},
+								{Name: "Daily wager", Value: string(utils.LimitDailyWager)},
+								{Name: "Weekly wager", Value: string(utils.LimitWeeklyWager)},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "amount",
+							Description: "Chips (e.g. 50000 or 50k), or \"none\" to remove the limit",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "realitycheck",
+					Description: "Get a reminder after every so many minutes of continuous play",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "minutes",
+							Description: "Minutes between reminders (0 turns them off)",
+							Required:    true,
+							MinValue:    &optionMinZero,
+							MaxValue:    480,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "selfexclude",
+			Description: "Take a break from the casino for a set time",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "duration",
+					Description: "How long to exclude yourself, e.g. 1d, 7d or 4w",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:                     "config",
+			Description:              "Configure the casino for this server",
+			DefaultMemberPermissions: &configPermissions,
+			DMPermission:             func() *bool { b := false; return &b }(),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "view",
+					Description: "See this server's casino settings",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "game",
+					Description: "Turn a game on or off for this server",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "game",
+							Description: "Game to change",
+							Required:    true,
+							Choices:     statsGameChoices,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "enabled",
+							Description: "Whether the game can be played",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "channels",
+					Description: "Limit games to certain channels",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "action",
+							Description: "Allow or remove a channel, or open every channel again",
+							Required:    true,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "Allow", Value: "allow"},
+								{Name: "Remove", Value: "remove"},
+								{Name: "Allow all channels", Value: "reset"},
+							},
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionChannel,
+							Name:         "channel",
+							Description:  "Channel to allow or remove",
+							Required:     false,
+							ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "betlimits",
+					Description: "Set the minimum and maximum bet for a game (0 removes a limit)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "game",
+							Description: "Game to change",
+							Required:    true,
+							Choices:     statsGameChoices,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "min",
+							Description: "Smallest bet allowed",
+							Required:    false,
+							MinValue:    &optionMinZero,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "max",
+							Description: "Largest bet allowed",
+							Required:    false,
+							MinValue:    &optionMinZero,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "announcements",
+					Description: "Post big wins to a channel (leave out the channel to turn off)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionChannel,
+							Name:         "channel",
+							Description:  "Channel for big-win announcements",
+							Required:     false,
+							ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "threshold",
+							Description: "Profit a round needs to be announced",
+							Required:    false,
+							MinValue:    &optionMinOne,
+						},
+					},
+				},
 				{
 					Type:        discordgo.ApplicationCommandOptionSubCommand,
-					Name:        "chips",
-					Description: "Top 10 users by Chips",
+					Name:        "locale",
+					Description: "Set the language of the server's big-win announcements",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "locale",
+							Description: "Language",
+							Required:    true,
+							Choices:     localeChoices(),
+						},
+					},
 				},
+			},
+		},
+		{
+			Name:        "fairness",
+			Description: "Provably fair seeds and round verification",
+			Options: []*discordgo.ApplicationCommandOption{
 				{
 					Type:        discordgo.ApplicationCommandOptionSubCommand,
-					Name:        "xp",
-					Description: "Top 10 users by total XP",
+					Name:        "seed",
+					Description: "View or change your client seed",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "client_seed",
+							Description: "New client seed for your future rounds",
+							Required:    false,
+							MaxLength:   utils.MaxClientSeedLength,
+						},
+					},
 				},
 				{
 					Type:        discordgo.ApplicationCommandOptionSubCommand,
-					Name:        "prestige",
-					Description: "Top 10 users by prestige",
+					Name:        "verify",
+					Description: "Recompute a past round's outcome from its revealed seeds",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "round",
+							Description: "Fair round number shown on the game",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "game",
+							Description: "Game the seeds were used for",
+							Required:    false,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "Mines", Value: "mines"},
+								{Name: "Slots", Value: "slots"},
+								{Name: "Roulette", Value: "roulette"},
+								{Name: "Craps", Value: "craps"},
+								{Name: "Blackjack", Value: "blackjack"},
+								{Name: "Baccarat", Value: "baccarat"},
+								{Name: "Higher or Lower", Value: "higher_or_lower"},
+								{Name: "Three Card Poker", Value: "three_card_poker"},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "server_seed",
+							Description: "Revealed server seed",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "client_seed",
+							Description: "Client seed used for the round",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "nonce",
+							Description: "Round nonce",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "mines",
+							Description: "Mine count (Mines only)",
+							Required:    false,
+						},
+					},
 				},
 			},
 		},
-		blackjack.RegisterBlackjackCommands(),
-		baccarat.RegisterBaccaratCommand(),
-		craps.RegisterCrapsCommand(),
-		slots.RegisterSlotsCommand(),
-		horseracing.RegisterHorseRacingCommand(),
-		mines.RegisterMinesCommand(),
-		higherorlower.RegisterHigherOrLowerCommand(),
-		roulette.RegisterRouletteCommand(),
-		threecardpoker.RegisterThreeCardPokerCommand(),
 		// Admin commands (with runtime permission checking)
 		{
 			Name:        "addchips",
@@ -338,7 +707,62 @@ func registerSlashCommands(s *discordgo.Session) error {
 				},
 			},
 		},
+		{
+			Name:        "admin",
+			Description: "Casino moderation tools (Admins only)",
+			Options: []*discordgo.ApplicationCommandOption{
+				adminSubcommand("removechips", "Remove chips from a user's balance", &discordgo.ApplicationCommandOption{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "amount",
+					Description: "Amount of chips to remove",
+					Required:    true,
+					MinValue:    &optionMinOne,
+				}),
+				adminSubcommand("setchips", "Set a user's balance", &discordgo.ApplicationCommandOption{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "amount",
+					Description: "New balance",
+					Required:    true,
+					MinValue:    &optionMinZero,
+				}),
+				adminSubcommand("reset", "Reset a user's chips, XP, prestige, stats, streaks and achievements"),
+				adminSubcommand("revoke", "Revoke an achievement from a user", &discordgo.ApplicationCommandOption{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "achievement",
+					Description: "Achievement ID",
+					Required:    true,
+				}),
+				adminSubcommand("resetcooldowns", "Reset a user's bonus cooldowns"),
+				adminSubcommand("ban", "Ban a user from the casino", &discordgo.ApplicationCommandOption{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "duration",
+					Description: "Ban length, e.g. 12h, 7d or 2w (permanent if omitted)",
+					Required:    false,
+				}),
+				adminSubcommand("cooldown", "Put a user on a timed casino cooldown", &discordgo.ApplicationCommandOption{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "duration",
+					Description: "Cooldown length, e.g. 30m, 12h or 3d",
+					Required:    true,
+				}),
+				adminSubcommand("unban", "Lift a user's casino ban or cooldown"),
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+					Name:        "achievements",
+					Description: "Manage achievement definitions",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Name:        "reload",
+							Description: "Reload the achievements file and apply its changes",
+						},
+					},
+				},
+			},
+		},
 	}
+	// Game commands come from the game registry
+	globalCommands = append(globalCommands, utils.GameCommands()...)
 
 	log.Printf("📊 Preparing %d commands for registration", len(globalCommands))
 
@@ -456,9 +880,43 @@ func verifyCommandRegistration(s *discordgo.Session, expectedCommands []*discord
 	return nil
 }
 
+// trackGuildMember records server membership for server leaderboards without holding up
+// the interaction
+func trackGuildMember(guildID, userID string) {
+	gid, _ := strconv.ParseInt(guildID, 10, 64)
+	uid, _ := strconv.ParseInt(userID, 10, 64)
+	if gid == 0 || uid == 0 {
+		return
+	}
+	go func() {
+		if err := utils.TrackGuildMember(gid, uid); err != nil {
+			log.Printf("⚠️ %v", err)
+		}
+	}()
+}
+
+func onGuildMemberAdd(s *discordgo.Session, m *discordgo.GuildMemberAdd) {
+	if m.Member != nil && m.User != nil && !m.User.Bot {
+		trackGuildMember(m.GuildID, m.User.ID)
+	}
+}
+
+func onGuildMemberRemove(s *discordgo.Session, m *discordgo.GuildMemberRemove) {
+	if m.Member == nil || m.User == nil {
+		return
+	}
+	gid, _ := strconv.ParseInt(m.GuildID, 10, 64)
+	uid, _ := strconv.ParseInt(m.User.ID, 10, 64)
+	if err := utils.RemoveGuildMember(gid, uid); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+}
+
 func onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	trackGuildMember(i.GuildID, interactionUserID(i))
 	// Slash commands
 	if i.Type == discordgo.InteractionApplicationCommand {
+		start := time.Now()
 		switch i.ApplicationCommandData().Name {
 		case "ping":
 			handlePingCommand(s, i)
@@ -498,108 +956,55 @@ func onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			handlePremiumCommand(s, i)
 		case "addchips":
 			handleAddChipsCommand(s, i)
-		case "blackjack":
-			blackjack.HandleBlackjackCommand(s, i)
-		case "baccarat":
-			baccarat.HandleBaccaratCommand(s, i)
-		case "craps":
-			craps.HandleCrapsCommand(s, i)
-		case "slots":
-			slots.HandleSlotsCommand(s, i)
-		case "derby":
-			horseracing.HandleHorseRacingCommand(s, i)
-		case "mines":
-			mines.HandleMinesCommand(s, i)
-		case "horl":
-			higherorlower.HandleHigherOrLowerCommand(s, i)
-		case "roulette":
-			roulette.HandleRouletteCommand(s, i)
-		case "tcpoker":
-			threecardpoker.HandleThreeCardPokerCommand(s, i)
+		case "admin":
+			handleAdminCommand(s, i)
+		case "fairness":
+			handleFairnessCommand(s, i)
+		case "stats":
+			handleStatsCommand(s, i)
+		case "give":
+			handleGiveCommand(s, i)
+		case "selfexclude":
+			handleSelfExcludeCommand(s, i)
+		case "limits":
+			handleLimitsCommand(s, i)
+		case "config":
+			handleConfigCommand(s, i)
+		default:
+			utils.DispatchCommand(s, i)
+		}
+		utils.ObserveCommandLatency(i.ApplicationCommandData().Name, time.Since(start))
+		// Command-based achievements are checked after the handler has responded
+		if i.Member != nil && i.Member.User != nil {
+			userID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
+			utils.EmitAchievementEvent(s, i, utils.AchievementEvent{Type: utils.EventCommandUsed, UserID: userID, Command: i.ApplicationCommandData().Name})
 		}
 		return
 	}
 	// Modal submissions
 	if i.Type == discordgo.InteractionModalSubmit {
-		if strings.HasPrefix(i.ModalSubmitData().CustomID, "roulette_bet_modal_") {
-			roulette.HandleRouletteModal(s, i)
-		}
-		if strings.HasPrefix(i.ModalSubmitData().CustomID, "craps_bet_modal_") {
-			craps.HandleCrapsModal(s, i)
-		}
-		if strings.HasPrefix(i.ModalSubmitData().CustomID, "derby_bet_modal_") {
-			horseracing.HandleHorseRacingModal(s, i)
-		}
+		utils.DispatchModal(s, i)
 	}
 }
 
+func init() {
+	// Non-game buttons share the registry's component routing with the games
+	utils.RegisterComponentRoute("premium_", handlePremiumButton)
+	utils.RegisterComponentRoute("prestige_", handlePrestigeButtons)
+	utils.RegisterComponentRoute("give_", handleGiveButtons)
+	utils.RegisterComponentRoute("selfexclude_", handleSelfExcludeButtons)
+	utils.RegisterComponentRoute("leaderboard_", handleLeaderboardButtons)
+	utils.RegisterComponentRoute("vote_", handleVoteButton)
+	utils.RegisterComponentRoute("profile_achievements_", handleProfileAchievementsButton)
+	utils.RegisterComponentRoute("achievements_", handleAchievementsButton)
+}
+
 func onButtonInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	if i.Type != discordgo.InteractionMessageComponent {
 		return
 	}
 
-	customID := i.MessageComponentData().CustomID
-
-	// Route button interactions to appropriate handlers
-	if strings.HasPrefix(customID, "blackjack_") {
-		blackjack.HandleBlackjackInteraction(s, i)
-	}
-
-	if strings.HasPrefix(customID, "roulette_") {
-		roulette.HandleRouletteInteraction(s, i)
-	}
-
-	if strings.HasPrefix(customID, "baccarat_") {
-		baccarat.HandleBaccaratButton(s, i)
-	}
-
-	if strings.HasPrefix(customID, "craps_") {
-		if customID == "craps_bet_select" { // select menu handled elsewhere
-			craps.HandleCrapsSelect(s, i)
-		} else {
-			craps.HandleCrapsButton(s, i)
-		}
-	}
-
-	if strings.HasPrefix(customID, "horl_") {
-		higherorlower.HandleHigherOrLowerInteraction(s, i)
-	}
-
-	if strings.HasPrefix(customID, "tcp_") {
-		threecardpoker.HandleThreeCardPokerInteraction(s, i)
-	}
-
-	if strings.HasPrefix(customID, "slots_") {
-		slots.HandleSlotsInteraction(s, i)
-	}
-
-	if strings.HasPrefix(customID, "derby_") {
-		horseracing.HandleHorseRacingInteraction(s, i)
-	}
-
-	if strings.HasPrefix(customID, "mines_") {
-		mines.HandleMinesButton(s, i)
-	}
-
-	if strings.HasPrefix(customID, "premium_") {
-		handlePremiumButton(s, i)
-	}
-
-	if strings.HasPrefix(customID, "prestige_") {
-		handlePrestigeButtons(s, i)
-	}
-
-	if strings.HasPrefix(customID, "vote_") {
-		handleVoteButton(s, i)
-	}
-
-	if strings.HasPrefix(customID, "profile_achievements_") {
-		handleProfileAchievementsButton(s, i)
-	}
-
-	if strings.HasPrefix(customID, "achievements_") {
-		handleAchievementsButton(s, i)
-	}
+	utils.DispatchComponent(s, i)
 }
 
 func handlePingCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
@@ -638,7 +1043,7 @@ func handleInviteCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 				discordgo.Button{
 					Label: "Add Bot to Server",
 					Style: discordgo.LinkButton,
-					URL:   "https://discord.com/oauth2/authorize?client_id=1396564026233983108&permissions=274878253072&integration_type=0&scope=applications.commands+bot",
+					URL:   "https://discord.com/oauth2/authorize?client_id=" + utils.IDs.BotID + "&permissions=274878253072&integration_type=0&scope=applications.commands+bot",
 					Emoji: &discordgo.ComponentEmoji{Name: "🤖"},
 				},
 			},
@@ -684,7 +1089,11 @@ func handleProfileCommand(s *discordgo.Session, i *discordgo.InteractionCreate)
 		hasPremiumBadge = true
 		showWinLoss = utils.GetPremiumSetting(user, utils.PremiumFeatureWinsLosses)
 	}
-	embed := utils.UserProfileEmbed(user, targetDiscordUser, showWinLoss, hasPremiumBadge)
+	seasonBadges, err := utils.GetSeasonBadges(userID, 5)
+	if err != nil {
+		log.Printf("Failed to load season badges for %d: %v", userID, err)
+	}
+	embed := utils.UserProfileEmbed(user, targetDiscordUser, showWinLoss, hasPremiumBadge, seasonBadges)
 	// Components: View Achievements button and conditional Join link
 	components := []discordgo.MessageComponent{}
 	// Row with View Achievements
@@ -693,8 +1102,7 @@ func handleProfileCommand(s *discordgo.Session, i *discordgo.InteractionCreate)
 	// Conditionally add Join for /bonus link if user not in main guild
 	showJoin := true
 	// Try to check membership in main guild
-	mainGuildID := strconv.FormatInt(utils.GuildID, 10)
-	if m, err := s.GuildMember(mainGuildID, i.Member.User.ID); err == nil && m != nil {
+	if m, err := s.GuildMember(utils.IDs.HomeGuildID, i.Member.User.ID); err == nil && m != nil {
 		showJoin = false
 	}
 	if showJoin {
@@ -758,128 +1166,380 @@ func handleLeaderboardCommand(s *discordgo.Session, i *discordgo.InteractionCrea
 	if len(opts) > 0 {
 		sub = opts[0].Name
 	}
+	if sub == "season" || sub == "history" {
+		handleSeasonLeaderboard(s, i, opts[0])
+		return
+	}
 
-	// Optimized leaderboard query with prepared statements
-	title := map[string]string{"chips": "High Rollers", "xp": "Total XP", "prestige": "Prestige"}[sub]
+	board := utils.LeaderboardBoard(sub)
 	if utils.DB == nil {
-		embed := utils.CreateBrandedEmbed(title, "Database not connected.", 0xE74C3C)
+		embed := utils.CreateBrandedEmbed(leaderboardTitles[board], "Database not connected.", 0xE74C3C)
 		utils.SendInteractionResponse(s, i, embed, nil, false)
 		utils.ReleaseEmbed(embed)
 		return
 	}
 
-	// Use optimized prepared statements for leaderboard queries
-	rows, err := utils.GetLeaderboard(sub)
+	guildID, _ := strconv.ParseInt(i.GuildID, 10, 64)
+	viewerID, _ := strconv.ParseInt(interactionUserID(i), 10, 64)
+	embed, components, err := buildLeaderboardView(board, guildID, viewerID, 1)
 	if err != nil {
-		embed := utils.CreateBrandedEmbed(title, "Failed to load leaderboard.", 0xE74C3C)
+		log.Printf("Leaderboard %s failed: %v", board, err)
+		embed := utils.CreateBrandedEmbed(leaderboardTitles[board], "Failed to load leaderboard.", 0xE74C3C)
 		utils.SendInteractionResponse(s, i, embed, nil, false)
 		utils.ReleaseEmbed(embed)
 		return
 	}
-	defer rows.Close()
+	utils.SendInteractionResponse(s, i, embed, components, false)
+}
 
-	lines := utils.GetStringSliceFromPool()
-	defer utils.PutStringSliceToPool(lines)
+// leaderboardTitles are the embed titles of the ranked boards
+var leaderboardTitles = map[utils.LeaderboardBoard]string{
+	utils.BoardChips:      "High Rollers",
+	utils.BoardXP:         "Total XP",
+	utils.BoardPrestige:   "Prestige",
+	utils.BoardProfit:     "Net Profit",
+	utils.BoardWinRate:    "Win Rate",
+	utils.BoardBiggestWin: "Biggest Win",
+}
 
-	idx := 1
-	for rows.Next() {
-		var uid int64
-		var val int64
-		if sub == "prestige" {
-			var pv int
-			if err := rows.Scan(&uid, &pv); err == nil {
-				lines = append(lines, fmt.Sprintf("%d. <@%d> — %d", idx, uid, pv))
-				idx++
-			}
-		} else {
-			if err := rows.Scan(&uid, &val); err == nil {
-				if sub == "chips" {
-					lines = append(lines, fmt.Sprintf("%d. <@%d> — %s %s", idx, uid, utils.FormatChips(val), utils.ChipsEmoji))
-				} else {
-					lines = append(lines, fmt.Sprintf("%d. <@%d> — %s XP", idx, uid, utils.FormatChips(val)))
-				}
-				idx++
-			}
+func leaderboardSubcommand(name, description string) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        name,
+		Description: description,
+	}
+}
+
+// buildLeaderboardView renders one page of a board for the server (or globally outside one),
+// adding the viewer's own position when they are not on the page
+func buildLeaderboardView(board utils.LeaderboardBoard, guildID, viewerID int64, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	entries, total, err := utils.GetLeaderboardPage(board, guildID, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	totalPages := max((total+utils.LeaderboardPageSize-1)/utils.LeaderboardPageSize, 1)
+
+	line := func(e utils.LeaderboardEntry) string {
+		text := fmt.Sprintf("%d. <@%d> — %s", e.Rank, e.UserID, utils.FormatLeaderboardValue(board, e.Value))
+		if e.UserID == viewerID {
+			text = "**" + text + "**"
 		}
+		return text
+	}
+
+	lines := make([]string, 0, len(entries))
+	onPage := false
+	for _, e := range entries {
+		lines = append(lines, line(e))
+		onPage = onPage || e.UserID == viewerID
 	}
 	if len(lines) == 0 {
 		lines = append(lines, "No data")
 	}
 
-	embed := utils.CreateBrandedEmbed(title, strings.Join(lines, "\n"), utils.BotColor)
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseChannelMessageWithSource, Data: &discordgo.InteractionResponseData{Embeds: []*discordgo.MessageEmbed{embed}}})
-	utils.ReleaseEmbed(embed)
-}
+	embed := utils.CreateBrandedEmbed(leaderboardTitles[board], strings.Join(lines, "\n"), utils.BotColor)
+	if !onPage && viewerID != 0 {
+		position, err := utils.GetLeaderboardPosition(board, guildID, viewerID, 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := "You're not ranked on this board yet."
+		if board == utils.BoardWinRate {
+			value = fmt.Sprintf("Play %d games to be ranked by win rate.", utils.LeaderboardMinGames)
+		}
+		if len(position) > 0 {
+			neighbours := make([]string, 0, len(position))
+			for _, e := range position {
+				neighbours = append(neighbours, line(e))
+			}
+			value = strings.Join(neighbours, "\n")
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Your Position", Value: value})
+	}
 
-func handlePrestigeCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	userID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
-	user, err := utils.GetUser(userID)
-	if err != nil {
-		respondWithError(s, i, "❌ Error accessing user data.")
-		return
+	scope := "Global"
+	if guildID != 0 {
+		scope = "This server"
 	}
-	prestigeReadyLevel := len(utils.Ranks) - 1
-	requiredXP := utils.GetXPForLevel(prestigeReadyLevel, user.Prestige)
-	if user.CurrentXP < requiredXP {
-		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Prestige", fmt.Sprintf("You are not yet eligible to prestige. You need %s XP.", utils.FormatChips(requiredXP)), 0xE67E22), nil, true)
-		return
+	embed.Footer = &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("%s • %d players ranked", scope, total)}
+
+	var components []discordgo.MessageComponent
+	if totalPages > 1 {
+		prefix := fmt.Sprintf("leaderboard_page_%s_%d_", board, viewerID)
+		components = utils.PaginationView(prefix+strconv.Itoa(page-1), prefix+strconv.Itoa(page+1), page, totalPages)
 	}
-	embed := utils.CreateBrandedEmbed("<:chips:1396988413151940629> Prestige Confirmation", "Prestige has a price: Every chip you've collected will be reset; you'll have to rank up again to be a High Roller. Only your total XP will be unaffected.", 0xE67E22)
-	components := []discordgo.MessageComponent{discordgo.ActionsRow{Components: []discordgo.MessageComponent{
-		discordgo.Button{CustomID: "prestige_confirm", Label: "Confirm", Style: discordgo.SuccessButton},
-		discordgo.Button{CustomID: "prestige_cancel", Label: "Cancel", Style: discordgo.DangerButton},
-	}}}
-	utils.SendInteractionResponse(s, i, embed, components, false)
+	return embed, components, nil
 }
 
-func handlePrestigeButtons(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	cid := i.MessageComponentData().CustomID
-	if cid == "prestige_cancel" {
-		embed := utils.CreateBrandedEmbed("🐔 Prestige canceled!", "You chickened out.", 0xE67E22)
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseUpdateMessage,
-			Data: &discordgo.InteractionResponseData{
-				Embeds:     []*discordgo.MessageEmbed{embed},
-				Components: []discordgo.MessageComponent{},
-			},
-		})
+// handleLeaderboardButtons pages through a board for the player who opened it
+func handleLeaderboardButtons(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// leaderboard_page_<board>_<viewer>_<page>
+	parts := strings.Split(i.MessageComponentData().CustomID, "_")
+	if len(parts) != 5 || parts[1] != "page" {
 		return
 	}
-	if cid == "prestige_confirm" {
-		userID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
-		// Reset chips and current XP, increment prestige
-		p := utils.UserUpdateData{PremiumSettings: nil}
-		// Set prestige
-		newPrestige := 0
-		if u, _ := utils.GetUser(userID); u != nil {
-			newPrestige = u.Prestige + 1
-		}
-		p.Prestige = &newPrestige
-		// Reset chips to starting, reset current_xp to 0
-		// Use increments to set exact values by computing delta
-		u, _ := utils.GetUser(userID)
-		if u != nil {
-			p.ChipsIncrement = utils.StartingChips - u.Chips
-			p.CurrentXPIncrement = -u.CurrentXP
-		}
-		updated, _ := utils.UpdateCachedUserWithNotification(userID, p, s, i)
-		embed := utils.CreateBrandedEmbed("Prestiged!", fmt.Sprintf("You are now Prestige %d. Balance reset to %s %s.", updated.Prestige, utils.FormatChips(updated.Chips), utils.ChipsEmoji), 0xF1C40F)
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseUpdateMessage, Data: &discordgo.InteractionResponseData{Embeds: []*discordgo.MessageEmbed{embed}, Components: []discordgo.MessageComponent{}}})
+	if parts[3] != interactionUserID(i) {
+		respondWithError(s, i, "❌ Run `/leaderboard` to browse the standings yourself.")
 		return
 	}
-}
-
-func startHealthServer() {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	board := utils.LeaderboardBoard(parts[2])
+	viewerID, _ := strconv.ParseInt(parts[3], 10, 64)
+	page, _ := strconv.Atoi(parts[4])
+	if !utils.IsLeaderboardBoard(board) || page < 1 {
+		return
+	}
+
+	guildID, _ := strconv.ParseInt(i.GuildID, 10, 64)
+	embed, components, err := buildLeaderboardView(board, guildID, viewerID, page)
+	if err != nil {
+		log.Printf("Leaderboard %s page %d failed: %v", board, page, err)
+		respondWithError(s, i, "❌ Failed to load leaderboard.")
+		return
+	}
+	utils.UpdateComponentInteraction(s, i, embed, components)
+}
+
+// interactionUserID returns the ID of whoever triggered an interaction, in a guild or a DM
+func interactionUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
+
+// seasonBoardOption picks which season board /leaderboard shows
+var seasonBoardOption = &discordgo.ApplicationCommandOption{
+	Type:        discordgo.ApplicationCommandOptionString,
+	Name:        "board",
+	Description: "Which standings to show (defaults to net profit)",
+	Required:    false,
+	Choices: []*discordgo.ApplicationCommandOptionChoice{
+		{Name: "Net profit", Value: string(utils.SeasonBoardProfit)},
+		{Name: "Wagered", Value: string(utils.SeasonBoardWagered)},
+		{Name: "Games won", Value: string(utils.SeasonBoardWins)},
+	},
+}
+
+// /leaderboard season and /leaderboard history
+func handleSeasonLeaderboard(s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	board := utils.SeasonBoardProfit
+	seasonID := 0
+	for _, opt := range sub.Options {
+		switch opt.Name {
+		case "board":
+			board = utils.SeasonBoard(opt.StringValue())
+		case "season":
+			seasonID = int(opt.IntValue())
+		}
+	}
+	if utils.DB == nil {
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Seasons", "Database not connected.", 0xE74C3C), nil, false)
+		return
+	}
+
+	var season *utils.Season
+	var err error
+	if sub.Name == "season" {
+		season, err = utils.CurrentSeason()
+	} else {
+		season, err = utils.GetSeason(seasonID)
+	}
+	if err != nil {
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Seasons", "Failed to load the season.", 0xE74C3C), nil, false)
+		return
+	}
+	if season == nil {
+		msg := "No season is running right now. The next one starts shortly."
+		if sub.Name == "history" {
+			msg = fmt.Sprintf("There is no Season %d.", seasonID)
+		}
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Seasons", msg, 0xE74C3C), nil, true)
+		return
+	}
+
+	var standings []utils.SeasonStanding
+	var footer string
+	if season.ClosedAt != nil {
+		standings, err = utils.GetSeasonResults(season.ID, board)
+		footer = fmt.Sprintf("Ended <t:%d:D>", season.EndsAt.Unix())
+	} else {
+		standings, err = utils.GetSeasonStandings(season.ID, board, 10)
+		footer = fmt.Sprintf("Ends <t:%d:R>", season.EndsAt.Unix())
+		if season.AwaitingFinalization(time.Now()) {
+			footer = fmt.Sprintf("Ended <t:%d:D> · ⏳ pending finalization, standings and rewards are not final yet", season.EndsAt.Unix())
+		}
+	}
+	if err != nil {
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Seasons", "Failed to load the standings.", 0xE74C3C), nil, false)
+		return
+	}
+
+	lines := make([]string, 0, len(standings)+2)
+	for _, st := range standings {
+		value := utils.FormatChips(st.Value) + " " + utils.ChipsEmoji
+		if board == utils.SeasonBoardWins {
+			value = fmt.Sprintf("%s wins", utils.FormatChips(st.Value))
+		}
+		line := fmt.Sprintf("%d. <@%d> — %s", st.Rank, st.UserID, value)
+		if st.Badge != "" {
+			line = st.Badge + " " + line
+		}
+		if st.Reward > 0 {
+			line += fmt.Sprintf(" (+%s)", utils.FormatChips(st.Reward))
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "No data")
 	}
+	lines = append(lines, "", footer)
+
+	boardNames := map[utils.SeasonBoard]string{utils.SeasonBoardProfit: "Net Profit", utils.SeasonBoardWagered: "Wagered", utils.SeasonBoardWins: "Games Won"}
+	embed := utils.CreateBrandedEmbed(fmt.Sprintf("🏁 %s — %s", season.Name, boardNames[board]), strings.Join(lines, "\n"), utils.BotColor)
+	utils.SendInteractionResponse(s, i, embed, nil, false)
+}
+
+// statsGameChoices are the games offered by /stats
+var statsGameChoices = []*discordgo.ApplicationCommandOptionChoice{
+	{Name: "Blackjack", Value: "blackjack"},
+	{Name: "Baccarat", Value: "baccarat"},
+	{Name: "Craps", Value: "craps"},
+	{Name: "Derby", Value: "derby"},
+	{Name: "Higher or Lower", Value: "higher_or_lower"},
+	{Name: "Mines", Value: "mines"},
+	{Name: "Roulette", Value: "roulette"},
+	{Name: "Slots", Value: "slots"},
+	{Name: "Three Card Poker", Value: "three_card_poker"},
+}
+
+func handleStatsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	targetDiscordUser := i.Member.User
+	gameType := ""
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "game":
+			gameType = opt.StringValue()
+		case "user":
+			if u := opt.UserValue(nil); u != nil {
+				targetDiscordUser = u
+			}
+		}
+	}
+	userID, _ := strconv.ParseInt(targetDiscordUser.ID, 10, 64)
+
+	if utils.DB == nil {
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Game Stats", "Database not connected.", 0xE74C3C), nil, true)
+		return
+	}
+
+	var embed *discordgo.MessageEmbed
+	if gameType != "" {
+		stats, err := utils.GetGameStats(userID, gameType)
+		if err != nil {
+			utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Game Stats", "Failed to load stats.", 0xE74C3C), nil, true)
+			return
+		}
+		embed = utils.GameStatsEmbed(stats, targetDiscordUser)
+	} else {
+		stats, err := utils.GetAllGameStats(userID)
+		if err != nil {
+			utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Game Stats", "Failed to load stats.", 0xE74C3C), nil, true)
+			return
+		}
+		embed = utils.GameStatsOverviewEmbed(stats, targetDiscordUser)
+	}
+
+	utils.SendInteractionResponse(s, i, embed, nil, false)
+	utils.ReleaseEmbed(embed)
+}
+
+func handlePrestigeCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
+	user, err := utils.GetUser(userID)
+	if err != nil {
+		respondWithError(s, i, "❌ Error accessing user data.")
+		return
+	}
+	if !utils.IsPrestigeEligible(user) {
+		requiredXP := utils.PrestigeRequiredXP(user.Prestige)
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Prestige", fmt.Sprintf("You are not yet eligible to prestige. You need %s XP.", utils.FormatChips(requiredXP)), 0xE67E22), nil, true)
+		return
+	}
+	embed := utils.CreateBrandedEmbed("<:chips:1396988413151940629> Prestige Confirmation", "Prestige has a price: Every chip you've collected will be reset; you'll have to rank up again to be a High Roller. Only your total XP will be unaffected.", 0xE67E22)
+	reward := utils.PrestigeRewardFor(user.Prestige + 1)
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: fmt.Sprintf("Prestige %d Reward", reward.Level), Value: prestigeRewardSummary(reward)})
+	components := []discordgo.MessageComponent{discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+		discordgo.Button{CustomID: "prestige_confirm", Label: "Confirm", Style: discordgo.SuccessButton},
+		discordgo.Button{CustomID: "prestige_cancel", Label: "Cancel", Style: discordgo.DangerButton},
+	}}}
+	utils.SendInteractionResponse(s, i, embed, components, false)
+}
+
+func handlePrestigeButtons(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	cid := i.MessageComponentData().CustomID
+	if cid == "prestige_cancel" {
+		embed := utils.CreateBrandedEmbed("🐔 Prestige canceled!", "You chickened out.", 0xE67E22)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Embeds:     []*discordgo.MessageEmbed{embed},
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+		return
+	}
+	if cid == "prestige_confirm" {
+		userID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
+		updated, reward, err := utils.PrestigeUser(userID, s, i)
+		var embed *discordgo.MessageEmbed
+		switch {
+		case errors.Is(err, utils.ErrNotEligibleForPrestige):
+			embed = utils.CreateBrandedEmbed("Prestige", "You are no longer eligible to prestige.", 0xE67E22)
+		case errors.Is(err, utils.ErrStakesHeld):
+			embed = utils.CreateBrandedEmbed("Prestige", "Finish your games in progress before you prestige; their bets would otherwise be paid on top of your new balance.", 0xE67E22)
+		case err != nil:
+			log.Printf("Prestige failed for %d: %v", userID, err)
+			embed = utils.CreateBrandedEmbed("Prestige", "❌ Prestige failed. Your balance was not changed.", 0xE74C3C)
+		default:
+			embed = utils.CreateBrandedEmbed("Prestiged!", fmt.Sprintf("You are now Prestige %d %s. Balance reset to %s %s.", updated.Prestige, utils.PrestigeBadge(updated.Prestige), utils.FormatChips(updated.Chips), utils.ChipsEmoji), 0xF1C40F)
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Reward", Value: prestigeRewardSummary(reward)})
+		}
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseUpdateMessage, Data: &discordgo.InteractionResponseData{Embeds: []*discordgo.MessageEmbed{embed}, Components: []discordgo.MessageComponent{}}})
+		return
+	}
+}
+
+// prestigeRewardSummary lists what a prestige tier grants
+func prestigeRewardSummary(reward utils.PrestigeReward) string {
+	var parts []string
+	if reward.Chips > 0 {
+		parts = append(parts, fmt.Sprintf("+%s %s on top of the starting balance", utils.FormatChips(reward.Chips), utils.ChipsEmoji))
+	}
+	if reward.BonusMultiplier > 0 {
+		parts = append(parts, fmt.Sprintf("+%.0f%% permanent bonus multiplier", reward.BonusMultiplier*100))
+	}
+	if reward.Badge != "" {
+		parts = append(parts, "Badge: "+reward.Badge)
+	}
+	if len(parts) == 0 {
+		return "Bragging rights"
+	}
+	return strings.Join(parts, "\n")
+}
+
+func startHealthServer() {
+	port := cfg.Port
 
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(fmt.Sprintf("Discord Bot Status: %s", botStatus)))
 	})
 
+	http.Handle("/metrics", utils.MetricsHandler())
+
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
@@ -887,6 +1547,44 @@ func startHealthServer() {
 		w.Write([]byte(response))
 	})
 
+	// Point the orchestrator's liveness probe at /livez, which fails when the gateway or
+	// the cache cleanup loop is stuck and only a restart will help, and its readiness
+	// probe at /readyz, which also fails on outages that pass, like the database or
+	// Discord's API, and should only take the bot out of rotation
+	http.HandleFunc("/livez", func(w http.ResponseWriter, r *http.Request) {
+		report := utils.CheckLiveness(session, time.Now())
+		w.Header().Set("Content-Type", "application/json")
+		if report.Ready() {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+
+	http.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		report := utils.CheckReadiness(r.Context(), session)
+		w.Header().Set("Content-Type", "application/json")
+		if report.Ready() {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(struct {
+			utils.ReadinessReport
+			BotStatus string `json:"bot_status"`
+		}{report, botStatus})
+	})
+
+	// Top.gg posts votes here; the secret must match the webhook Authorization in the Top.gg dashboard
+	if secret := cfg.TopGG.WebhookSecret; secret != "" {
+		weekendMultiplier := 1.0
+		if cfg.TopGG.WeekendDouble {
+			weekendMultiplier = 2
+		}
+		http.Handle("/topgg/webhook", utils.NewTopGGWebhook(secret, weekendMultiplier, cfg.Bonuses.Vote.Cooldown, func() *discordgo.Session { return session }))
+	}
+
 	log.Printf("Health server starting on port %s", port)
 	if err := http.ListenAndServe(":"+port, nil); err != nil {
 		log.Printf("Health server error: %v", err)
@@ -900,28 +1598,35 @@ func handleHelpCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	cats := map[string][]string{
 		"Casino Games":   {"blackjack", "baccarat", "craps", "horl", "mines", "derby", "roulette", "slots", "tcpoker"},
 		"Bonuses":        {"hourly", "daily", "weekly", "vote", "bonus", "claimall", "cooldowns"},
-		"Profile / Rank": {"profile", "balance", "premium"},
+		"Profile / Rank": {"profile", "balance", "give", "premium", "stats", "fairness", "limits", "selfexclude"},
+		"Server Setup":   {"config"},
 	}
 	desc := map[string]string{
-		"blackjack": "Play Blackjack",
-		"baccarat":  "Play Baccarat",
-		"craps":     "Play Craps",
-		"horl":      "Play Higher or Lower",
-		"derby":     "Bet on Horse Racing",
-		"mines":     "Play Mines",
-		"roulette":  "Play Roulette",
-		"slots":     "Play Slots",
-		"tcpoker":   "Play Three Card Poker",
-		"hourly":    "Claim your hourly bonus",
-		"daily":     "Claim your daily bonus",
-		"weekly":    "Claim your weekly bonus",
-		"vote":      "Vote on Top.gg for bonus chips",
-		"bonus":     "Claim server bonus (High Roller Club members)",
-		"claimall":  "Claim all available bonuses",
-		"cooldowns": "View your bonus cooldowns",
-		"profile":   "View your casino profile and stats",
-		"balance":   "Check your chip balance",
-		"premium":   "Manage premium feature visibility",
+		"blackjack":   "Play Blackjack solo or open a table",
+		"baccarat":    "Play Baccarat",
+		"craps":       "Play Craps",
+		"horl":        "Play Higher or Lower",
+		"derby":       "Bet on Horse Racing",
+		"mines":       "Play Mines",
+		"roulette":    "Play Roulette",
+		"slots":       "Play Slots",
+		"tcpoker":     "Play Three Card Poker",
+		"hourly":      "Claim your hourly bonus",
+		"daily":       "Claim your daily bonus",
+		"weekly":      "Claim your weekly bonus",
+		"vote":        "Vote on Top.gg for bonus chips",
+		"bonus":       "Claim server bonus (High Roller Club members)",
+		"claimall":    "Claim all available bonuses",
+		"cooldowns":   "View your bonus cooldowns",
+		"profile":     "View your casino profile and stats",
+		"balance":     "Check your chip balance",
+		"premium":     "Manage premium feature visibility",
+		"fairness":    "Set your client seed or verify a past round",
+		"stats":       "View your per-game records and streaks",
+		"give":        "Send chips to another player (taxed)",
+		"selfexclude": "Lock yourself out of games and bonuses for a while",
+		"limits":      "Set loss and wager limits and reality-check reminders",
+		"config":      "Choose games, channels, bet limits and announcements (Manage Server)",
 	}
 	for name, cmds := range cats {
 		var lines []string
@@ -1074,21 +1779,7 @@ func handleProfileAchievementsButton(s *discordgo.Session, i *discordgo.Interact
 
 // /addchips (guild-only) with strict role check
 func handleAddChipsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	// Security: must be from configured guild
-	if i.GuildID != AdminGuildID {
-		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Unauthorized", "This command cannot be used in this server.", 0xE74C3C), nil, true)
-		return
-	}
-	// Must have role
-	hasRole := false
-	for _, rid := range i.Member.Roles {
-		if rid == AdminRoleID {
-			hasRole = true
-			break
-		}
-	}
-	if !hasRole {
-		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Forbidden", "You do not have permission to use this command.", 0xE74C3C), nil, true)
+	if !requireCasinoAdmin(s, i) {
 		return
 	}
 
@@ -1113,7 +1804,11 @@ func handleAddChipsCommand(s *discordgo.Session, i *discordgo.InteractionCreate)
 		return
 	}
 	tid, _ := strconv.ParseInt(targetID, 10, 64)
-	updated, err := utils.UpdateCachedUserWithNotification(tid, utils.UserUpdateData{ChipsIncrement: amount}, s, i)
+	updated, err := utils.UpdateCachedUserWithNotification(tid, utils.UserUpdateData{
+		ChipsIncrement: amount,
+		Reason:         utils.TxReasonAdminGrant,
+		Note:           fmt.Sprintf("by %s: %s", i.Member.User.ID, reason),
+	}, s, i)
 	if err != nil {
 		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Error", "Failed to update user.", 0xE74C3C), nil, true)
 		return
@@ -1122,20 +1817,991 @@ func handleAddChipsCommand(s *discordgo.Session, i *discordgo.InteractionCreate)
 	embed := utils.CreateBrandedEmbed("Chips Added", fmt.Sprintf("Successfully added %s chips to <@%s> for: %s.", utils.FormatChips(amount), targetID, reason), 0x2ECC71)
 	utils.SendInteractionResponse(s, i, embed, nil, true)
 	// Log
-	if ch, err := s.Channel(AdminLogChannelID); err == nil && ch != nil {
-		logEmbed := utils.CreateBrandedEmbed("Chip Transaction Log", "", 0x2ECC71)
-		logEmbed.Fields = []*discordgo.MessageEmbedField{
-			{Name: "Moderator", Value: i.Member.User.Mention(), Inline: false},
-			{Name: "User", Value: "<@" + targetID + ">", Inline: false},
-			{Name: "Amount Added", Value: utils.FormatChips(amount), Inline: false},
-			{Name: "New Balance", Value: utils.FormatChips(updated.Chips), Inline: false},
+	logEmbed := utils.CreateBrandedEmbed("Chip Transaction Log", "", 0x2ECC71)
+	logEmbed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "Moderator", Value: i.Member.User.Mention(), Inline: false},
+		{Name: "User", Value: "<@" + targetID + ">", Inline: false},
+		{Name: "Amount Added", Value: utils.FormatChips(amount), Inline: false},
+		{Name: "New Balance", Value: utils.FormatChips(updated.Chips), Inline: false},
+	}
+	logEmbed.Footer = &discordgo.MessageEmbedFooter{Text: "User ID: " + targetID}
+	sendAdminLog(s, logEmbed)
+	actorID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
+	if err := utils.RecordAdminAction(actorID, utils.AdminActionAddChips, tid, reason, utils.JSONB{"amount": amount, "balance": updated.Chips}); err != nil {
+		log.Printf("⚠️ Failed to audit addchips: %v", err)
+	}
+}
+
+// requireCasinoAdmin allows only the admin role in the admin guild, replying to anyone else
+func requireCasinoAdmin(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	// Security: must be from configured guild
+	if i.GuildID != utils.IDs.HomeGuildID || i.Member == nil {
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Unauthorized", "This command cannot be used in this server.", 0xE74C3C), nil, true)
+		return false
+	}
+	// Must have role
+	for _, rid := range i.Member.Roles {
+		if rid == utils.IDs.AdminRoleID {
+			return true
+		}
+	}
+	utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Forbidden", "You do not have permission to use this command.", 0xE74C3C), nil, true)
+	return false
+}
+
+// sendAdminLog posts an embed to the admin log channel if the bot can see it
+func sendAdminLog(s *discordgo.Session, embed *discordgo.MessageEmbed) {
+	if ch, err := s.Channel(utils.IDs.AdminLogChannelID); err == nil && ch != nil {
+		s.ChannelMessageSendEmbed(utils.IDs.AdminLogChannelID, embed)
+	}
+}
+
+// Lower bounds for integer command options
+var (
+	optionMinOne  = 1.0
+	optionMinZero = 0.0
+)
+
+// adminSubcommand builds an /admin subcommand taking a target user, a required reason and
+// any extra options
+func adminSubcommand(name, description string, extra ...*discordgo.ApplicationCommandOption) *discordgo.ApplicationCommandOption {
+	options := []*discordgo.ApplicationCommandOption{
+		{Type: discordgo.ApplicationCommandOptionUser, Name: "user", Description: "Target user", Required: true},
+	}
+	// Discord requires required options to come before optional ones
+	var optional []*discordgo.ApplicationCommandOption
+	for _, opt := range extra {
+		if opt.Required {
+			options = append(options, opt)
+		} else {
+			optional = append(optional, opt)
+		}
+	}
+	options = append(options, &discordgo.ApplicationCommandOption{
+		Type: discordgo.ApplicationCommandOptionString, Name: "reason", Description: "Reason for the action (recorded in the audit log)", Required: true,
+	})
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        name,
+		Description: description,
+		Options:     append(options, optional...),
+	}
+}
+
+// /admin (guild-only) moderation toolkit; every action is audited and logged
+func handleAdminCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !requireCasinoAdmin(s, i) {
+		return
+	}
+
+	data := i.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		return
+	}
+	sub := data.Options[0]
+	if sub.Type == discordgo.ApplicationCommandOptionSubCommandGroup {
+		if sub.Name == "achievements" {
+			handleAdminAchievements(s, i, sub)
+		}
+		return
+	}
+	opts := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(sub.Options))
+	for _, opt := range sub.Options {
+		opts[opt.Name] = opt
+	}
+
+	var target *discordgo.User
+	if opt, ok := opts["user"]; ok {
+		target = opt.UserValue(nil)
+	}
+	var reason string
+	if opt, ok := opts["reason"]; ok {
+		reason = strings.TrimSpace(opt.StringValue())
+	}
+	if target == nil || reason == "" {
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Invalid Input", "Please provide a valid user and a reason.", 0xE74C3C), nil, true)
+		return
+	}
+	targetID, _ := strconv.ParseInt(target.ID, 10, 64)
+	actorID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
+	note := fmt.Sprintf("by %s: %s", i.Member.User.ID, reason)
+	fail := func(title, message string) {
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed(title, message, 0xE74C3C), nil, true)
+	}
+
+	var action, summary string
+	details := utils.JSONB{}
+	switch sub.Name {
+	case "removechips":
+		amount := opts["amount"].IntValue()
+		current, err := utils.GetCachedUser(targetID)
+		if err != nil {
+			fail("Error", "Failed to load user.")
+			return
+		}
+		if amount > current.Chips {
+			fail("Invalid Input", fmt.Sprintf("<@%s> only has %s chips. Use `/admin setchips` to zero the balance.", target.ID, utils.FormatChips(current.Chips)))
+			return
+		}
+		updated, err := utils.UpdateCachedUserWithNotification(targetID, utils.UserUpdateData{
+			ChipsIncrement: -amount,
+			Reason:         utils.TxReasonAdjustment,
+			Note:           note,
+		}, s, i)
+		if err != nil {
+			fail("Error", "Failed to update user.")
+			return
+		}
+		action = utils.AdminActionRemoveChips
+		details["amount"], details["balance"] = amount, updated.Chips
+		summary = fmt.Sprintf("Removed %s chips from <@%s>. New balance: %s.", utils.FormatChips(amount), target.ID, utils.FormatChips(updated.Chips))
+	case "setchips":
+		amount := opts["amount"].IntValue()
+		updated, err := utils.UpdateCachedUserWithNotification(targetID, utils.UserUpdateData{
+			SetChips: &amount,
+			Reason:   utils.TxReasonAdjustment,
+			Note:     note,
+		}, s, i)
+		if err != nil {
+			fail("Error", "Failed to update user.")
+			return
+		}
+		action = utils.AdminActionSetChips
+		details["balance"] = updated.Chips
+		summary = fmt.Sprintf("Set <@%s>'s balance to %s chips.", target.ID, utils.FormatChips(updated.Chips))
+	case "reset":
+		if _, err := utils.ResetUserProgress(targetID, note); err != nil {
+			if errors.Is(err, utils.ErrStakesHeld) {
+				fail("Games In Progress", fmt.Sprintf("<@%s> has unfinished games; try again once they settle.", target.ID))
+				return
+			}
+			fail("Error", "Failed to reset user.")
+			return
+		}
+		action = utils.AdminActionResetUser
+		summary = fmt.Sprintf("Reset <@%s> to a fresh account.", target.ID)
+	case "revoke":
+		achievementID := int(opts["achievement"].IntValue())
+		var achievement *utils.Achievement
+		if utils.AchievementMgr != nil {
+			achievement = utils.AchievementMgr.GetAchievement(achievementID)
+		}
+		if achievement == nil {
+			fail("Invalid Input", fmt.Sprintf("There is no achievement with ID %d.", achievementID))
+			return
+		}
+		revoked, err := utils.RevokeAchievement(targetID, achievementID)
+		if err != nil {
+			fail("Error", "Failed to revoke achievement.")
+			return
+		}
+		if !revoked {
+			fail("Not Earned", fmt.Sprintf("<@%s> hasn't earned **%s**.", target.ID, achievement.Name))
+			return
+		}
+		action = utils.AdminActionRevokeAchievement
+		details["achievement_id"], details["achievement"] = achievementID, achievement.Name
+		summary = fmt.Sprintf("Revoked **%s** from <@%s>.", achievement.Name, target.ID)
+	case "resetcooldowns":
+		if _, err := utils.UpdateCachedUser(targetID, utils.UserUpdateData{ResetCooldowns: true}); err != nil {
+			fail("Error", "Failed to reset cooldowns.")
+			return
+		}
+		action = utils.AdminActionResetCooldowns
+		summary = fmt.Sprintf("Reset <@%s>'s bonus cooldowns.", target.ID)
+	case "ban", "cooldown":
+		kind := utils.RestrictionBan
+		action = utils.AdminActionBan
+		if sub.Name == "cooldown" {
+			kind, action = utils.RestrictionCooldown, utils.AdminActionCooldown
+		}
+		var expiresAt *time.Time
+		if opt, ok := opts["duration"]; ok {
+			length, err := utils.ParseDurationSpec(opt.StringValue())
+			if err != nil {
+				fail("Invalid Input", "Duration must look like `30m`, `12h`, `7d` or `2w`.")
+				return
+			}
+			until := time.Now().Add(length)
+			expiresAt = &until
+			details["duration"] = opt.StringValue()
+		}
+		if _, err := utils.AddRestriction(targetID, kind, reason, actorID, expiresAt); err != nil {
+			fail("Error", "Failed to restrict user.")
+			return
+		}
+		switch {
+		case kind == utils.RestrictionCooldown:
+			summary = fmt.Sprintf("Put <@%s> on a casino cooldown until <t:%d:f>.", target.ID, expiresAt.Unix())
+		case expiresAt != nil:
+			summary = fmt.Sprintf("Banned <@%s> from the casino until <t:%d:f>.", target.ID, expiresAt.Unix())
+		default:
+			summary = fmt.Sprintf("Banned <@%s> from the casino permanently.", target.ID)
+		}
+	case "unban":
+		// Self-exclusions are the player's own choice and are never lifted early
+		lifted, err := utils.LiftRestrictions(targetID, utils.RestrictionBan, utils.RestrictionCooldown)
+		if err != nil {
+			fail("Error", "Failed to unban user.")
+			return
+		}
+		if lifted == 0 {
+			fail("Not Banned", fmt.Sprintf("<@%s> is not banned or on cooldown.", target.ID))
+			return
+		}
+		action = utils.AdminActionUnban
+		summary = fmt.Sprintf("Lifted <@%s>'s casino ban or cooldown.", target.ID)
+	default:
+		return
+	}
+
+	if err := utils.RecordAdminAction(actorID, action, targetID, reason, details); err != nil {
+		log.Printf("⚠️ Failed to audit admin %s: %v", sub.Name, err)
+	}
+	utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Admin Action Complete", summary, 0x2ECC71), nil, true)
+
+	logEmbed := utils.CreateBrandedEmbed("Admin Action Log", summary, 0xE67E22)
+	logEmbed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "Moderator", Value: i.Member.User.Mention(), Inline: true},
+		{Name: "User", Value: "<@" + target.ID + ">", Inline: true},
+		{Name: "Action", Value: "`" + action + "`", Inline: true},
+		{Name: "Reason", Value: reason, Inline: false},
+	}
+	logEmbed.Footer = &discordgo.MessageEmbedFooter{Text: "User ID: " + target.ID}
+	sendAdminLog(s, logEmbed)
+}
+
+// handleAdminAchievements reloads the achievements file and reports what it changed
+func handleAdminAchievements(s *discordgo.Session, i *discordgo.InteractionCreate, group *discordgo.ApplicationCommandInteractionDataOption) {
+	if len(group.Options) == 0 || group.Options[0].Name != "reload" {
+		return
+	}
+	if utils.AchievementMgr == nil {
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Error", "Achievements are not loaded.", 0xE74C3C), nil, true)
+		return
+	}
+	diff, err := utils.AchievementMgr.ReloadAchievements()
+	if err != nil {
+		log.Printf("⚠️ Achievement reload failed: %v", err)
+		msg := []rune(err.Error())
+		if len(msg) > 3900 { // embed descriptions are capped at 4096
+			msg = append(msg[:3900], '…')
+		}
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Reload Failed", "Nothing was changed.\n```\n"+string(msg)+"\n```", 0xE74C3C), nil, true)
+		return
+	}
+
+	summary := "Achievements are already up to date."
+	if !diff.Empty() {
+		summary = fmt.Sprintf("Applied the achievements file: %d added, %d changed, %d missing from the file.",
+			len(diff.Added), len(diff.Changed), len(diff.Missing))
+	}
+	var fields []*discordgo.MessageEmbedField
+	for _, section := range []struct {
+		name    string
+		changes []utils.AchievementChange
+	}{
+		{"Added", diff.Added},
+		{"Changed", diff.Changed},
+		{"Missing (kept in the table)", diff.Missing},
+	} {
+		if len(section.changes) == 0 {
+			continue
+		}
+		const maxLines = 15
+		var lines []string
+		for n, c := range section.changes {
+			if n == maxLines {
+				lines = append(lines, fmt.Sprintf("…and %d more", len(section.changes)-maxLines))
+				break
+			}
+			line := fmt.Sprintf("`%d` %s", c.ID, c.Name)
+			if len(c.Fields) > 0 {
+				line += " (" + strings.Join(c.Fields, ", ") + ")"
+			}
+			lines = append(lines, line)
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{Name: section.name, Value: strings.Join(lines, "\n")})
+	}
+
+	actorID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
+	details := utils.JSONB{"added": len(diff.Added), "changed": len(diff.Changed), "missing": len(diff.Missing)}
+	if err := utils.RecordAdminAction(actorID, utils.AdminActionReloadAchievements, 0, "Reloaded achievement definitions", details); err != nil {
+		log.Printf("⚠️ Failed to audit achievements reload: %v", err)
+	}
+	embed := utils.CreateBrandedEmbed("Achievements Reloaded", summary, 0x2ECC71)
+	embed.Fields = fields
+	utils.SendInteractionResponse(s, i, embed, nil, true)
+
+	logEmbed := utils.CreateBrandedEmbed("Admin Action Log", summary, 0xE67E22)
+	logEmbed.Fields = append([]*discordgo.MessageEmbedField{
+		{Name: "Moderator", Value: i.Member.User.Mention(), Inline: true},
+		{Name: "Action", Value: "`" + utils.AdminActionReloadAchievements + "`", Inline: true},
+	}, fields...)
+	sendAdminLog(s, logEmbed)
+}
+
+// /give
+func handleGiveCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !utils.GatePlay(s, i) {
+		return
+	}
+	fromID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
+	var target *discordgo.User
+	var amountStr string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "user":
+			target = opt.UserValue(s)
+		case "amount":
+			amountStr = opt.StringValue()
+		}
+	}
+	if target == nil {
+		respondWithError(s, i, "❌ Please choose a player to give chips to.")
+		return
+	}
+	if target.Bot {
+		respondWithError(s, i, "❌ Bots can't receive chips.")
+		return
+	}
+
+	sender, err := utils.GetCachedUser(fromID)
+	if err != nil {
+		respondWithError(s, i, "❌ Error accessing user data.")
+		return
+	}
+	amount, err := utils.ParseBet(amountStr, sender.Chips)
+	if err != nil || amount <= 0 {
+		respondWithError(s, i, "❌ Invalid amount.")
+		return
+	}
+
+	// Large transfers ask the sender to confirm first
+	if amount >= cfg.Transfers.ConfirmThreshold {
+		tax := cfg.Transfers.TransferTax(amount)
+		embed := utils.CreateBrandedEmbed("🤝 Confirm Transfer",
+			fmt.Sprintf("Send **%s** %s to %s?\n\nHouse tax: %s\nThey receive: **%s** %s",
+				utils.FormatChips(amount), utils.ChipsEmoji, target.Mention(),
+				utils.FormatChips(tax), utils.FormatChips(amount-tax), utils.ChipsEmoji), 0xE67E22)
+		toID, _ := strconv.ParseInt(target.ID, 10, 64)
+		token := utils.HoldTransfer(fromID, toID, amount, time.Now())
+		confirmID := fmt.Sprintf("give_confirm_%d_%s", fromID, token)
+		cancelID := fmt.Sprintf("give_cancel_%d_%s", fromID, token)
+		utils.SendInteractionResponse(s, i, embed, utils.ConfirmationView(confirmID, cancelID), true)
+		return
+	}
+
+	embed, ok := completeGive(s, i, fromID, target.ID, amount)
+	utils.SendInteractionResponse(s, i, embed, nil, !ok)
+}
+
+// /limits
+func handleLimitsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
+	sub := i.ApplicationCommandData().Options[0]
+
+	switch sub.Name {
+	case "set":
+		var kind utils.PlayLimitKind
+		var amountStr string
+		for _, opt := range sub.Options {
+			switch opt.Name {
+			case "limit":
+				kind = utils.PlayLimitKind(opt.StringValue())
+			case "amount":
+				amountStr = strings.ToLower(strings.TrimSpace(opt.StringValue()))
+			}
+		}
+		var amount int64
+		if amountStr != "none" && amountStr != "off" && amountStr != "0" {
+			parsed, err := utils.ParseBet(amountStr, 0)
+			if err != nil || parsed <= 0 {
+				respondWithError(s, i, "❌ Amount must be a number of chips like `50000` or `50k`, or `none`.")
+				return
+			}
+			amount = parsed
+		}
+		limit, err := utils.SetPlayLimit(userID, kind, amount)
+		if err != nil {
+			log.Printf("Setting %s limit for %d failed: %v", kind, userID, err)
+			respondWithError(s, i, "❌ Failed to update your limit.")
+			return
+		}
+		label := utils.PlayLimitLabel(kind)
+		var msg string
+		switch {
+		case limit.PendingAt != nil && amount == 0:
+			msg = fmt.Sprintf("Your %s limit will be removed <t:%d:R>. Until then it stays at %s chips.", label, limit.PendingAt.Unix(), utils.FormatChips(limit.Amount))
+		case limit.PendingAt != nil:
+			msg = fmt.Sprintf("Your %s limit will rise to %s chips <t:%d:R>. Until then it stays at %s chips.", label, utils.FormatChips(amount), limit.PendingAt.Unix(), utils.FormatChips(limit.Amount))
+		case amount == 0:
+			msg = fmt.Sprintf("You have no %s limit.", label)
+		default:
+			msg = fmt.Sprintf("Your %s limit is now %s chips.", label, utils.FormatChips(amount))
+		}
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("🛡️ Limit Updated", msg, 0x2ECC71), nil, true)
+	case "realitycheck":
+		minutes := sub.Options[0].IntValue()
+		if _, err := utils.SetPlayLimit(userID, utils.LimitRealityCheck, minutes); err != nil {
+			log.Printf("Setting reality check for %d failed: %v", userID, err)
+			respondWithError(s, i, "❌ Failed to update your reality check.")
+			return
+		}
+		msg := "Reality checks are off."
+		if minutes > 0 {
+			msg = fmt.Sprintf("You'll get a reminder after every %d minutes of continuous play.", minutes)
+		}
+		utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("⏰ Reality Check Updated", msg, 0x2ECC71), nil, true)
+	default:
+		limits, err := utils.GetPlayLimits(userID)
+		if err != nil {
+			respondWithError(s, i, "❌ Failed to load your limits.")
+			return
+		}
+		usage, err := utils.GetPlayUsage(userID)
+		if err != nil {
+			respondWithError(s, i, "❌ Failed to load your limits.")
+			return
+		}
+		embed := utils.CreateBrandedEmbed("🛡️ Your Play Limits",
+			fmt.Sprintf("Lowering a limit applies at once; raising or removing one takes %d hours.", int(utils.LimitCoolingOff.Hours())), utils.BotColor)
+		now := time.Now()
+		for _, kind := range utils.PlayLimitKinds {
+			value := "Not set"
+			l, ok := limits[kind]
+			if ok && l.Effective(now) > 0 {
+				if kind == utils.LimitRealityCheck {
+					value = fmt.Sprintf("Every %d minutes", l.Effective(now))
+				} else {
+					value = fmt.Sprintf("%s / %s", utils.FormatChips(usage.Used(kind)), utils.FormatChips(l.Effective(now)))
+				}
+			} else if kind != utils.LimitRealityCheck {
+				value = fmt.Sprintf("Not set (%s used)", utils.FormatChips(usage.Used(kind)))
+			}
+			if ok && l.PendingAt != nil && now.Before(*l.PendingAt) {
+				if *l.PendingAmount == 0 {
+					value += fmt.Sprintf("\nRemoved <t:%d:R>", l.PendingAt.Unix())
+				} else {
+					value += fmt.Sprintf("\nRises to %s <t:%d:R>", utils.FormatChips(*l.PendingAmount), l.PendingAt.Unix())
+				}
+			}
+			name := strings.ToUpper(utils.PlayLimitLabel(kind)[:1]) + utils.PlayLimitLabel(kind)[1:]
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: name, Value: value, Inline: true})
+		}
+		utils.SendInteractionResponse(s, i, embed, nil, true)
+	}
+}
+
+// configPermissions hides /config from members who can't manage the server
+var configPermissions int64 = discordgo.PermissionManageGuild
+
+// localeChoices offers every language a server can pick
+func localeChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(utils.GuildLocales))
+	for _, l := range utils.GuildLocales {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: discordgo.Locales[l], Value: string(l)})
+	}
+	return choices
+}
+
+// /config
+func handleConfigCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Discord hides the command from other members, but the permission can be overridden
+	if i.Member == nil || i.Member.Permissions&discordgo.PermissionManageGuild == 0 {
+		respondWithError(s, i, "❌ You need the Manage Server permission to configure the casino.")
+		return
+	}
+	guildID, _ := strconv.ParseInt(i.GuildID, 10, 64)
+	sub := i.ApplicationCommandData().Options[0]
+	opts := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(sub.Options))
+	for _, opt := range sub.Options {
+		opts[opt.Name] = opt
+	}
+
+	var change func(*utils.GuildSettings) error
+	var summary string
+	switch sub.Name {
+	case "game":
+		game, enabled := opts["game"].StringValue(), opts["enabled"].BoolValue()
+		change = func(g *utils.GuildSettings) error {
+			g.DisabledGames = slices.DeleteFunc(g.DisabledGames, func(d string) bool { return d == game })
+			if !enabled {
+				g.DisabledGames = append(g.DisabledGames, game)
+			}
+			return nil
+		}
+		summary = utils.GameDisplayName(game) + " is now **disabled**."
+		if enabled {
+			summary = utils.GameDisplayName(game) + " is now **enabled**."
+		}
+	case "channels":
+		action := opts["action"].StringValue()
+		var channelID int64
+		if opt, ok := opts["channel"]; ok {
+			channelID, _ = strconv.ParseInt(opt.ChannelValue(nil).ID, 10, 64)
+		}
+		if action != "reset" && channelID == 0 {
+			respondWithError(s, i, "❌ Pick a channel to allow or remove.")
+			return
+		}
+		change = func(g *utils.GuildSettings) error {
+			g.AllowedChannels = slices.DeleteFunc(g.AllowedChannels, func(c int64) bool { return c == channelID })
+			switch action {
+			case "allow":
+				g.AllowedChannels = append(g.AllowedChannels, channelID)
+			case "reset":
+				g.AllowedChannels = nil
+			}
+			return nil
+		}
+		switch action {
+		case "allow":
+			summary = fmt.Sprintf("Games can now be played in <#%d>.", channelID)
+		case "remove":
+			summary = fmt.Sprintf("<#%d> is no longer a casino channel.", channelID)
+		default:
+			summary = "Games can be played in every channel."
+		}
+	case "betlimits":
+		game := opts["game"].StringValue()
+		change = func(g *utils.GuildSettings) error {
+			limit := g.BetLimits[game]
+			if opt, ok := opts["min"]; ok {
+				limit.Min = opt.IntValue()
+			}
+			if opt, ok := opts["max"]; ok {
+				limit.Max = opt.IntValue()
+			}
+			if limit.Min > 0 && limit.Max > 0 && limit.Min > limit.Max {
+				return errConfigInput("The minimum bet can't be above the maximum.")
+			}
+			g.BetLimits[game] = limit
+			summary = fmt.Sprintf("%s bets: %s.", utils.GameDisplayName(game), formatBetLimit(limit))
+			return nil
+		}
+	case "announcements":
+		change = func(g *utils.GuildSettings) error {
+			g.AnnounceChannelID = 0
+			if opt, ok := opts["channel"]; ok {
+				g.AnnounceChannelID, _ = strconv.ParseInt(opt.ChannelValue(nil).ID, 10, 64)
+			}
+			if opt, ok := opts["threshold"]; ok {
+				g.BigWinThreshold = opt.IntValue()
+			}
+			if g.AnnounceChannelID == 0 {
+				summary = "Big-win announcements are off."
+			} else {
+				summary = fmt.Sprintf("Wins of %s chips or more will be announced in <#%d>.", utils.FormatChips(g.BigWinThreshold), g.AnnounceChannelID)
+			}
+			return nil
+		}
+	case "locale":
+		locale := discordgo.Locale(opts["locale"].StringValue())
+		if !slices.Contains(utils.GuildLocales, locale) {
+			respondWithError(s, i, "❌ That language isn't supported.")
+			return
+		}
+		change = func(g *utils.GuildSettings) error {
+			g.Locale = locale
+			return nil
+		}
+		summary = fmt.Sprintf("Big-win announcements will be posted in %s.", discordgo.Locales[locale])
+	default:
+		settings, err := utils.GetGuildSettings(guildID)
+		if err != nil {
+			respondWithError(s, i, "❌ Failed to load this server's settings.")
+			return
+		}
+		utils.SendInteractionResponse(s, i, guildSettingsEmbed(settings), nil, true)
+		return
+	}
+
+	settings, err := utils.UpdateGuildSettings(guildID, change)
+	var inputErr errConfigInput
+	if errors.As(err, &inputErr) {
+		respondWithError(s, i, "❌ "+string(inputErr))
+		return
+	}
+	if err != nil {
+		log.Printf("Updating settings for guild %d failed: %v", guildID, err)
+		respondWithError(s, i, "❌ Failed to update this server's settings.")
+		return
+	}
+	log.Printf("Guild %d settings changed by %s: %s", guildID, i.Member.User.ID, sub.Name)
+	embed := guildSettingsEmbed(settings)
+	embed.Description = summary
+	utils.SendInteractionResponse(s, i, embed, nil, true)
+}
+
+// errConfigInput rejects a /config change the admin needs to fix
+type errConfigInput string
+
+func (e errConfigInput) Error() string { return string(e) }
+
+// formatBetLimit describes a game's bet range
+func formatBetLimit(l utils.BetLimit) string {
+	switch {
+	case l.Min > 0 && l.Max > 0:
+		return fmt.Sprintf("%s – %s", utils.FormatChips(l.Min), utils.FormatChips(l.Max))
+	case l.Min > 0:
+		return "at least " + utils.FormatChips(l.Min)
+	case l.Max > 0:
+		return "up to " + utils.FormatChips(l.Max)
+	}
+	return "no limits"
+}
+
+// guildSettingsEmbed summarises a server's casino settings
+func guildSettingsEmbed(g *utils.GuildSettings) *discordgo.MessageEmbed {
+	embed := utils.CreateBrandedEmbed("⚙️ Server Settings", "", utils.BotColor)
+
+	var games []string
+	for _, m := range utils.GameModules() {
+		status := "✅"
+		if !g.GameEnabled(m.GameType) {
+			status = "❌"
+		}
+		line := status + " " + utils.GameDisplayName(m.GameType)
+		if limit, ok := g.BetLimits[m.GameType]; ok && (limit.Min > 0 || limit.Max > 0) {
+			line += " (" + formatBetLimit(limit) + ")"
+		}
+		games = append(games, line)
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Games", Value: strings.Join(games, "\n")})
+
+	channels := "All channels"
+	if len(g.AllowedChannels) > 0 {
+		mentions := make([]string, len(g.AllowedChannels))
+		for n, c := range g.AllowedChannels {
+			mentions[n] = fmt.Sprintf("<#%d>", c)
+		}
+		channels = strings.Join(mentions, ", ")
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Casino Channels", Value: channels})
+
+	announce := "Off"
+	if g.AnnounceChannelID != 0 {
+		announce = fmt.Sprintf("<#%d> for wins of %s+", g.AnnounceChannelID, utils.FormatChips(g.BigWinThreshold))
+	}
+	embed.Fields = append(embed.Fields,
+		&discordgo.MessageEmbedField{Name: "Big-Win Announcements", Value: announce, Inline: true},
+		&discordgo.MessageEmbedField{Name: "Announcement Language", Value: discordgo.Locales[g.Locale], Inline: true},
+	)
+	return embed
+}
+
+// /selfexclude
+func handleSelfExcludeCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var spec string
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "duration" {
+			spec = strings.ToLower(strings.TrimSpace(opt.StringValue()))
+		}
+	}
+	length, err := utils.ParseDurationSpec(spec)
+	if err != nil {
+		respondWithError(s, i, "❌ Duration must look like `1d`, `7d` or `4w`.")
+		return
+	}
+	if length < utils.MinSelfExclusion || length > utils.MaxSelfExclusion {
+		respondWithError(s, i, "❌ Self-exclusion must last between 1 day and 1 year.")
+		return
+	}
+
+	until := time.Now().Add(length)
+	embed := utils.CreateBrandedEmbed("🛑 Confirm Self-Exclusion",
+		fmt.Sprintf("You won't be able to play games, claim bonuses or give chips until <t:%d:f>.\n\n**This can't be undone early, even by staff.**", until.Unix()), 0xE67E22)
+	confirmID := fmt.Sprintf("selfexclude_confirm_%s_%s", i.Member.User.ID, spec)
+	cancelID := fmt.Sprintf("selfexclude_cancel_%s", i.Member.User.ID)
+	utils.SendInteractionResponse(s, i, embed, utils.ConfirmationView(confirmID, cancelID), true)
+}
+
+func handleSelfExcludeButtons(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	parts := strings.Split(i.MessageComponentData().CustomID, "_")
+	if len(parts) < 3 || parts[2] != i.Member.User.ID {
+		respondWithError(s, i, "❌ This isn't your request.")
+		return
+	}
+
+	var embed *discordgo.MessageEmbed
+	switch {
+	case parts[1] == "cancel":
+		embed = utils.CreateBrandedEmbed("🛑 Self-Exclusion Canceled", "Nothing has changed.", 0x95A5A6)
+	case parts[1] == "confirm" && len(parts) == 4:
+		userID, _ := strconv.ParseInt(parts[2], 10, 64)
+		length, err := utils.ParseDurationSpec(parts[3])
+		if err != nil {
+			return
+		}
+		restriction, err := utils.SelfExclude(userID, length)
+		if err != nil {
+			log.Printf("Self-exclusion for %d failed: %v", userID, err)
+			embed = utils.CreateBrandedEmbed("❌ Self-Exclusion Failed", "Please try again.", 0xE74C3C)
+			break
+		}
+		embed = utils.CreateBrandedEmbed("🛑 Self-Exclusion Active",
+			fmt.Sprintf("You're excluded from games, bonuses and transfers until <t:%d:f>. Take care of yourself.", restriction.ExpiresAt.Unix()), 0x2ECC71)
+	default:
+		return
+	}
+	utils.UpdateComponentInteraction(s, i, embed, []discordgo.MessageComponent{})
+}
+
+func handleGiveButtons(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	parts := strings.Split(i.MessageComponentData().CustomID, "_")
+	if len(parts) < 3 || parts[2] != i.Member.User.ID {
+		respondWithError(s, i, "❌ This transfer isn't yours.")
+		return
+	}
+
+	if len(parts) != 4 {
+		return
+	}
+	// Taking the token first makes a double click send the chips only once
+	pending, ok := utils.TakeTransfer(parts[3], time.Now())
+	var embed *discordgo.MessageEmbed
+	switch {
+	case !ok:
+		embed = utils.CreateBrandedEmbed("🤝 Transfer Expired", "This transfer was already handled or has expired. Use `/give` again.", 0x95A5A6)
+	case parts[1] == "cancel":
+		embed = utils.CreateBrandedEmbed("🤝 Transfer Canceled", "No chips were sent.", 0x95A5A6)
+	case parts[1] == "confirm":
+		embed, _ = completeGive(s, i, pending.FromID, strconv.FormatInt(pending.ToID, 10), pending.Amount)
+	default:
+		return
+	}
+	utils.UpdateComponentInteraction(s, i, embed, []discordgo.MessageComponent{})
+}
+
+// completeGive runs a transfer, logs it for the admins and returns the embed describing it
+// along with whether it went through
+func completeGive(s *discordgo.Session, i *discordgo.InteractionCreate, fromID int64, targetID string, amount int64) (*discordgo.MessageEmbed, bool) {
+	toID, _ := strconv.ParseInt(targetID, 10, 64)
+	result, err := cfg.Transfers.TransferChips(fromID, toID, amount, s, i)
+	if err != nil {
+		msg := err.Error()
+		switch {
+		case errors.Is(err, utils.ErrTransferTooSmall):
+			msg = fmt.Sprintf("The minimum transfer is %s chips", utils.FormatChips(cfg.Transfers.MinAmount))
+		case errors.Is(err, utils.ErrTransferSenderIneligible), errors.Is(err, utils.ErrTransferRecipientNew):
+			msg += fmt.Sprintf(" (accounts need to be %d days old with %d games played)",
+				int(cfg.Transfers.MinAccountAge.Hours()/24), cfg.Transfers.MinGamesPlayed)
+		case !isTransferRule(err):
+			log.Printf("Transfer %d -> %d failed: %v", fromID, toID, err)
+			msg = "The transfer failed. No chips were moved"
+		}
+		return utils.CreateBrandedEmbed("❌ Transfer Failed", strings.ToUpper(msg[:1])+msg[1:]+".", 0xE74C3C), false
+	}
+
+	embed := utils.CreateBrandedEmbed("🤝 Chips Sent",
+		fmt.Sprintf("<@%d> sent **%s** %s to <@%s>.", fromID, utils.FormatChips(result.Received), utils.ChipsEmoji, targetID), 0x2ECC71)
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "House Tax", Value: utils.FormatChips(result.Tax), Inline: true},
+		{Name: "Your Balance", Value: fmt.Sprintf("%s %s", utils.FormatChips(result.Sender.Chips), utils.ChipsEmoji), Inline: true},
+	}
+
+	logEmbed := utils.CreateBrandedEmbed("Chip Transfer Log", "", 0x3498DB)
+	logEmbed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "From", Value: fmt.Sprintf("<@%d>", fromID), Inline: true},
+		{Name: "To", Value: "<@" + targetID + ">", Inline: true},
+		{Name: "Amount Sent", Value: utils.FormatChips(result.Amount), Inline: false},
+		{Name: "Tax", Value: utils.FormatChips(result.Tax), Inline: true},
+		{Name: "Received", Value: utils.FormatChips(result.Received), Inline: true},
+	}
+	logEmbed.Footer = &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Sender ID: %d • Recipient ID: %s", fromID, targetID)}
+	sendAdminLog(s, logEmbed)
+	return embed, true
+}
+
+// isTransferRule reports whether a transfer error is a rule the player broke rather than a fault
+func isTransferRule(err error) bool {
+	for _, rule := range []error{
+		utils.ErrTransferSelf, utils.ErrTransferTooSmall, utils.ErrTransferInsufficientChips,
+		utils.ErrTransferUnknownRecipient, utils.ErrTransferSendCap, utils.ErrTransferReceiveCap,
+		utils.ErrTransferSenderIneligible, utils.ErrTransferRecipientNew,
+	} {
+		if errors.Is(err, rule) {
+			return true
 		}
-		logEmbed.Footer = &discordgo.MessageEmbedFooter{Text: "User ID: " + targetID}
-		s.ChannelMessageSendEmbed(AdminLogChannelID, logEmbed)
 	}
+	return false
+}
+
+func handleFairnessCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		return
+	}
+	sub := data.Options[0]
+	opts := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(sub.Options))
+	for _, opt := range sub.Options {
+		opts[opt.Name] = opt
+	}
+
+	userID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
+
+	switch sub.Name {
+	case "seed":
+		if opt, ok := opts["client_seed"]; ok {
+			if err := utils.SetClientSeed(userID, strings.TrimSpace(opt.StringValue())); err != nil {
+				utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Fairness", err.Error(), 0xE74C3C), nil, true)
+				return
+			}
+		}
+		clientSeed, nonce, err := utils.GetClientSeed(userID)
+		if err != nil {
+			utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Fairness", "Failed to load your seeds.", 0xE74C3C), nil, true)
+			return
+		}
+		if clientSeed == "" {
+			clientSeed = "(assigned on your first round)"
+		}
+		embed := utils.CreateBrandedEmbed("Fairness", "Each round commits to a fresh server seed hash before play and reveals the seed when the round ends.", utils.BotColor)
+		embed.Fields = []*discordgo.MessageEmbedField{
+			{Name: "Client Seed", Value: "`" + clientSeed + "`", Inline: false},
+			{Name: "Rounds Played", Value: strconv.FormatInt(nonce, 10), Inline: true},
+		}
+		utils.SendInteractionResponse(s, i, embed, nil, true)
+
+	case "verify":
+		var round *utils.FairRound
+		if opt, ok := opts["round"]; ok {
+			r, err := utils.GetFairRound(opt.IntValue())
+			if err != nil {
+				utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Fairness", "Round not found.", 0xE74C3C), nil, true)
+				return
+			}
+			if !r.Revealed {
+				utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Fairness", fmt.Sprintf("Round #%d is still in play; its server seed is revealed when it ends.\nCommitted hash: `%s`", r.ID, r.ServerSeedHash), 0xF39C12), nil, true)
+				return
+			}
+			round = r
+		} else {
+			round = &utils.FairRound{Params: utils.JSONB{}}
+			if opt, ok := opts["game"]; ok {
+				round.GameType = opt.StringValue()
+			}
+			if opt, ok := opts["server_seed"]; ok {
+				round.ServerSeed = strings.TrimSpace(opt.StringValue())
+			}
+			if opt, ok := opts["client_seed"]; ok {
+				round.ClientSeed = strings.TrimSpace(opt.StringValue())
+			}
+			if opt, ok := opts["nonce"]; ok {
+				round.Nonce = opt.IntValue()
+			}
+			if opt, ok := opts["mines"]; ok {
+				round.Params["mines"] = opt.IntValue()
+			}
+			if round.GameType == "" || round.ServerSeed == "" || round.ClientSeed == "" {
+				utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Fairness", "Provide a round number, or the game, server seed, client seed and nonce.", 0xE74C3C), nil, true)
+				return
+			}
+			round.ServerSeedHash = rng.HashServerSeed(round.ServerSeed)
+		}
+
+		outcome, err := fairOutcome(round.GameType, rng.NewFair(round.ServerSeed, round.ClientSeed, round.Nonce), round.Params)
+		if err != nil {
+			utils.SendInteractionResponse(s, i, utils.CreateBrandedEmbed("Fairness", err.Error(), 0xE74C3C), nil, true)
+			return
+		}
+
+		hashCheck := "✅ SHA-256(server seed) matches the committed hash"
+		if rng.HashServerSeed(round.ServerSeed) != round.ServerSeedHash {
+			hashCheck = "❌ SHA-256(server seed) does not match the committed hash"
+		}
+
+		title := "Fairness Verification"
+		if round.ID > 0 {
+			title = fmt.Sprintf("Fairness Verification — Round #%d", round.ID)
+		}
+		embed := utils.CreateBrandedEmbed(title, hashCheck, utils.BotColor)
+		embed.Fields = []*discordgo.MessageEmbedField{
+			{Name: "Game", Value: round.GameType, Inline: true},
+			{Name: "Nonce", Value: strconv.FormatInt(round.Nonce, 10), Inline: true},
+			{Name: "Server Seed", Value: "`" + round.ServerSeed + "`", Inline: false},
+			{Name: "Server Seed Hash", Value: "`" + round.ServerSeedHash + "`", Inline: false},
+			{Name: "Client Seed", Value: "`" + round.ClientSeed + "`", Inline: false},
+			{Name: "Outcome", Value: outcome, Inline: false},
+		}
+		utils.SendInteractionResponse(s, i, embed, nil, true)
+	}
+}
+
+// fairOutcome replays the outcome a game draws first from a fair round's source
+func fairOutcome(gameType string, src rng.Source, params utils.JSONB) (string, error) {
+	switch gameType {
+	case "mines":
+		count := fairParamInt(params, "mines", 0)
+		if count < 1 || count > 19 {
+			return "", fmt.Errorf("mines verification needs a mine count between 1 and 19")
+		}
+		var b strings.Builder
+		for _, row := range mines.PlaceMines(src, count) {
+			for _, mine := range row {
+				if mine {
+					b.WriteString("💣")
+				} else {
+					b.WriteString("💎")
+				}
+			}
+			b.WriteString("\n")
+		}
+		return b.String(), nil
+	case "slots":
+		reels := slots.CreateReels(src)
+		rows := make([]string, len(reels))
+		for r, row := range reels {
+			rows[r] = strings.Join(row, " ")
+		}
+		return strings.Join(rows, "\n"), nil
+	case "roulette":
+		return fmt.Sprintf("Ball lands on **%d**", roulette.SpinWheel(src)), nil
+	case "craps":
+		rolls := make([]string, 10)
+		for n := range rolls {
+			d1, d2 := craps.RollDice(src)
+			rolls[n] = fmt.Sprintf("%d+%d", d1, d2)
+		}
+		return "First rolls: " + strings.Join(rolls, ", "), nil
+	case "blackjack", "baccarat", "higher_or_lower", "three_card_poker":
+		decks := fairParamInt(params, "decks", fairDeckCounts[gameType])
+		deck := utils.NewDeckWithSource(decks, gameType, src)
+		cards := make([]string, 0, 12)
+		for _, c := range deck.DealMultiple(12) {
+			cards = append(cards, "`"+c.String()+"`")
+		}
+		return fmt.Sprintf("Top of the %d-deck shoe: %s", decks, strings.Join(cards, " ")), nil
+	}
+	return "", fmt.Errorf("unknown game %q", gameType)
+}
+
+// fairDeckCounts is the shoe size each card game deals from
+var fairDeckCounts = map[string]int{
+	"blackjack":        utils.DeckCount,
+	"baccarat":         6,
+	"higher_or_lower":  1,
+	"three_card_poker": 1,
+}
+
+// fairParamInt reads an integer round parameter; JSONB numbers decode as float64
+func fairParamInt(params utils.JSONB, key string, def int) int {
+	switch v := params[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case int64:
+		return int(v)
+	}
+	return def
 }
 
 func handleBonusCommand(s *discordgo.Session, i *discordgo.InteractionCreate, bonusType utils.BonusType) {
+	if !utils.GatePlay(s, i) {
+		return
+	}
 	userID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
 
 	// Get or create user
@@ -1165,6 +2831,9 @@ func handleBonusCommand(s *discordgo.Session, i *discordgo.InteractionCreate, bo
 }
 
 func handleBonusServerCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !utils.GatePlay(s, i) {
+		return
+	}
 	userID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
 
 	// Check if user is in the main support server
@@ -1183,7 +2852,7 @@ func handleBonusServerCommand(s *discordgo.Session, i *discordgo.InteractionCrea
 	}
 
 	// Check if command is being run in the main support server
-	if i.GuildID != utils.MainSupportServerID {
+	if i.GuildID != utils.IDs.HomeGuildID {
 		embed := utils.CreateBrandedEmbed("🏠 High Roller Club Bonus",
 			"This command can only be used in the main support server!\n\n[🔗 Join High Roller Club](https://discord.gg/RK4K8tDsHB)",
 			0xE74C3C)
@@ -1245,6 +2914,9 @@ func handleCooldownsCommand(s *discordgo.Session, i *discordgo.InteractionCreate
 }
 
 func handleClaimAllCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !utils.GatePlay(s, i) {
+		return
+	}
 	userID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
 
 	// Get or create user
@@ -1285,9 +2957,16 @@ func handleClaimAllCommand(s *discordgo.Session, i *discordgo.InteractionCreate)
 
 			// Format bonus line like Python version
 			bonusName := strings.Title(string(bonus.BonusInfo.Type))
-			claimedList = append(claimedList,
-				fmt.Sprintf("• **%s**: %s %s", bonusName,
-					utils.FormatChips(bonus.BonusInfo.ActualAmount), utils.ChipsEmoji))
+			line := fmt.Sprintf("• **%s**: %s %s", bonusName,
+				utils.FormatChips(bonus.BonusInfo.ActualAmount), utils.ChipsEmoji)
+			if streak := bonus.BonusInfo.Streak; streak != nil {
+				line += fmt.Sprintf(" · 🔥 %d", streak.Streak.Current)
+				if m := streak.Milestone; m != nil {
+					totalChips += m.Chips
+					line += fmt.Sprintf(" (milestone +%s %s)", utils.FormatChips(m.Chips), utils.ChipsEmoji)
+				}
+			}
+			claimedList = append(claimedList, line)
 		}
 	}
 
@@ -1321,6 +3000,9 @@ func respondWithError(s *discordgo.Session, i *discordgo.InteractionCreate, mess
 }
 
 func handleVoteCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !utils.GatePlay(s, i) {
+		return
+	}
 	userID, _ := strconv.ParseInt(i.Member.User.ID, 10, 64)
 
 	// Check if Top.gg client is available
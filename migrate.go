@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"hrc-go/utils"
+)
+
+const migrateUsage = `usage: hrc-go migrate [command]
+
+commands:
+  up          apply all pending migrations (default)
+  down [n]    roll back the last n applied migrations (default 1)
+  status      list migrations and when they were applied`
+
+// runMigrateCommand implements the "migrate" subcommand and returns the process exit code
+func runMigrateCommand(args []string) int {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	if err := utils.ConnectDatabase(cfg.DatabaseURL); err != nil {
+		fmt.Fprintf(os.Stderr, "Database connection failed: %v\n", err)
+		return 1
+	}
+	if utils.DB == nil {
+		fmt.Fprintln(os.Stderr, "DATABASE_URL is not set")
+		return 1
+	}
+	defer utils.CloseDatabase()
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		applied, err := utils.MigrateUp(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Applied %d migrations\n", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				return 2
+			}
+			steps = n
+		}
+		rolledBack, err := utils.MigrateDown(ctx, steps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Rollback failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Rolled back %d migrations\n", rolledBack)
+
+	case "status":
+		status, err := utils.GetMigrationStatus(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Status failed: %v\n", err)
+			return 1
+		}
+		for _, m := range status {
+			applied := "pending"
+			if m.AppliedAt != nil {
+				applied = m.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d  %-24s %s\n", m.Version, m.Name, applied)
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	return 0
+}
@@ -0,0 +1,285 @@
+package utils
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"unicode/utf8"
+)
+
+// The built-in achievements. A file with the same schema can replace them with
+// LoadAchievementDefinitions.
+//
+//go:embed achievements.json
+var embeddedAchievements []byte
+
+// Column limits of the achievements table
+const (
+	maxAchievementNameLen = 100
+	maxAchievementIconLen = 50
+)
+
+// achievementDefinition is one entry of an achievements file
+type achievementDefinition struct {
+	ID               int                 `json:"id"`
+	Name             string              `json:"name"`
+	Description      string              `json:"description"`
+	Icon             string              `json:"icon"`
+	Category         AchievementCategory `json:"category"`
+	RequirementType  RequirementType     `json:"requirement_type"`
+	RequirementValue int64               `json:"requirement_value"`
+	ChipsReward      int64               `json:"chips_reward"`
+	XPReward         int64               `json:"xp_reward"`
+	Hidden           bool                `json:"hidden"`
+}
+
+// achievementDefs holds the active definitions and where they were read from
+var achievementDefs = struct {
+	sync.RWMutex
+	path string // empty for the built-in file
+	list []*Achievement
+}{}
+
+// LoadAchievementDefinitions reads and validates the achievements file at path, or the
+// built-in one if path is empty, and makes it the source of achievement defaults
+func LoadAchievementDefinitions(path string) error {
+	list, err := readAchievementDefinitions(path)
+	if err != nil {
+		return err
+	}
+	achievementDefs.Lock()
+	achievementDefs.path, achievementDefs.list = path, list
+	achievementDefs.Unlock()
+	return nil
+}
+
+func readAchievementDefinitions(path string) ([]*Achievement, error) {
+	data := embeddedAchievements
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read achievements file: %w", err)
+		}
+	}
+	return parseAchievementDefinitions(data)
+}
+
+// parseAchievementDefinitions decodes an achievements file and checks every entry,
+// reporting all problems at once
+func parseAchievementDefinitions(data []byte) ([]*Achievement, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var defs []achievementDefinition
+	if err := dec.Decode(&defs); err != nil {
+		return nil, fmt.Errorf("failed to parse achievements file: %w", err)
+	}
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("achievements file is empty")
+	}
+
+	var errs []error
+	check := func(ok bool, def achievementDefinition, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("achievement %d (%s): %s", def.ID, def.Name, fmt.Sprintf(format, args...)))
+		}
+	}
+	ids := make(map[int]bool, len(defs))
+	names := make(map[string]bool, len(defs))
+	list := make([]*Achievement, 0, len(defs))
+	for _, def := range defs {
+		check(def.ID > 0, def, "id must be positive")
+		check(!ids[def.ID], def, "duplicate id")
+		check(!names[def.Name], def, "duplicate name")
+		check(def.Name != "" && utf8.RuneCountInString(def.Name) <= maxAchievementNameLen, def,
+			"name must be 1-%d characters", maxAchievementNameLen)
+		check(def.Description != "", def, "description is required")
+		check(def.Icon != "" && utf8.RuneCountInString(def.Icon) <= maxAchievementIconLen, def,
+			"icon must be 1-%d characters", maxAchievementIconLen)
+		check(slices.Contains(AchievementCategories, def.Category), def, "unknown category %q", def.Category)
+		check(slices.Contains(RequirementTypes, def.RequirementType), def, "unknown requirement type %q", def.RequirementType)
+		if def.RequirementType == RequirementSpecial {
+			_, ok := achievementEvaluators[def.Name]
+			check(ok, def, "no evaluator is registered for this special achievement")
+		}
+		check(def.RequirementValue > 0, def, "requirement_value must be positive")
+		check(def.ChipsReward >= 0 && def.XPReward >= 0, def, "rewards can't be negative")
+		ids[def.ID], names[def.Name] = true, true
+
+		list = append(list, &Achievement{
+			ID:               def.ID,
+			Name:             def.Name,
+			Description:      def.Description,
+			Icon:             def.Icon,
+			Category:         string(def.Category),
+			RequirementType:  string(def.RequirementType),
+			RequirementValue: def.RequirementValue,
+			ChipsReward:      def.ChipsReward,
+			XPReward:         def.XPReward,
+			Hidden:           def.Hidden,
+		})
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid achievements file: %w", errors.Join(errs...))
+	}
+	return list, nil
+}
+
+// defaultAchievements returns copies of the active definitions, loading the built-in
+// ones if none were loaded at startup
+func defaultAchievements() []*Achievement {
+	achievementDefs.RLock()
+	list := achievementDefs.list
+	achievementDefs.RUnlock()
+	if list == nil {
+		var err error
+		if list, err = parseAchievementDefinitions(embeddedAchievements); err != nil {
+			panic(err) // the built-in file is checked by tests
+		}
+	}
+
+	return cloneAchievements(list)
+}
+
+// cloneAchievements copies each achievement so the copies can be changed freely
+func cloneAchievements(list []*Achievement) []*Achievement {
+	copies := make([]*Achievement, len(list))
+	for n, a := range list {
+		c := *a
+		copies[n] = &c
+	}
+	return copies
+}
+
+// AchievementChange is one achievement that differs between the file and the table
+type AchievementChange struct {
+	ID     int
+	Name   string
+	Fields []string // columns that changed; empty for added or missing achievements
+}
+
+// AchievementDiff is what applying an achievements file changes in the table
+type AchievementDiff struct {
+	Added   []AchievementChange
+	Changed []AchievementChange
+	Missing []AchievementChange // in the table but not the file; kept so earned ones stay valid
+}
+
+// Empty reports whether the file and the table already agree
+func (d AchievementDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Missing) == 0
+}
+
+// diffAchievements compares file definitions with stored achievements by ID
+func diffAchievements(file, stored []*Achievement) AchievementDiff {
+	byID := make(map[int]*Achievement, len(stored))
+	for _, a := range stored {
+		byID[a.ID] = a
+	}
+
+	var diff AchievementDiff
+	inFile := make(map[int]bool, len(file))
+	for _, a := range file {
+		inFile[a.ID] = true
+		old, ok := byID[a.ID]
+		if !ok {
+			diff.Added = append(diff.Added, AchievementChange{ID: a.ID, Name: a.Name})
+			continue
+		}
+		var fields []string
+		for _, f := range []struct {
+			name    string
+			changed bool
+		}{
+			{"name", old.Name != a.Name},
+			{"description", old.Description != a.Description},
+			{"icon", old.Icon != a.Icon},
+			{"category", old.Category != a.Category},
+			{"requirement_type", old.RequirementType != a.RequirementType},
+			{"requirement_value", old.RequirementValue != a.RequirementValue},
+			{"chips_reward", old.ChipsReward != a.ChipsReward},
+			{"xp_reward", old.XPReward != a.XPReward},
+			{"hidden", old.Hidden != a.Hidden},
+		} {
+			if f.changed {
+				fields = append(fields, f.name)
+			}
+		}
+		if len(fields) > 0 {
+			diff.Changed = append(diff.Changed, AchievementChange{ID: a.ID, Name: a.Name, Fields: fields})
+		}
+	}
+	for _, a := range stored {
+		if !inFile[a.ID] {
+			diff.Missing = append(diff.Missing, AchievementChange{ID: a.ID, Name: a.Name})
+		}
+	}
+
+	for _, changes := range [][]AchievementChange{diff.Added, diff.Changed, diff.Missing} {
+		slices.SortFunc(changes, func(a, b AchievementChange) int { return a.ID - b.ID })
+	}
+	return diff
+}
+
+// checkAchievementIDs refuses a file that would move a name between ids. Rows are matched
+// by name and special achievements find their evaluator by name, so a renamed id or a
+// name reused under a new id would update the wrong row.
+func checkAchievementIDs(file, stored []*Achievement, diff AchievementDiff) error {
+	for _, c := range diff.Changed {
+		if slices.Contains(c.Fields, "name") {
+			return fmt.Errorf("achievement %d can't be renamed to %q; add it under a new id instead", c.ID, c.Name)
+		}
+	}
+	storedIDs := make(map[string]int, len(stored))
+	for _, a := range stored {
+		storedIDs[a.Name] = a.ID
+	}
+	for _, a := range file {
+		if id, ok := storedIDs[a.Name]; ok && id != a.ID {
+			return fmt.Errorf("achievement %q is stored as %d and can't move to %d", a.Name, id, a.ID)
+		}
+	}
+	return nil
+}
+
+// ReloadAchievements re-reads the achievements file, compares it with the achievements
+// table and writes it there. The new definitions only go live once that write has
+// committed, so an invalid file or a failed write leaves everything as it was.
+func (am *AchievementManager) ReloadAchievements() (AchievementDiff, error) {
+	achievementDefs.RLock()
+	path := achievementDefs.path
+	achievementDefs.RUnlock()
+
+	list, err := readAchievementDefinitions(path)
+	if err != nil {
+		return AchievementDiff{}, err
+	}
+	stored, err := am.storedAchievements()
+	if err != nil {
+		return AchievementDiff{}, err
+	}
+	diff := diffAchievements(list, stored)
+	if err := checkAchievementIDs(list, stored, diff); err != nil {
+		return AchievementDiff{}, err
+	}
+
+	if err := am.applyAchievements(cloneAchievements(list)); err != nil {
+		return AchievementDiff{}, err
+	}
+	achievementDefs.Lock()
+	achievementDefs.list = list
+	achievementDefs.Unlock()
+	return diff, nil
+}
+
+// storedAchievements reads the achievements table, or the loaded set when offline
+func (am *AchievementManager) storedAchievements() ([]*Achievement, error) {
+	if DB == nil {
+		return am.GetAllAchievements(), nil
+	}
+	return queryAchievements()
+}
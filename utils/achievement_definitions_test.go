@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedAchievementsAreValid(t *testing.T) {
+	list, err := parseAchievementDefinitions(embeddedAchievements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range list {
+		// Catches descriptions that drift from their thresholds when values are rebalanced
+		if a.RequirementType == string(RequirementSpecial) || a.RequirementValue == 1 {
+			continue
+		}
+		if !strings.Contains(a.Description, FormatNumber(a.RequirementValue)) {
+			t.Errorf("achievement %d (%s): description %q does not mention %s", a.ID, a.Name, a.Description, FormatNumber(a.RequirementValue))
+		}
+	}
+}
+
+func TestParseAchievementDefinitionsReportsEveryProblem(t *testing.T) {
+	_, err := parseAchievementDefinitions([]byte(`[
+		{"id": 1, "name": "Winner", "description": "Win 5 games", "icon": "🏆", "category": "Wins", "requirement_type": "wins", "requirement_value": 5},
+		{"id": 1, "name": "Copy", "description": "Win 10 games", "icon": "🏆", "category": "Wins", "requirement_type": "wins", "requirement_value": 10},
+		{"id": 2, "name": "Lucky", "description": "Be lucky", "icon": "🍀", "category": "Special", "requirement_type": "luck", "requirement_value": 1}
+	]`))
+	if err == nil {
+		t.Fatal("invalid definitions were accepted")
+	}
+	for _, want := range []string{"achievement 1 (Copy): duplicate id", `unknown requirement type "luck"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %s: %v", want, err)
+		}
+	}
+
+	if _, err := parseAchievementDefinitions([]byte(`[{"id": 1, "nmae": "Typo"}]`)); err == nil {
+		t.Error("unknown field was accepted")
+	}
+}
+
+func TestDiffAchievements(t *testing.T) {
+	stored := []*Achievement{
+		{ID: 1, Name: "First Win", RequirementValue: 1, ChipsReward: 100},
+		{ID: 2, Name: "Veteran", RequirementValue: 100},
+		{ID: 3, Name: "Retired", RequirementValue: 1},
+	}
+	file := []*Achievement{
+		{ID: 4, Name: "Newcomer", RequirementValue: 1},
+		{ID: 2, Name: "Veteran", RequirementValue: 150, Hidden: true},
+		{ID: 1, Name: "First Win", RequirementValue: 1, ChipsReward: 100},
+	}
+
+	diff := diffAchievements(file, stored)
+	if len(diff.Added) != 1 || diff.Added[0].ID != 4 {
+		t.Errorf("added = %+v", diff.Added)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].ID != 2 || strings.Join(diff.Changed[0].Fields, ",") != "requirement_value,hidden" {
+		t.Errorf("changed = %+v", diff.Changed)
+	}
+	if len(diff.Missing) != 1 || diff.Missing[0].ID != 3 {
+		t.Errorf("missing = %+v", diff.Missing)
+	}
+	if !diffAchievements(stored, stored).Empty() {
+		t.Error("identical sets produced a diff")
+	}
+}
+
+func TestCheckAchievementIDsRefusesMovedNames(t *testing.T) {
+	stored := []*Achievement{
+		{ID: 1, Name: "First Win"},
+		{ID: 2, Name: "Veteran"},
+	}
+
+	// Veteran is retired from id 2 and its name reused for a new id 3
+	moved := []*Achievement{{ID: 1, Name: "First Win"}, {ID: 3, Name: "Veteran"}}
+	err := checkAchievementIDs(moved, stored, diffAchievements(moved, stored))
+	if err == nil || !strings.Contains(err.Error(), `"Veteran" is stored as 2`) {
+		t.Errorf("moved name: err = %v", err)
+	}
+
+	renamed := []*Achievement{{ID: 1, Name: "First Victory"}, {ID: 2, Name: "Veteran"}}
+	if err := checkAchievementIDs(renamed, stored, diffAchievements(renamed, stored)); err == nil {
+		t.Error("renamed id was accepted")
+	}
+
+	added := append(slices.Clone(stored), &Achievement{ID: 3, Name: "Newcomer"})
+	if err := checkAchievementIDs(added, stored, diffAchievements(added, stored)); err != nil {
+		t.Errorf("new achievement: err = %v", err)
+	}
+}
@@ -0,0 +1,399 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jackc/pgx/v5"
+)
+
+// AchievementEventType identifies what happened for event-driven achievements
+type AchievementEventType string
+
+const (
+	// EventGameFinished fires when a stake is settled or forfeited
+	EventGameFinished AchievementEventType = "game_finished"
+	// EventCommandUsed fires after a slash command has been handled
+	EventCommandUsed AchievementEventType = "command_used"
+	// EventJackpotWon fires when a player hits a jackpot
+	EventJackpotWon AchievementEventType = "jackpot_won"
+	// EventWentBroke fires when a game leaves the player with no chips
+	EventWentBroke AchievementEventType = "went_broke"
+	// EventStreakUpdated fires when a bonus claim extends, breaks or restarts a streak
+	EventStreakUpdated AchievementEventType = "streak_updated"
+	// EventChipsGiven fires when a player sends chips to another player
+	EventChipsGiven AchievementEventType = "chips_given"
+)
+
+// AchievementEvent is something a player did that special achievements may react to
+type AchievementEvent struct {
+	Type          AchievementEventType
+	UserID        int64
+	GameType      string
+	Command       string
+	Bet           int64
+	Profit        int64
+	BalanceBefore int64 // balance before the stake was taken
+	BalanceAfter  int64
+	User          *User // user row after the event, if known
+	StreakType    StreakType
+	Streak        int
+	BrokenFrom    int // length of the streak the claim broke, if it broke one
+	TimesBroken   int
+	At            time.Time
+}
+
+// AchievementCounters are the persistent per-user counters evaluators keep between events
+type AchievementCounters interface {
+	Get(counter string) (int64, error)
+	Add(counter string, delta int64) (int64, error)
+	Set(counter string, value int64) error
+}
+
+// AchievementEvaluator decides whether an event unlocks a RequirementSpecial achievement
+type AchievementEvaluator func(ev *AchievementEvent, a *Achievement, counters AchievementCounters) (bool, error)
+
+type achievementEvaluator struct {
+	events   []AchievementEventType
+	evaluate AchievementEvaluator
+}
+
+// achievementEvaluators maps special achievement names to the evaluator that unlocks them
+var achievementEvaluators = make(map[string]achievementEvaluator)
+
+// RegisterAchievementEvaluator wires a RequirementSpecial achievement, by name, to the
+// events that can unlock it
+func RegisterAchievementEvaluator(name string, evaluate AchievementEvaluator, events ...AchievementEventType) {
+	achievementEvaluators[name] = achievementEvaluator{events: events, evaluate: evaluate}
+}
+
+func (e achievementEvaluator) handles(t AchievementEventType) bool {
+	for _, et := range e.events {
+		if et == t {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// marathonGap is the longest pause between games that still counts as one session
+	marathonGap = 30 * time.Minute
+	// marathonLength is how long a session must last for Marathon Session
+	marathonLength = 6 * time.Hour
+	// miracleBalance is the balance below which a win counts as a miracle
+	miracleBalance = 100
+)
+
+func init() {
+	profitAtLeast := func(ev *AchievementEvent, a *Achievement, _ AchievementCounters) (bool, error) {
+		return ev.Profit >= a.RequirementValue, nil
+	}
+	RegisterAchievementEvaluator("Big Winner", profitAtLeast, EventGameFinished)
+	RegisterAchievementEvaluator("Whale", profitAtLeast, EventGameFinished)
+
+	RegisterAchievementEvaluator("Lucky 7s", func(ev *AchievementEvent, a *Achievement, _ AchievementCounters) (bool, error) {
+		return ev.Profit == a.RequirementValue, nil
+	}, EventGameFinished)
+
+	RegisterAchievementEvaluator("All In", func(ev *AchievementEvent, _ *Achievement, _ AchievementCounters) (bool, error) {
+		return ev.BalanceBefore > 0 && ev.Bet >= ev.BalanceBefore && ev.Profit > 0, nil
+	}, EventGameFinished)
+
+	RegisterAchievementEvaluator("Miracle Worker", func(ev *AchievementEvent, _ *Achievement, _ AchievementCounters) (bool, error) {
+		return ev.BalanceBefore < miracleBalance && ev.Profit > 0, nil
+	}, EventGameFinished)
+
+	RegisterAchievementEvaluator("Double or Nothing", func(ev *AchievementEvent, _ *Achievement, _ AchievementCounters) (bool, error) {
+		return ev.BalanceBefore > 0 && ev.BalanceAfter >= 2*ev.BalanceBefore, nil
+	}, EventGameFinished)
+
+	// Time-of-day achievements use UTC; we do not know players' time zones. The windows
+	// don't overlap, so one game can't unlock both.
+	RegisterAchievementEvaluator("Night Owl", func(ev *AchievementEvent, _ *Achievement, _ AchievementCounters) (bool, error) {
+		return ev.At.UTC().Hour() < 4, nil
+	}, EventGameFinished)
+	RegisterAchievementEvaluator("Early Riser", func(ev *AchievementEvent, _ *Achievement, _ AchievementCounters) (bool, error) {
+		hour := ev.At.UTC().Hour()
+		return hour >= 4 && hour < 6, nil
+	}, EventGameFinished)
+
+	RegisterAchievementEvaluator("Early Bird", func(ev *AchievementEvent, _ *Achievement, _ AchievementCounters) (bool, error) {
+		return ev.User != nil && !ev.User.CreatedAt.IsZero() && ev.At.Sub(ev.User.CreatedAt) <= time.Hour, nil
+	}, EventGameFinished)
+
+	RegisterAchievementEvaluator("House Always Wins", func(ev *AchievementEvent, a *Achievement, _ AchievementCounters) (bool, error) {
+		return ev.User != nil && int64(ev.User.Losses) >= a.RequirementValue, nil
+	}, EventGameFinished)
+
+	RegisterAchievementEvaluator("Marathon Session", evaluateMarathon, EventGameFinished)
+	RegisterAchievementEvaluator("Degen Gambler", evaluateLossStreak, EventGameFinished)
+
+	RegisterAchievementEvaluator("Bankruptcy Expert", func(_ *AchievementEvent, a *Achievement, counters AchievementCounters) (bool, error) {
+		n, err := counters.Add("times_broke", 1)
+		return n >= a.RequirementValue, err
+	}, EventWentBroke)
+
+	RegisterAchievementEvaluator("Jackpot Hunter", func(_ *AchievementEvent, _ *Achievement, _ AchievementCounters) (bool, error) {
+		return true, nil
+	}, EventJackpotWon)
+
+	RegisterAchievementEvaluator("Curious Cat", func(ev *AchievementEvent, _ *Achievement, _ AchievementCounters) (bool, error) {
+		return ev.Command == "balance" || ev.Command == "chips", nil
+	}, EventCommandUsed)
+
+	RegisterAchievementEvaluator("Show Off", func(ev *AchievementEvent, a *Achievement, counters AchievementCounters) (bool, error) {
+		if ev.Command != "profile" {
+			return false, nil
+		}
+		n, err := counters.Add("profile_uses", 1)
+		return n >= a.RequirementValue, err
+	}, EventCommandUsed)
+
+	dailyStreakAtLeast := func(ev *AchievementEvent, a *Achievement, _ AchievementCounters) (bool, error) {
+		return ev.StreakType == StreakDaily && int64(ev.Streak) >= a.RequirementValue, nil
+	}
+	RegisterAchievementEvaluator("On a Roll", dailyStreakAtLeast, EventStreakUpdated)
+	RegisterAchievementEvaluator("Creature of Habit", dailyStreakAtLeast, EventStreakUpdated)
+	RegisterAchievementEvaluator("Unbreakable", dailyStreakAtLeast, EventStreakUpdated)
+	RegisterAchievementEvaluator("Comeback Kid", func(ev *AchievementEvent, a *Achievement, _ AchievementCounters) (bool, error) {
+		return ev.StreakType == StreakDaily && ev.TimesBroken > 0 && int64(ev.Streak) >= a.RequirementValue, nil
+	}, EventStreakUpdated)
+
+	// Only a sizeable gift counts, so the reward can't be farmed with minimum transfers
+	RegisterAchievementEvaluator("Helping Hand", func(ev *AchievementEvent, a *Achievement, _ AchievementCounters) (bool, error) {
+		return ev.Bet >= a.RequirementValue, nil
+	}, EventChipsGiven)
+
+	RegisterAchievementEvaluator("Blackjack Master", gameStatsEvaluator("blackjack", func(gs *GameStats) int64 { return gs.Wins }), EventGameFinished)
+	RegisterAchievementEvaluator("Slot Machine Addict", gameStatsEvaluator("slots", func(gs *GameStats) int64 { return gs.GamesPlayed }), EventGameFinished)
+	RegisterAchievementEvaluator("Roulette Roller", gameStatsEvaluator("roulette", func(gs *GameStats) int64 { return gs.GamesPlayed }), EventGameFinished)
+}
+
+// evaluateMarathon tracks how long the player has been playing without a long break
+func evaluateMarathon(ev *AchievementEvent, _ *Achievement, counters AchievementCounters) (bool, error) {
+	now := ev.At.Unix()
+	last, err := counters.Get("session_last_game")
+	if err != nil {
+		return false, err
+	}
+	start, err := counters.Get("session_started")
+	if err != nil {
+		return false, err
+	}
+
+	if last == 0 || now-last > int64(marathonGap/time.Second) {
+		start = now
+		if err := counters.Set("session_started", start); err != nil {
+			return false, err
+		}
+	}
+	if err := counters.Set("session_last_game", now); err != nil {
+		return false, err
+	}
+	return now-start >= int64(marathonLength/time.Second), nil
+}
+
+// evaluateLossStreak counts consecutive losses across every game; pushes keep the streak
+func evaluateLossStreak(ev *AchievementEvent, a *Achievement, counters AchievementCounters) (bool, error) {
+	switch {
+	case ev.Profit < 0:
+		n, err := counters.Add("loss_streak", 1)
+		return n >= a.RequirementValue, err
+	case ev.Profit > 0:
+		return false, counters.Set("loss_streak", 0)
+	}
+	return false, nil
+}
+
+// gameStatsEvaluator unlocks once a per-game stat reaches the requirement
+func gameStatsEvaluator(gameType string, stat func(*GameStats) int64) AchievementEvaluator {
+	return func(ev *AchievementEvent, a *Achievement, _ AchievementCounters) (bool, error) {
+		if ev.GameType != gameType {
+			return false, nil
+		}
+		gs, err := GetGameStats(ev.UserID, gameType)
+		if err != nil {
+			return false, err
+		}
+		return stat(gs) >= a.RequirementValue, nil
+	}
+}
+
+// dbAchievementCounters stores a user's counters in achievement_counters
+type dbAchievementCounters struct {
+	userID int64
+}
+
+func (c dbAchievementCounters) Get(counter string) (int64, error) {
+	var value int64
+	ctx := context.Background()
+	err := DB.QueryRow(ctx, `SELECT value FROM achievement_counters WHERE user_id = $1 AND counter = $2`, c.userID, counter).Scan(&value)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get achievement counter: %w", err)
+	}
+	return value, nil
+}
+
+func (c dbAchievementCounters) Add(counter string, delta int64) (int64, error) {
+	var value int64
+	ctx := context.Background()
+	err := DB.QueryRow(ctx, `
+		INSERT INTO achievement_counters (user_id, counter, value) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, counter) DO UPDATE
+		SET value = achievement_counters.value + EXCLUDED.value, updated_at = NOW()
+		RETURNING value`,
+		c.userID, counter, delta).Scan(&value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update achievement counter: %w", err)
+	}
+	return value, nil
+}
+
+func (c dbAchievementCounters) Set(counter string, value int64) error {
+	ctx := context.Background()
+	_, err := DB.Exec(ctx, `
+		INSERT INTO achievement_counters (user_id, counter, value) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, counter) DO UPDATE SET value = EXCLUDED.value, updated_at = NOW()`,
+		c.userID, counter, value)
+	if err != nil {
+		return fmt.Errorf("failed to set achievement counter: %w", err)
+	}
+	return nil
+}
+
+// EmitAchievementEvent evaluates special achievements for ev in the background. When
+// session and interaction are set, unlocks are announced as an ephemeral followup.
+func EmitAchievementEvent(session *discordgo.Session, interaction *discordgo.InteractionCreate, ev AchievementEvent) {
+	if AchievementMgr == nil || DB == nil {
+		return
+	}
+	if ev.At.IsZero() {
+		ev.At = time.Now()
+	}
+
+	go func() {
+		unlocked, err := AchievementMgr.ProcessEvent(&ev, dbAchievementCounters{userID: ev.UserID})
+		if err != nil {
+			log.Printf("⚠️ Failed to process %s achievement event for user %d: %v", ev.Type, ev.UserID, err)
+		}
+		if len(unlocked) > 0 && session != nil && interaction != nil {
+			SendAchievementNotification(session, interaction, unlocked)
+		}
+	}()
+}
+
+// emitGameFinished reports a settled or forfeited stake, plus going broke when the game
+// emptied the player's balance
+func emitGameFinished(r *BetReservation, wagered, payout int64, user *User, session *discordgo.Session, interaction *discordgo.InteractionCreate) {
+	ev := AchievementEvent{
+		Type:     EventGameFinished,
+		UserID:   r.UserID,
+		GameType: r.GameType,
+		Bet:      wagered,
+		Profit:   payout - wagered,
+		User:     user,
+		At:       time.Now(),
+	}
+	if user != nil {
+		ev.BalanceAfter = user.Chips
+		ev.BalanceBefore = user.Chips - ev.Profit
+	}
+	EmitAchievementEvent(session, interaction, ev)
+
+	if user != nil && ev.BalanceBefore > 0 && ev.BalanceAfter <= 0 {
+		broke := ev
+		broke.Type = EventWentBroke
+		EmitAchievementEvent(nil, nil, broke)
+	}
+}
+
+// ProcessEvent runs the evaluators listening for ev against the user's unearned special
+// achievements, awarding and rewarding any that unlock
+func (am *AchievementManager) ProcessEvent(ev *AchievementEvent, counters AchievementCounters) ([]*Achievement, error) {
+	var candidates []*Achievement
+	var evaluators []achievementEvaluator
+	am.mutex.RLock()
+	for _, a := range am.achievements {
+		if RequirementType(a.RequirementType) != RequirementSpecial {
+			continue
+		}
+		if e, ok := achievementEvaluators[a.Name]; ok && e.handles(ev.Type) {
+			candidates = append(candidates, a)
+			evaluators = append(evaluators, e)
+		}
+	}
+	am.mutex.RUnlock()
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	earned, err := am.GetUserAchievements(ev.UserID)
+	if err != nil {
+		return nil, err
+	}
+	earnedMap := make(map[int]bool, len(earned))
+	for _, ua := range earned {
+		earnedMap[ua.AchievementID] = true
+	}
+
+	var unlocked []*Achievement
+	for idx, a := range candidates {
+		if earnedMap[a.ID] {
+			continue
+		}
+		ok, err := evaluators[idx].evaluate(ev, a, counters)
+		if err != nil {
+			log.Printf("⚠️ Achievement %q evaluation failed for user %d: %v", a.Name, ev.UserID, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		awarded, err := am.awardNew(ev.UserID, a)
+		if err != nil {
+			log.Printf("⚠️ Failed to award achievement %q to user %d: %v", a.Name, ev.UserID, err)
+		}
+		if awarded {
+			unlocked = append(unlocked, a)
+		}
+	}
+
+	return unlocked, nil
+}
+
+// awardNew records an achievement and grants its rewards, reporting false if the user
+// already had it
+func (am *AchievementManager) awardNew(userID int64, a *Achievement) (bool, error) {
+	ctx := context.Background()
+	tag, err := DB.Exec(ctx, `
+		INSERT INTO user_achievements (user_id, achievement_id, earned_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, achievement_id) DO NOTHING`,
+		userID, a.ID, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to award achievement: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if a.ChipsReward > 0 || a.XPReward > 0 {
+		if _, err := UpdateCachedUser(userID, UserUpdateData{
+			ChipsIncrement:   a.ChipsReward,
+			TotalXPIncrement: a.XPReward,
+			Reason:           TxReasonAchievement,
+			Note:             a.Name,
+		}); err != nil {
+			return true, fmt.Errorf("failed to apply achievement reward: %w", err)
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+type memCounters map[string]int64
+
+func (m memCounters) Get(counter string) (int64, error) { return m[counter], nil }
+func (m memCounters) Add(counter string, delta int64) (int64, error) {
+	m[counter] += delta
+	return m[counter], nil
+}
+func (m memCounters) Set(counter string, value int64) error {
+	m[counter] = value
+	return nil
+}
+
+func TestLossStreakEvaluatorResetsOnWin(t *testing.T) {
+	a := &Achievement{Name: "Degen Gambler", RequirementValue: 3}
+	counters := memCounters{}
+	play := func(profit int64) bool {
+		ok, err := evaluateLossStreak(&AchievementEvent{Type: EventGameFinished, Profit: profit}, a, counters)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	play(-10)
+	play(-10)
+	play(50)
+	if play(-10) || play(0) || play(-10) {
+		t.Fatal("unlocked before three losses in a row")
+	}
+	if !play(-10) {
+		t.Fatalf("three losses in a row did not unlock (streak %d)", counters["loss_streak"])
+	}
+}
+
+func TestMarathonEvaluatorRestartsAfterBreak(t *testing.T) {
+	a := &Achievement{Name: "Marathon Session"}
+	counters := memCounters{}
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	play := func(at time.Time) bool {
+		ok, err := evaluateMarathon(&AchievementEvent{Type: EventGameFinished, At: at}, a, counters)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	at := start
+	for ; at.Sub(start) < marathonLength; at = at.Add(20 * time.Minute) {
+		if play(at) {
+			t.Fatalf("unlocked after %v", at.Sub(start))
+		}
+	}
+	if !play(at) {
+		t.Fatal("six hours of play did not unlock")
+	}
+
+	counters = memCounters{}
+	play(start)
+	if play(start.Add(marathonLength)) {
+		t.Fatal("a break longer than the session gap still counted")
+	}
+}
+
+func TestHelpingHandNeedsASizeableGift(t *testing.T) {
+	a := &Achievement{Name: "Helping Hand", RequirementValue: 50000}
+	evaluate := achievementEvaluators["Helping Hand"].evaluate
+	for bet, want := range map[int64]bool{100: false, 49999: false, 50000: true} {
+		got, err := evaluate(&AchievementEvent{Type: EventChipsGiven, Bet: bet}, a, memCounters{})
+		if err != nil || got != want {
+			t.Errorf("gift of %d: unlocked = %v, %v; want %v", bet, got, err, want)
+		}
+	}
+}
+
+func TestTimeOfDayWindowsDoNotOverlap(t *testing.T) {
+	owl := achievementEvaluators["Night Owl"].evaluate
+	riser := achievementEvaluators["Early Riser"].evaluate
+	for hour := 0; hour < 24; hour++ {
+		ev := &AchievementEvent{Type: EventGameFinished, At: time.Date(2025, 1, 1, hour, 30, 0, 0, time.UTC)}
+		gotOwl, _ := owl(ev, &Achievement{Name: "Night Owl"}, memCounters{})
+		gotRiser, _ := riser(ev, &Achievement{Name: "Early Riser"}, memCounters{})
+		if wantOwl, wantRiser := hour < 4, hour >= 4 && hour < 6; gotOwl != wantOwl || gotRiser != wantRiser {
+			t.Errorf("%02d:30 UTC: night owl %v, early riser %v; want %v, %v", hour, gotOwl, gotRiser, wantOwl, wantRiser)
+		}
+	}
+}
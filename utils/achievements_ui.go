@@ -196,23 +196,11 @@ func CreateAchievementCategoryEmbed(category AchievementCategory, achievements [
 func CreateAchievementOverviewEmbed(categorized map[AchievementCategory][]*AchievementDisplayData, userID int64) *discordgo.MessageEmbed {
 	embed := CreateBrandedEmbed("🏆 Achievements Overview", "Select a category to view detailed achievements", BotColor)
 
-	// Define category order
-	categoryOrder := []AchievementCategory{
-		CategoryFirstSteps,
-		CategoryWins,
-		CategoryWealth,
-		CategoryExperience,
-		CategoryPrestige,
-		CategoryGaming,
-		CategoryLoyalty,
-		CategorySpecial,
-	}
-
 	// Calculate total achievements and completed
 	totalAchievements := 0
 	totalCompleted := 0
 
-	for _, category := range categoryOrder {
+	for _, category := range AchievementCategories {
 		achievements, exists := categorized[category]
 		if !exists || len(achievements) == 0 {
 			continue
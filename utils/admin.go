@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Admin actions recorded in admin_audit_log
+const (
+	AdminActionAddChips          = "add_chips"
+	AdminActionRemoveChips       = "remove_chips"
+	AdminActionSetChips          = "set_chips"
+	AdminActionResetUser         = "reset_user"
+	AdminActionRevokeAchievement = "revoke_achievement"
+	AdminActionResetCooldowns    = "reset_cooldowns"
+	AdminActionBan               = "ban"
+	AdminActionCooldown          = "cooldown"
+	AdminActionUnban             = "unban"
+
+	AdminActionReloadAchievements = "reload_achievements" // not tied to a user; target_id is 0
+)
+
+// AdminAuditEntry is one recorded moderator action
+type AdminAuditEntry struct {
+	ID        int64
+	ActorID   int64
+	Action    string
+	TargetID  int64
+	Reason    string
+	Details   JSONB
+	CreatedAt time.Time
+}
+
+// RecordAdminAction appends an entry to the admin audit log
+func RecordAdminAction(actorID int64, action string, targetID int64, reason string, details JSONB) error {
+	if DB == nil {
+		return fmt.Errorf("database not connected")
+	}
+	if details == nil {
+		details = JSONB{}
+	}
+
+	ctx := context.Background()
+	_, err := DB.Exec(ctx, `
+		INSERT INTO admin_audit_log (actor_id, action, target_id, reason, details)
+		VALUES ($1, $2, $3, $4, $5)`,
+		actorID, action, targetID, reason, details)
+	if err != nil {
+		return fmt.Errorf("failed to record admin action: %w", err)
+	}
+	return nil
+}
+
+// GetAdminAuditLog returns the most recent admin actions taken against a user
+func GetAdminAuditLog(targetID int64, limit int) ([]AdminAuditEntry, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx := context.Background()
+	rows, err := DB.Query(ctx, `
+		SELECT id, actor_id, action, target_id, reason, details, created_at
+		FROM admin_audit_log WHERE target_id = $1
+		ORDER BY created_at DESC LIMIT $2`, targetID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []AdminAuditEntry
+	for rows.Next() {
+		var e AdminAuditEntry
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.TargetID, &e.Reason, &e.Details, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan admin audit entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ResetUserProgress returns a user to a fresh account: starting chips, no XP, prestige,
+// record, cooldowns, achievements, game stats or streaks. Returns ErrStakesHeld while
+// any game still holds a stake.
+func ResetUserProgress(userID int64, note string) (*User, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx := context.Background()
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	chips, zero, none, prestige := Economy.StartingChips, int64(0), 0, 0
+	updates := UserUpdateData{
+		SetChips:       &chips,
+		SetTotalXP:     &zero,
+		SetCurrentXP:   &zero,
+		SetWins:        &none,
+		SetLosses:      &none,
+		Prestige:       &prestige,
+		ResetCooldowns: true,
+		Reason:         TxReasonAdjustment,
+		Note:           note,
+	}
+	if err := resolveSetFields(ctx, tx, userID, &updates); err != nil {
+		return nil, err
+	}
+	if err := checkNoHeldStakes(ctx, tx, userID); err != nil {
+		return nil, err
+	}
+	user, err := applyUserUpdateInTx(ctx, tx, userID, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, table := range []string{"user_achievements", "user_game_stats", "user_streaks", "streak_freezes", "achievement_counters"} {
+		if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE user_id = $1`, userID); err != nil {
+			PutUserToPool(user)
+			return nil, fmt.Errorf("failed to reset %s: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		PutUserToPool(user)
+		return nil, fmt.Errorf("failed to commit user reset: %w", err)
+	}
+
+	ResetPrestigeReady(userID)
+	if Cache != nil {
+		Cache.Update(userID, user)
+	}
+	return user, nil
+}
+
+// RevokeAchievement removes an earned achievement; its reward is not clawed back
+func RevokeAchievement(userID int64, achievementID int) (bool, error) {
+	if DB == nil {
+		return false, fmt.Errorf("database not connected")
+	}
+
+	ctx := context.Background()
+	tag, err := DB.Exec(ctx, `DELETE FROM user_achievements WHERE user_id = $1 AND achievement_id = $2`, userID, achievementID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke achievement: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
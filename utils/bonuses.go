@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -30,6 +31,7 @@ type BonusInfo struct {
 	NextAvailable time.Time     `json:"next_available"`
 	Multiplier    float64       `json:"multiplier"`
 	StreakBonus   int64         `json:"streak_bonus"`
+	Streak        *StreakUpdate `json:"streak,omitempty"`
 }
 
 // BonusResult represents the result of claiming a bonus
@@ -40,57 +42,76 @@ type BonusResult struct {
 	TimeRemaining time.Duration `json:"time_remaining"`
 }
 
-// Bonus configuration constants
-const (
-	// Base bonus amounts
-	BaseHourlyBonus = 25  // 25 chips per hour (matches Python)
-	BaseDailyBonus  = 150 // 150 chips per day (matches Python)
-	BaseWeeklyBonus = 600 // 600 chips per week (matches Python)
-	BaseVoteBonus   = 250 // 250 chips per vote (matches Python)
-	BaseServerBonus = 500 // 500 chips per server bonus
-
-	// Prestige bonus amounts (per prestige level) - reduced by ~30%
-	PrestigeBonusHourly = 35   // 35 chips per prestige level for hourly (was 50)
-	PrestigeBonusDaily  = 250  // 250 chips per prestige level for daily (was 350)
-	PrestigeBonusWeekly = 1100 // 1100 chips per prestige level for weekly (was 1500)
-	PrestigeBonusVote   = 450  // 450 chips per prestige level for vote (was 625)
-	PrestigeBonusServer = 900  // 900 chips per prestige level for server (was 1250)
-
-	// Level bonus amounts (per level) - reduced by ~30%
-	LevelBonusHourly = 10  // 10 chips per level for hourly (was 15)
-	LevelBonusDaily  = 50  // 50 chips per level for daily (was 75)
-	LevelBonusWeekly = 200 // 200 chips per level for weekly (was 300)
-	LevelBonusVote   = 85  // 85 chips per level for vote (was 125)
-	LevelBonusServer = 175 // 175 chips per level for server (was 250)
-
-	// XP rewards
-	HourlyXP = 50   // 50 XP per hourly
-	DailyXP  = 250  // 250 XP per daily
-	WeeklyXP = 1000 // 1000 XP per weekly
-	VoteXP   = 500  // 500 XP per vote
-	ServerXP = 750  // 750 XP per server bonus
-
-	// Cooldown periods
-	HourlyCooldown = time.Hour          // 1 hour
-	DailyCooldown  = 24 * time.Hour     // 24 hours
-	WeeklyCooldown = 7 * 24 * time.Hour // 7 days
-	VoteCooldown   = 12 * time.Hour     // 12 hours (top.gg voting cooldown)
-	ServerCooldown = 24 * time.Hour     // 24 hours (daily server bonus)
-
-	// Bonus multipliers - reduced for better balance
-	MaxRankMultiplier     = 1.3  // 30% bonus for max rank (was 50%)
-	PrestigeMultiplier    = 0.08 // 8% per prestige level (was 10%)
-	MaxPrestigeMultiplier = 1.75 // 175% max bonus from prestige (was 200%)
-)
+// BonusRule sets what one bonus type pays and how often it can be claimed
+type BonusRule struct {
+	Base        int64         `yaml:"base"`
+	PerPrestige int64         `yaml:"per_prestige"` // chips per prestige level
+	PerLevel    int64         `yaml:"per_level"`    // chips per level
+	XP          int64         `yaml:"xp"`
+	Cooldown    time.Duration `yaml:"cooldown"`
+}
 
-// Main support server configuration
-const MainSupportServerID = "1396567190102347776" // Discord server where server bonus can be claimed
+// BonusConfig sets every bonus type's rule and the multipliers applied on top
+type BonusConfig struct {
+	Hourly                BonusRule `yaml:"hourly"`
+	Daily                 BonusRule `yaml:"daily"`
+	Weekly                BonusRule `yaml:"weekly"`
+	Vote                  BonusRule `yaml:"vote"`   // top.gg voting
+	Server                BonusRule `yaml:"server"` // claimable in the home server only
+	MaxRankMultiplier     float64   `yaml:"max_rank_multiplier"`
+	PrestigeMultiplier    float64   `yaml:"prestige_multiplier"` // added per prestige level
+	MaxPrestigeMultiplier float64   `yaml:"max_prestige_multiplier"`
+}
+
+// DefaultBonusConfig returns the built-in bonus rules
+func DefaultBonusConfig() BonusConfig {
+	return BonusConfig{
+		Hourly: BonusRule{Base: 25, PerPrestige: 35, PerLevel: 10, XP: 50, Cooldown: time.Hour},
+		Daily:  BonusRule{Base: 150, PerPrestige: 250, PerLevel: 50, XP: 250, Cooldown: 24 * time.Hour},
+		Weekly: BonusRule{Base: 600, PerPrestige: 1100, PerLevel: 200, XP: 1000, Cooldown: 7 * 24 * time.Hour},
+		Vote:   BonusRule{Base: 250, PerPrestige: 450, PerLevel: 85, XP: 500, Cooldown: 12 * time.Hour},
+		Server: BonusRule{Base: 500, PerPrestige: 900, PerLevel: 175, XP: 750, Cooldown: 24 * time.Hour},
+
+		MaxRankMultiplier:     1.3,
+		PrestigeMultiplier:    0.08,
+		MaxPrestigeMultiplier: 1.75,
+	}
+}
+
+// Rule returns the rule for a bonus type
+func (c BonusConfig) Rule(bonusType BonusType) (BonusRule, bool) {
+	switch bonusType {
+	case BonusHourly:
+		return c.Hourly, true
+	case BonusDaily:
+		return c.Daily, true
+	case BonusWeekly:
+		return c.Weekly, true
+	case BonusVote:
+		return c.Vote, true
+	case BonusServer:
+		return c.Server, true
+	}
+	return BonusRule{}, false
+}
 
 // BonusManager handles all bonus-related operations
-type BonusManager struct{}
+type BonusManager struct {
+	cfg BonusConfig
+}
 
-// Global bonus manager
-var BonusMgr = &BonusManager{}
+// Global bonus manager; it pays the built-in rules until InitializeBonusManager runs
+var BonusMgr = &BonusManager{cfg: DefaultBonusConfig()}
+
+// InitializeBonusManager sets up the bonus system with the given rules
+func InitializeBonusManager(cfg BonusConfig) {
+	BonusMgr = &BonusManager{cfg: cfg}
+}
+
+// Rule returns the rule the manager applies to a bonus type
+func (bm *BonusManager) Rule(bonusType BonusType) (BonusRule, bool) {
+	return bm.cfg.Rule(bonusType)
+}
 
 // IsUserInMainSupportServer checks if a user is a member of the main support server
 func IsUserInMainSupportServer(session *discordgo.Session, userID string) bool {
@@ -99,32 +120,27 @@ func IsUserInMainSupportServer(session *discordgo.Session, userID string) bool {
 	}
 
 	// Check if user is in the main support server
-	_, err := session.GuildMember(MainSupportServerID, userID)
+	_, err := session.GuildMember(IDs.HomeGuildID, userID)
 	return err == nil
 }
 
 // CanClaimBonus checks if a user can claim a specific bonus type
 func (bm *BonusManager) CanClaimBonus(user *User, bonusType BonusType) *BonusResult {
 	var lastClaimed *time.Time
-	var cooldown time.Duration
-
 	switch bonusType {
 	case BonusHourly:
 		lastClaimed = user.LastHourly
-		cooldown = HourlyCooldown
 	case BonusDaily:
 		lastClaimed = user.LastDaily
-		cooldown = DailyCooldown
 	case BonusWeekly:
 		lastClaimed = user.LastWeekly
-		cooldown = WeeklyCooldown
 	case BonusVote:
 		lastClaimed = user.LastVote
-		cooldown = VoteCooldown
 	case BonusServer:
 		lastClaimed = user.LastBonus
-		cooldown = ServerCooldown
-	default:
+	}
+	rule, ok := bm.Rule(bonusType)
+	if !ok {
 		return &BonusResult{
 			Success: false,
 			Error:   "Invalid bonus type",
@@ -133,7 +149,7 @@ func (bm *BonusManager) CanClaimBonus(user *User, bonusType BonusType) *BonusRes
 
 	// Check if enough time has passed
 	if lastClaimed != nil {
-		nextAvailable := lastClaimed.Add(cooldown)
+		nextAvailable := lastClaimed.Add(rule.Cooldown)
 		if time.Now().Before(nextAvailable) {
 			return &BonusResult{
 				Success:       false,
@@ -153,6 +169,11 @@ func (bm *BonusManager) ClaimBonus(user *User, bonusType BonusType) (*BonusResul
 
 // ClaimBonusWithNotification processes a bonus claim for a user and sends achievement notifications if context is provided
 func (bm *BonusManager) ClaimBonusWithNotification(user *User, bonusType BonusType, session *discordgo.Session, interaction *discordgo.InteractionCreate) (*BonusResult, error) {
+	return bm.ClaimScaledBonus(user, bonusType, 1, session, interaction)
+}
+
+// ClaimScaledBonus processes a bonus claim with the chip amount scaled, e.g. doubled for weekend votes
+func (bm *BonusManager) ClaimScaledBonus(user *User, bonusType BonusType, scale float64, session *discordgo.Session, interaction *discordgo.InteractionCreate) (*BonusResult, error) {
 	// Check if bonus can be claimed
 	canClaim := bm.CanClaimBonus(user, bonusType)
 	if !canClaim.Success {
@@ -161,12 +182,18 @@ func (bm *BonusManager) ClaimBonusWithNotification(user *User, bonusType BonusTy
 
 	// Calculate bonus amounts
 	bonusInfo := bm.calculateBonusAmount(user, bonusType)
+	if scale > 1 {
+		bonusInfo.ActualAmount = int64(float64(bonusInfo.ActualAmount) * scale)
+		bonusInfo.Multiplier *= scale
+	}
 
 	// Update user data
 	now := time.Now()
 	updates := UserUpdateData{
 		ChipsIncrement:   bonusInfo.ActualAmount,
 		TotalXPIncrement: bonusInfo.XPAmount,
+		Reason:           TxReasonBonus,
+		Note:             string(bonusType),
 	}
 
 	// Update the appropriate timestamp
@@ -197,6 +224,8 @@ func (bm *BonusManager) ClaimBonusWithNotification(user *User, bonusType BonusTy
 	log.Printf("User %d claimed %s bonus: %d chips, %d XP",
 		user.UserID, bonusType, bonusInfo.ActualAmount, bonusInfo.XPAmount)
 
+	bonusInfo.Streak = recordBonusStreak(user.UserID, bonusType, now, session, interaction)
+
 	// Return success result
 	bonusInfo.NextAvailable = now.Add(bonusInfo.Cooldown)
 	return &BonusResult{
@@ -208,45 +237,14 @@ func (bm *BonusManager) ClaimBonusWithNotification(user *User, bonusType BonusTy
 // calculateBonusAmount calculates the actual bonus amount based on user stats
 // Hybrid system: Python base formula + Go multiplier system
 func (bm *BonusManager) calculateBonusAmount(user *User, bonusType BonusType) *BonusInfo {
-	var baseAmount, prestigeBonus, levelBonus, xpAmount int64
-	var cooldown time.Duration
-
 	// Get user level based on current XP and prestige
 	level := GetUserLevel(user.CurrentXP, user.Prestige)
 
 	// Get base amounts and bonuses per type (Python formula)
-	switch bonusType {
-	case BonusHourly:
-		baseAmount = BaseHourlyBonus
-		prestigeBonus = int64(user.Prestige) * PrestigeBonusHourly
-		levelBonus = int64(level) * LevelBonusHourly
-		xpAmount = HourlyXP
-		cooldown = HourlyCooldown
-	case BonusDaily:
-		baseAmount = BaseDailyBonus
-		prestigeBonus = int64(user.Prestige) * PrestigeBonusDaily
-		levelBonus = int64(level) * LevelBonusDaily
-		xpAmount = DailyXP
-		cooldown = DailyCooldown
-	case BonusWeekly:
-		baseAmount = BaseWeeklyBonus
-		prestigeBonus = int64(user.Prestige) * PrestigeBonusWeekly
-		levelBonus = int64(level) * LevelBonusWeekly
-		xpAmount = WeeklyXP
-		cooldown = WeeklyCooldown
-	case BonusVote:
-		baseAmount = BaseVoteBonus
-		prestigeBonus = int64(user.Prestige) * PrestigeBonusVote
-		levelBonus = int64(level) * LevelBonusVote
-		xpAmount = VoteXP
-		cooldown = VoteCooldown
-	case BonusServer:
-		baseAmount = BaseServerBonus
-		prestigeBonus = int64(user.Prestige) * PrestigeBonusServer
-		levelBonus = int64(level) * LevelBonusServer
-		xpAmount = ServerXP
-		cooldown = ServerCooldown
-	}
+	rule, _ := bm.Rule(bonusType)
+	baseAmount := rule.Base
+	prestigeBonus := int64(user.Prestige) * rule.PerPrestige
+	levelBonus := int64(level) * rule.PerLevel
 
 	// Calculate total amount from Python formula: base + prestige_amount + level_amount
 	pythonAmount := baseAmount + prestigeBonus + levelBonus
@@ -257,22 +255,25 @@ func (bm *BonusManager) calculateBonusAmount(user *User, bonusType BonusType) *B
 	// Rank-based multiplier
 	_, _, _, nextRankXP := GetRank(user.TotalXP)
 	if nextRankXP == user.TotalXP { // Max rank reached
-		multiplier += MaxRankMultiplier - 1.0 // Add 50% bonus
+		multiplier += bm.cfg.MaxRankMultiplier - 1.0
 	} else {
 		// Scale bonus based on rank progress
 		rankProgress := float64(user.TotalXP) / float64(nextRankXP)
-		multiplier += rankProgress * (MaxRankMultiplier - 1.0)
+		multiplier += rankProgress * (bm.cfg.MaxRankMultiplier - 1.0)
 	}
 
 	// Prestige-based multiplier (additional to Python prestige bonus)
 	if user.Prestige > 0 {
-		prestigeMultiplierBonus := float64(user.Prestige) * PrestigeMultiplier
-		if prestigeMultiplierBonus > MaxPrestigeMultiplier-1.0 {
-			prestigeMultiplierBonus = MaxPrestigeMultiplier - 1.0
+		prestigeMultiplierBonus := float64(user.Prestige) * bm.cfg.PrestigeMultiplier
+		if prestigeMultiplierBonus > bm.cfg.MaxPrestigeMultiplier-1.0 {
+			prestigeMultiplierBonus = bm.cfg.MaxPrestigeMultiplier - 1.0
 		}
 		multiplier += prestigeMultiplierBonus
 	}
 
+	// Permanent bonus earned along the prestige track
+	multiplier += PrestigeBonusMultiplier(user.Prestige)
+
 	// Apply multiplier to the Python formula result
 	actualAmount := int64(float64(pythonAmount) * multiplier)
 
@@ -287,8 +288,8 @@ func (bm *BonusManager) calculateBonusAmount(user *User, bonusType BonusType) *B
 		Type:         bonusType,
 		BaseAmount:   baseAmount,
 		ActualAmount: actualAmount,
-		XPAmount:     xpAmount,
-		Cooldown:     cooldown,
+		XPAmount:     rule.XP,
+		Cooldown:     rule.Cooldown,
 		Multiplier:   multiplier,
 		StreakBonus:  streakBonus,
 	}
@@ -296,13 +297,29 @@ func (bm *BonusManager) calculateBonusAmount(user *User, bonusType BonusType) *B
 
 // calculateDailyStreakBonus calculates bonus for consecutive daily claims
 func (bm *BonusManager) calculateDailyStreakBonus(user *User) int64 {
-	// This is a simplified streak calculation
-	// In a full implementation, you'd track daily streak in the database
-	dailyCount := user.DailyBonusesClaimed
+	streaks, freezes, err := GetStreaks(user.UserID)
+	if err != nil {
+		return 0
+	}
+	daily := streaks[StreakDaily]
+	if !daily.Alive(time.Now(), freezes) {
+		return 0
+	}
+
+	// Every full week of the streak this claim extends gives a streak bonus
+	streakWeeks := (daily.Current + 1) / 7
+	return int64(streakWeeks) * 100 // 100 chips per week of daily streak
+}
 
-	// Every 7 days gives a streak bonus
-	streakWeeks := dailyCount / 7
-	return int64(streakWeeks) * 100 // 100 chips per week of daily bonuses
+// recordBonusStreak extends the streak for a claimed bonus; failures are logged rather
+// than undoing a claim that already paid out
+func recordBonusStreak(userID int64, bonusType BonusType, now time.Time, session *discordgo.Session, interaction *discordgo.InteractionCreate) *StreakUpdate {
+	update, err := RecordStreakClaim(userID, bonusType, now, session, interaction)
+	if err != nil {
+		log.Printf("⚠️ Failed to record %s streak for user %d: %v", bonusType, userID, err)
+		return nil
+	}
+	return update
 }
 
 // GetAllCooldowns returns cooldown information for all bonus types
@@ -385,6 +402,8 @@ func (bm *BonusManager) ClaimAllAvailableBonusesWithGuildCheck(user *User, sessi
 	// Apply all updates at once
 	updates.ChipsIncrement = totalChips
 	updates.TotalXPIncrement = totalXP
+	updates.Reason = TxReasonBonus
+	updates.Note = claimedBonusTypes(claimedBonuses)
 
 	_, err := UpdateCachedUser(user.UserID, updates)
 	if err != nil {
@@ -394,9 +413,24 @@ func (bm *BonusManager) ClaimAllAvailableBonusesWithGuildCheck(user *User, sessi
 	log.Printf("User %d claimed %d bonuses: %d chips, %d XP total",
 		user.UserID, len(claimedBonuses), totalChips, totalXP)
 
+	for _, claimed := range claimedBonuses {
+		claimed.BonusInfo.Streak = recordBonusStreak(user.UserID, claimed.BonusInfo.Type, now, session, nil)
+	}
+
 	return claimedBonuses, nil
 }
 
+// claimedBonusTypes lists the bonus types in a claim-all batch for the chip ledger note
+func claimedBonusTypes(results []*BonusResult) string {
+	types := make([]string, 0, len(results))
+	for _, r := range results {
+		if r.BonusInfo != nil {
+			types = append(types, string(r.BonusInfo.Type))
+		}
+	}
+	return strings.Join(types, ",")
+}
+
 // ClaimAllAvailableBonuses claims all available bonuses for a user
 // Note: Vote bonus is excluded from claimall as it requires manual Top.gg verification
 func (bm *BonusManager) ClaimAllAvailableBonuses(user *User) ([]*BonusResult, error) {
@@ -451,6 +485,8 @@ func (bm *BonusManager) ClaimAllAvailableBonuses(user *User) ([]*BonusResult, er
 	// Apply all updates at once
 	updates.ChipsIncrement = totalChips
 	updates.TotalXPIncrement = totalXP
+	updates.Reason = TxReasonBonus
+	updates.Note = claimedBonusTypes(claimedBonuses)
 
 	_, err := UpdateCachedUser(user.UserID, updates)
 	if err != nil {
@@ -460,6 +496,10 @@ func (bm *BonusManager) ClaimAllAvailableBonuses(user *User) ([]*BonusResult, er
 	log.Printf("User %d claimed %d bonuses: %d chips, %d XP total",
 		user.UserID, len(claimedBonuses), totalChips, totalXP)
 
+	for _, claimed := range claimedBonuses {
+		claimed.BonusInfo.Streak = recordBonusStreak(user.UserID, claimed.BonusInfo.Type, now, nil, nil)
+	}
+
 	return claimedBonuses, nil
 }
 
@@ -517,6 +557,28 @@ func (bm *BonusManager) CreateBonusEmbed(user *User, bonusResult *BonusResult, t
 		})
 	}
 
+	if update := bonusInfo.Streak; update != nil {
+		unit := StreakUnit(update.Streak.Type)
+		lines := []string{formatStreak(update.Streak.Current, update.Streak.Best, unit)}
+		if update.FreezesUsed > 0 {
+			lines = append(lines, fmt.Sprintf("🧊 Used %d streak freeze(s) to keep it alive", update.FreezesUsed))
+		}
+		if update.BrokenFrom > 0 {
+			lines = append(lines, fmt.Sprintf("💔 Your %d-%s streak was broken", update.BrokenFrom, unit))
+		}
+		if m := update.Milestone; m != nil {
+			lines = append(lines, fmt.Sprintf("🏆 %d-%s milestone: +%s %s", m.Length, unit, FormatChips(m.Chips), ChipsEmoji))
+			if m.Freezes > 0 {
+				lines = append(lines, fmt.Sprintf("🧊 +%d streak freeze (holding %d/%d)", m.Freezes, update.FreezesLeft, MaxStreakFreezes))
+			}
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("🔥 %s Streak", strings.Title(string(update.Streak.Type))),
+			Value:  strings.Join(lines, "\n"),
+			Inline: false,
+		})
+	}
+
 	// Add next available time
 	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 		Name:   "⏰ Next Available",
@@ -588,6 +650,25 @@ func (bm *BonusManager) CreateCooldownEmbed(user *User) *discordgo.MessageEmbed
 		})
 	}
 
+	if streaks, freezes, err := GetStreaks(user.UserID); err == nil {
+		now := time.Now()
+		var lines []string
+		for _, streakType := range []StreakType{StreakDaily, StreakWeekly, StreakVote} {
+			streak := streaks[streakType]
+			current := streak.Current
+			if !streak.Alive(now, freezes) {
+				current = 0
+			}
+			lines = append(lines, fmt.Sprintf("**%s**: %s", strings.Title(string(streakType)), formatStreak(current, streak.Best, StreakUnit(streakType))))
+		}
+		lines = append(lines, fmt.Sprintf("🧊 Streak freezes: %d/%d", freezes, MaxStreakFreezes))
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "🔥 Streaks",
+			Value:  strings.Join(lines, "\n"),
+			Inline: false,
+		})
+	}
+
 	return embed
 }
 
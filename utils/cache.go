@@ -2,6 +2,7 @@ package utils
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -26,6 +27,9 @@ type UserCache struct {
 	cleanupTicker *time.Ticker
 	done          chan bool
 	hotThreshold  int64 // Access count threshold for "hot" classification
+	hits          atomic.Int64
+	misses        atomic.Int64
+	lastCleanup   atomic.Int64 // unix nanoseconds, so readiness can spot a stalled cleanup
 }
 
 // Global cache instance
@@ -41,6 +45,8 @@ func InitializeCache(ttl time.Duration) {
 		hotThreshold: 5, // Consider user "hot" after 5 accesses
 	}
 
+	Cache.lastCleanup.Store(time.Now().UnixNano())
+
 	// Start cleanup routine every 90 seconds for better performance
 	Cache.cleanupTicker = time.NewTicker(90 * time.Second)
 	go Cache.cleanupRoutine()
@@ -220,6 +226,7 @@ func (uc *UserCache) cleanupRoutine() {
 // cleanup removes expired entries
 func (uc *UserCache) cleanup() {
 	now := time.Now()
+	defer uc.lastCleanup.Store(now.UnixNano())
 	expiredKeys := make([]int64, 0)
 
 	uc.mutex.RLock()
@@ -248,8 +255,10 @@ func GetCachedUser(userID int64) (*User, error) {
 	// Try cache first with zero-copy optimization
 	if Cache != nil {
 		if user, found := Cache.Get(userID); found {
+			Cache.hits.Add(1)
 			return user, nil
 		}
+		Cache.misses.Add(1)
 	}
 
 	// If not in cache, get from database
@@ -280,6 +289,15 @@ func UpdateCachedUserWithNotification(userID int64, updates UserUpdateData, sess
 		return nil, err
 	}
 
+	afterUserUpdate(user, session, interaction)
+
+	return user, nil
+}
+
+// afterUserUpdate refreshes the cache and runs achievement checks for a freshly written user row
+func afterUserUpdate(user *User, session *discordgo.Session, interaction *discordgo.InteractionCreate) {
+	userID := user.UserID
+
 	// Update cache if cache is initialized
 	if Cache != nil {
 		Cache.Update(userID, user)
@@ -300,8 +318,6 @@ func UpdateCachedUserWithNotification(userID int64, updates UserUpdateData, sess
 			}(user, userID)
 		}
 	}
-
-	return user, nil
 }
 
 // InvalidateUserCache removes a user from cache
@@ -319,6 +335,16 @@ type CacheStats struct {
 	HotEntries  int           `json:"hot_entries"`
 	ColdEntries int           `json:"cold_entries"`
 	LastCleanup time.Time     `json:"last_cleanup"`
+	Hits        int64         `json:"hits"`   // lookups served from the cache
+	Misses      int64         `json:"misses"` // lookups that went to the database
+}
+
+// HitRatio is the share of lookups served from the cache, 0 before any lookups
+func (cs CacheStats) HitRatio() float64 {
+	if cs.Hits+cs.Misses == 0 {
+		return 0
+	}
+	return float64(cs.Hits) / float64(cs.Hits+cs.Misses)
 }
 
 // GetCacheStats returns current cache statistics with hot/cold breakdown
@@ -347,6 +373,9 @@ func GetCacheStats() CacheStats {
 		ColdTTL:     Cache.coldTTL,
 		HotEntries:  hot,
 		ColdEntries: cold,
+		LastCleanup: time.Unix(0, Cache.lastCleanup.Load()),
+		Hits:        Cache.hits.Load(),
+		Misses:      Cache.misses.Load(),
 	}
 }
 
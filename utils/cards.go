@@ -2,8 +2,8 @@ package utils
 
 import (
 	"fmt"
-	"math/rand"
-	"time"
+
+	"hrc-go/utils/rng"
 )
 
 // Card represents a playing card
@@ -92,17 +92,22 @@ type Deck struct {
 	Game       string `json:"game"`
 	DealtCards int    `json:"dealt_cards"`
 	TotalCards int    `json:"total_cards"`
-	rng        *rand.Rand
+	src        rng.Source
 }
 
-// NewDeck creates a new deck of cards
+// NewDeck creates a new deck of cards shuffled with the default RNG
 func NewDeck(numDecks int, game string) *Deck {
+	return NewDeckWithSource(numDecks, game, rng.Default())
+}
+
+// NewDeckWithSource creates a new deck of cards shuffled with src
+func NewDeckWithSource(numDecks int, game string, src rng.Source) *Deck {
 	deck := &Deck{
 		Cards:      make([]Card, 0),
 		NumDecks:   numDecks,
 		Game:       game,
 		DealtCards: 0,
-		rng:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		src:        src,
 	}
 
 	deck.buildDeck()
@@ -118,7 +123,7 @@ func (d *Deck) buildDeck() {
 	// Create all cards for each deck
 	for deckNum := 0; deckNum < d.NumDecks; deckNum++ {
 		for _, suit := range CardSuits {
-			for rank := range CardRanks {
+			for _, rank := range CardRankOrder {
 				card := NewCard(rank, suit)
 				d.Cards = append(d.Cards, card)
 			}
@@ -129,9 +134,15 @@ func (d *Deck) buildDeck() {
 	d.DealtCards = 0
 }
 
+// Reattach sets the source used for future reshuffles, after a deck has been restored
+// from a snapshot
+func (d *Deck) Reattach(src rng.Source) {
+	d.src = src
+}
+
 // Shuffle shuffles the deck
 func (d *Deck) shuffle() {
-	d.rng.Shuffle(len(d.Cards), func(i, j int) {
+	d.src.Shuffle(len(d.Cards), func(i, j int) {
 		d.Cards[i], d.Cards[j] = d.Cards[j], d.Cards[i]
 	})
 }
@@ -2,19 +2,42 @@ package utils
 
 // General Configuration
 const (
-	GuildID             int64 = 1396567190102347776
-	HighRollersClubLink       = "https://discord.gg/RK4K8tDsHB"
-	DeveloperRoleID     int64 = 1399860810574073978
-	BotColor                  = 0x5865F2
-	PremiumRoleID       int64 = 1396631093154943026
+	HighRollersClubLink = "https://discord.gg/RK4K8tDsHB"
+	BotColor            = 0x5865F2
 )
 
-// Economy & XP
-const (
-	StartingChips = 1000
-	DailyReward   = 500
-	XPPerProfit   = 2
-)
+// DiscordIDs are the application, servers, roles and channels the bot treats specially
+type DiscordIDs struct {
+	BotID             string `yaml:"bot_id"`
+	HomeGuildID       string `yaml:"home_guild_id"` // the support server: server bonus and admin commands
+	DevGuildID        string `yaml:"dev_guild_id"`  // commands register here instantly
+	PremiumRoleID     string `yaml:"premium_role_id"`
+	AdminRoleID       string `yaml:"admin_role_id"`
+	AdminLogChannelID string `yaml:"admin_log_channel_id"`
+}
+
+// IDs holds the active Discord IDs, set once at startup by config.Apply. It is one of
+// the three configuration globals; new settings are passed to their owner instead.
+var IDs = DiscordIDs{
+	BotID:             "1396564026233983108",
+	HomeGuildID:       "1396567190102347776",
+	DevGuildID:        "1262162191923023882",
+	PremiumRoleID:     "1396631093154943026",
+	AdminRoleID:       "1396615290015453195",
+	AdminLogChannelID: "1396996421340626954",
+}
+
+// EconomyConfig sets what new players start with and how winnings earn XP
+type EconomyConfig struct {
+	StartingChips int64 `yaml:"starting_chips"`
+	XPPerProfit   int64 `yaml:"xp_per_profit"` // XP per chip of profit on a winning round
+}
+
+// Economy holds the active economy rules, set once at startup by config.Apply
+var Economy = EconomyConfig{
+	StartingChips: 1000,
+	XPPerProfit:   2,
+}
 
 // Ranks with XP requirements and colors
 type Rank struct {
@@ -42,6 +65,9 @@ var (
 		"2": 2, "3": 3, "4": 4, "5": 5, "6": 6, "7": 7, "8": 8, "9": 9, "10": 10,
 		"J": 10, "Q": 10, "K": 10, "A": 11,
 	}
+	// CardRankOrder fixes the order ranks are laid out in a fresh deck, so a
+	// shuffle from a given source always produces the same shoe
+	CardRankOrder = []string{"2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K", "A"}
 )
 
 // Blackjack Game Constants
@@ -101,6 +127,7 @@ const (
 	TimeoutMessage     = "You did not respond in time. The interaction has timed out."
 	GameTimeoutMessage = "You did not respond in time. Your game has timed out and you have forfeited your bet of %d <:chips:1404332422451040330>."
 	GameCleanupMessage = "Your game has been removed due to inactivity. You have forfeited your bet of %d <:chips:1404332422451040330>."
+	GameRefundMessage  = "You did not respond in time. Your game has timed out and your bet of %d <:chips:1404332422451040330> has been refunded."
 	TopGGVoteLink      = "https://top.gg/bot/1396564026233983108/vote"
 )
 
@@ -5,7 +5,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
-	"os"
+	"log"
 	"strconv"
 	"strings"
 	"sync"
@@ -50,6 +50,21 @@ type UserUpdateData struct {
 	LastVote                     *time.Time
 	LastBonus                    *time.Time
 	PremiumSettings              JSONB
+
+	// Absolute values for admin corrections. UpdateUser turns them into increments under a
+	// row lock, so the ledger still records the exact chip delta.
+	SetChips       *int64
+	SetTotalXP     *int64
+	SetCurrentXP   *int64
+	SetWins        *int
+	SetLosses      *int
+	ResetCooldowns bool // clears every bonus claim timestamp
+
+	// Ledger metadata recorded with any chip change (see chip_transactions)
+	Reason   string
+	GameType string
+	GameID   string
+	Note     string
 }
 
 type Achievement struct {
@@ -205,11 +220,32 @@ func PutStringSliceToPool(slice []string) {
 var (
 	DB            *pgxpool.Pool
 	dbInitialized = false
+	dbConfigured  = false // a database URL was given, whether or not it connected
 	dbMutex       sync.RWMutex
 )
 
-// SetupDatabase initializes the database connection pool
-func SetupDatabase() error {
+// SetupDatabase connects to the database and applies any pending schema migrations
+func SetupDatabase(databaseURL string) error {
+	if err := ConnectDatabase(databaseURL); err != nil {
+		return err
+	}
+	if DB == nil {
+		return nil
+	}
+
+	applied, err := MigrateUp(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	if applied > 0 {
+		log.Printf("🗄️ Applied %d schema migrations", applied)
+	}
+	return nil
+}
+
+// ConnectDatabase initializes the database connection pool without touching the schema.
+// An empty URL leaves the bot running without a database.
+func ConnectDatabase(databaseURL string) error {
 	dbMutex.Lock()
 	defer dbMutex.Unlock()
 
@@ -217,10 +253,10 @@ func SetupDatabase() error {
 		return nil
 	}
 
-	databaseURL := os.Getenv("DATABASE_URL")
 	if databaseURL == "" {
 		return nil
 	}
+	dbConfigured = true
 
 	ctx := context.Background()
 
@@ -262,15 +298,6 @@ func SetupDatabase() error {
 	DB = pool
 	dbInitialized = true
 
-	// Ensure core tables exist
-	createUsersTable()
-
-	// Create user_achievements table if it doesn't exist
-	createUserAchievementsTable()
-
-	// Create performance indexes
-	createPerformanceIndexes()
-
 	// Note: Prepared statements with connection pools require per-connection preparation
 	// We'll use optimized direct queries instead for better pool compatibility
 
@@ -294,7 +321,7 @@ func GetUser(userID int64) (*User, error) {
 	if DB == nil {
 		return &User{
 			UserID:              userID,
-			Chips:               StartingChips,
+			Chips:               Economy.StartingChips,
 			TotalXP:             0,
 			CurrentXP:           0,
 			Prestige:            0,
@@ -361,7 +388,7 @@ func CreateUser(userID int64) (*User, error) {
 	if DB == nil {
 		return &User{
 			UserID:              userID,
-			Chips:               StartingChips,
+			Chips:               Economy.StartingChips,
 			TotalXP:             0,
 			CurrentXP:           0,
 			Prestige:            0,
@@ -388,7 +415,7 @@ func CreateUser(userID int64) (*User, error) {
 	}()
 
 	user.UserID = userID
-	user.Chips = StartingChips
+	user.Chips = Economy.StartingChips
 	user.TotalXP = 0
 	user.CurrentXP = 0
 	user.Prestige = 0
@@ -431,7 +458,7 @@ func UpdateUser(userID int64, updates UserUpdateData) (*User, error) {
 		// Return dummy user for testing
 		return &User{
 			UserID:              userID,
-			Chips:               StartingChips + updates.ChipsIncrement,
+			Chips:               Economy.StartingChips + updates.ChipsIncrement,
 			TotalXP:             updates.TotalXPIncrement,
 			CurrentXP:           updates.CurrentXPIncrement,
 			Prestige:            0,
@@ -445,8 +472,84 @@ func UpdateUser(userID int64, updates UserUpdateData) (*User, error) {
 	}
 
 	ctx := context.Background()
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if updates.hasSetFields() {
+		if err := resolveSetFields(ctx, tx, userID, &updates); err != nil {
+			return nil, err
+		}
+	}
+
+	setParts, args := buildUserUpdateSet(userID, updates)
+	if len(setParts) == 0 {
+		// No updates to make, just return current user
+		return GetUser(userID)
+	}
+
+	user, err := updateUserInTx(ctx, tx, setParts, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if updates.ChipsIncrement != 0 {
+		if err := recordChipTransaction(ctx, tx, user, updates); err != nil {
+			PutUserToPool(user)
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		PutUserToPool(user)
+		return nil, fmt.Errorf("failed to commit user update: %w", err)
+	}
+
+	return user, nil
+}
+
+func (u *UserUpdateData) hasSetFields() bool {
+	return u.SetChips != nil || u.SetTotalXP != nil || u.SetCurrentXP != nil || u.SetWins != nil || u.SetLosses != nil
+}
 
-	// Build dynamic query based on what fields need updating
+// resolveSetFields locks the user's row and rewrites absolute Set* values as increments
+// from the current values
+func resolveSetFields(ctx context.Context, tx pgx.Tx, userID int64, updates *UserUpdateData) error {
+	var chips, totalXP, currentXP int64
+	var wins, losses int
+	err := tx.QueryRow(ctx, `SELECT chips, total_xp, current_xp, wins, losses FROM users WHERE user_id = $1 FOR UPDATE`, userID).
+		Scan(&chips, &totalXP, &currentXP, &wins, &losses)
+	if err != nil {
+		return fmt.Errorf("failed to lock user for update: %w", err)
+	}
+
+	if updates.SetChips != nil {
+		updates.ChipsIncrement = *updates.SetChips - chips
+		updates.SetChips = nil
+	}
+	if updates.SetTotalXP != nil {
+		updates.TotalXPIncrement = *updates.SetTotalXP - totalXP
+		updates.SetTotalXP = nil
+	}
+	if updates.SetCurrentXP != nil {
+		updates.CurrentXPIncrement = *updates.SetCurrentXP - currentXP
+		updates.SetCurrentXP = nil
+	}
+	if updates.SetWins != nil {
+		updates.WinsIncrement = *updates.SetWins - wins
+		updates.SetWins = nil
+	}
+	if updates.SetLosses != nil {
+		updates.LossesIncrement = *updates.SetLosses - losses
+		updates.SetLosses = nil
+	}
+	return nil
+}
+
+// buildUserUpdateSet builds the SET clause and arguments for a user update; $1 is always userID
+func buildUserUpdateSet(userID int64, updates UserUpdateData) ([]string, []interface{}) {
 	setParts := []string{}
 	args := []interface{}{userID} // $1 will always be userID
 	argIndex := 2
@@ -535,19 +638,29 @@ func UpdateUser(userID int64, updates UserUpdateData) (*User, error) {
 		argIndex++
 	}
 
-	if len(setParts) == 0 {
-		// No updates to make, just return current user
-		return GetUser(userID)
+	if updates.ResetCooldowns {
+		setParts = append(setParts, "last_hourly = NULL", "last_daily = NULL", "last_weekly = NULL", "last_vote = NULL", "last_bonus = NULL")
+	}
+
+	return setParts, args
+}
+
+// updateUserInTx applies a prepared SET clause to the users row within tx; any extra
+// conditions are ANDed onto the WHERE clause and yield pgx.ErrNoRows when unmet
+func updateUserInTx(ctx context.Context, tx pgx.Tx, setParts []string, args []interface{}, conds ...string) (*User, error) {
+	where := "user_id = $1"
+	for _, cond := range conds {
+		where += " AND " + cond
 	}
 
 	query := fmt.Sprintf(`
 		UPDATE users 
 		SET %s
-		WHERE user_id = $1
+		WHERE %s
 		RETURNING user_id, chips, total_xp, current_xp, prestige, wins, losses, 
 				  daily_bonuses_claimed, votes_count, last_hourly, last_daily, 
 				  last_weekly, last_vote, last_bonus, premium_settings, created_at`,
-		strings.Join(setParts, ", "))
+		strings.Join(setParts, ", "), where)
 
 	// Use object pool for better memory management
 	user := GetUserFromPool()
@@ -559,7 +672,7 @@ func UpdateUser(userID int64, updates UserUpdateData) (*User, error) {
 		}
 	}()
 
-	err = DB.QueryRow(ctx, query, args...).Scan(
+	err = tx.QueryRow(ctx, query, args...).Scan(
 		&user.UserID,
 		&user.Chips,
 		&user.TotalXP,
@@ -601,9 +714,19 @@ func BatchUpdateUsers(updates []struct {
 	}
 	defer tx.Rollback(ctx)
 
-	// Batch update using COPY or prepared statements
 	for _, update := range updates {
-		_, err := UpdateUser(update.UserID, update.Data)
+		setParts, args := buildUserUpdateSet(update.UserID, update.Data)
+		if len(setParts) == 0 {
+			continue
+		}
+		user, err := updateUserInTx(ctx, tx, setParts, args)
+		if err != nil {
+			return fmt.Errorf("failed to update user %d: %w", update.UserID, err)
+		}
+		if update.Data.ChipsIncrement != 0 {
+			err = recordChipTransaction(ctx, tx, user, update.Data)
+		}
+		PutUserToPool(user)
 		if err != nil {
 			return fmt.Errorf("failed to update user %d: %w", update.UserID, err)
 		}
@@ -801,125 +924,3 @@ func UpdateJackpot(increment int64) (int64, error) {
 
 	return newAmount, nil
 }
-
-// createUserAchievementsTable creates the user_achievements table if it doesn't exist
-func createUserAchievementsTable() error {
-	if DB == nil {
-		return fmt.Errorf("database not connected")
-	}
-
-	ctx := context.Background()
-	query := `
-		CREATE TABLE IF NOT EXISTS user_achievements (
-			id SERIAL PRIMARY KEY,
-			user_id BIGINT NOT NULL,
-			achievement_id INTEGER NOT NULL,
-			earned_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-			UNIQUE(user_id, achievement_id)
-		);
-		
-		CREATE INDEX IF NOT EXISTS idx_user_achievements_user_id ON user_achievements(user_id);
-		CREATE INDEX IF NOT EXISTS idx_user_achievements_achievement_id ON user_achievements(achievement_id);`
-
-	_, err := DB.Exec(ctx, query)
-	if err != nil {
-		return fmt.Errorf("failed to create user_achievements table: %w", err)
-	}
-
-	return nil
-}
-
-// createUsersTable creates the users table if it does not exist
-func createUsersTable() error {
-	if DB == nil {
-		return fmt.Errorf("database not connected")
-	}
-	ctx := context.Background()
-	query := `CREATE TABLE IF NOT EXISTS users (
-		user_id BIGINT PRIMARY KEY,
-		chips BIGINT NOT NULL DEFAULT 0,
-		total_xp BIGINT NOT NULL DEFAULT 0,
-		current_xp BIGINT NOT NULL DEFAULT 0,
-		prestige INTEGER NOT NULL DEFAULT 0,
-		wins INTEGER NOT NULL DEFAULT 0,
-		losses INTEGER NOT NULL DEFAULT 0,
-		daily_bonuses_claimed INTEGER NOT NULL DEFAULT 0,
-		votes_count INTEGER NOT NULL DEFAULT 0,
-		last_hourly TIMESTAMPTZ,
-		last_daily TIMESTAMPTZ,
-		last_weekly TIMESTAMPTZ,
-		last_vote TIMESTAMPTZ,
-		last_bonus TIMESTAMPTZ,
-		premium_settings JSONB,
-		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
-	);
-	CREATE INDEX IF NOT EXISTS idx_users_chips ON users(chips);
-	CREATE INDEX IF NOT EXISTS idx_users_total_xp ON users(total_xp);
-	CREATE INDEX IF NOT EXISTS idx_users_wins ON users(wins);`
-	if _, err := DB.Exec(ctx, query); err != nil {
-		return fmt.Errorf("failed to create users table: %w", err)
-	}
-	return nil
-}
-
-// createPerformanceIndexes creates indexes to optimize query performance
-func createPerformanceIndexes() error {
-	if DB == nil {
-		return fmt.Errorf("database not connected")
-	}
-
-	ctx := context.Background()
-
-	// Performance-optimized indexes for common queries
-	indexes := []string{
-		// Leaderboard queries with composite indexes (chips, total_xp, prestige)
-		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_chips_desc ON users(chips DESC, user_id)",
-		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_total_xp_desc ON users(total_xp DESC, user_id)",
-		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_prestige_desc ON users(prestige DESC, user_id)",
-
-		// Partial indexes for high-value users (better performance for active users)
-		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_active_chips ON users(chips DESC, user_id) WHERE chips >= 10000",
-		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_high_xp ON users(total_xp DESC, user_id) WHERE total_xp >= 50000",
-
-		// Bonus cooldown queries
-		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_last_hourly ON users(last_hourly) WHERE last_hourly IS NOT NULL",
-		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_last_daily ON users(last_daily) WHERE last_daily IS NOT NULL",
-		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_last_weekly ON users(last_weekly) WHERE last_weekly IS NOT NULL",
-
-		// Achievement-related queries
-		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_wins_losses ON users(wins, losses)",
-		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_prestige_xp ON users(prestige, total_xp)",
-
-		// JSONB operations (if premium settings are queried)
-		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_premium_gin ON users USING GIN(premium_settings)",
-
-		// Time-based queries
-		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_created_at ON users(created_at)",
-	}
-
-	for _, query := range indexes {
-		DB.Exec(ctx, query)
-	}
-
-	return nil
-}
-
-// GetLeaderboard executes optimized leaderboard query with direct SQL for reliable operation
-func GetLeaderboard(leaderboardType string) (pgx.Rows, error) {
-	if DB == nil {
-		return nil, fmt.Errorf("database not connected")
-	}
-
-	ctx := context.Background()
-
-	switch leaderboardType {
-	case "chips":
-		return DB.Query(ctx, "SELECT user_id, chips FROM users ORDER BY chips DESC, user_id LIMIT 10")
-	case "xp":
-		return DB.Query(ctx, "SELECT user_id, total_xp FROM users ORDER BY total_xp DESC, user_id LIMIT 10")
-	case "prestige":
-		return DB.Query(ctx, "SELECT user_id, prestige FROM users ORDER BY prestige DESC, user_id LIMIT 10")
-	default:
-		return DB.Query(ctx, "SELECT user_id, chips FROM users ORDER BY chips DESC, user_id LIMIT 10")
-	}
-}
@@ -84,6 +84,15 @@ func GameCleanupEmbed(betAmount int64) *discordgo.MessageEmbed {
 	)
 }
 
+// GameRefundEmbed creates an embed for games whose stake was refunded on timeout
+func GameRefundEmbed(betAmount int64) *discordgo.MessageEmbed {
+	return CreateBrandedEmbed(
+		"⏰ Game Timeout",
+		fmt.Sprintf(GameRefundMessage, betAmount),
+		0xF39C12, // Orange color
+	)
+}
+
 // CreateTimeoutEmbed creates a generic timeout embed
 func CreateTimeoutEmbed() *discordgo.MessageEmbed {
 	return CreateBrandedEmbed(
@@ -290,8 +299,9 @@ func GameResultEmbed(gameType string, bet, profit int64, userBefore, userAfter *
 // (Removed duplicate Rank struct - using the one from constants.go)
 
 // UserProfileEmbed creates an embed for user profile display
-// showWinLoss controls whether wins/losses/win rate stats are shown (premium feature)
-func UserProfileEmbed(user *User, discordUser *discordgo.User, showWinLoss bool, hasPremium bool) *discordgo.MessageEmbed {
+// showWinLoss controls whether wins/losses/win rate stats are shown (premium feature);
+// seasonBadges are the badges earned from past season finishes
+func UserProfileEmbed(user *User, discordUser *discordgo.User, showWinLoss bool, hasPremium bool, seasonBadges []string) *discordgo.MessageEmbed {
 	rank := getUserRank(user.TotalXP)
 	nextRank := getNextRank(user.TotalXP)
 
@@ -300,37 +310,24 @@ func UserProfileEmbed(user *User, discordUser *discordgo.User, showWinLoss bool,
 		URL: discordUser.AvatarURL(""),
 	}
 
-	// Badges row (prestige and optional premium). Shown above everything else.
-	// Primary: prestige badge emoji; Fallback: roman numerals I, II, III, IV, V, ... if emoji missing.
+	// Badges row (premium, prestige and season finishes). Shown above everything else.
+	// The prestige badge comes from the prestige track, falling back to roman numerals.
 	badges := ""
 	if hasPremium {
 		badges = PremiumEmoji
 	}
-	if user.Prestige > 0 {
-		if em, ok := PrestigeEmojis[user.Prestige]; ok {
-			if badges != "" {
-				badges += " " + em
-			} else {
-				badges = em
-			}
+	if badge := PrestigeBadge(user.Prestige); badge != "" {
+		if badges != "" {
+			badges += " " + badge
 		} else {
-			// Fallback to roman numerals up to a reasonable range
-			roman := []string{"", "I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X"}
-			n := user.Prestige
-			if n >= 0 && n < len(roman) {
-				if badges != "" {
-					badges += " " + roman[n]
-				} else {
-					badges = roman[n]
-				}
-			} else {
-				if badges != "" {
-					badges += " " + strconv.Itoa(user.Prestige)
-				} else {
-					badges = strconv.Itoa(user.Prestige)
-				}
-			}
+			badges = badge
+		}
+	}
+	for _, badge := range seasonBadges {
+		if badges != "" {
+			badges += " "
 		}
+		badges += badge
 	}
 	// Reserve vertical space even if no badges by using a zero-width space when empty
 	if badges == "" {
@@ -393,7 +390,7 @@ func UserProfileEmbed(user *User, discordUser *discordgo.User, showWinLoss bool,
 	if showWinLoss {
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 			Name:   "Net Profit",
-			Value:  fmt.Sprintf("%s%s %s", getProfitPrefix(user.Chips-StartingChips), FormatChips(abs(user.Chips-StartingChips)), ChipsEmoji),
+			Value:  fmt.Sprintf("%s%s %s", getProfitPrefix(user.Chips-Economy.StartingChips), FormatChips(abs(user.Chips-Economy.StartingChips)), ChipsEmoji),
 			Inline: true,
 		})
 	}
@@ -445,6 +442,73 @@ func UserProfileEmbed(user *User, discordUser *discordgo.User, showWinLoss bool,
 	return embed
 }
 
+// GameStatsEmbed renders a user's detailed record in one game
+func GameStatsEmbed(stats *GameStats, discordUser *discordgo.User) *discordgo.MessageEmbed {
+	embed := CreateBrandedEmbed("📊 "+GameDisplayName(stats.GameType)+" Stats", "<@"+discordUser.ID+">", BotColor)
+	embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: discordUser.AvatarURL("")}
+
+	if stats.GamesPlayed == 0 {
+		embed.Description += " hasn't played this game yet."
+		return embed
+	}
+
+	streak := "—"
+	if stats.CurrentStreak > 0 {
+		streak = fmt.Sprintf("🔥 %d wins", stats.CurrentStreak)
+	} else if stats.CurrentStreak < 0 {
+		streak = fmt.Sprintf("🧊 %d losses", -stats.CurrentStreak)
+	}
+
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "Games Played", Value: FormatNumber(stats.GamesPlayed), Inline: true},
+		{Name: "Wins / Losses", Value: fmt.Sprintf("%s / %s", FormatNumber(stats.Wins), FormatNumber(stats.Losses)), Inline: true},
+		{Name: "Win Rate", Value: fmt.Sprintf("%.1f%%", stats.WinRate()), Inline: true},
+		{Name: "Wagered", Value: fmt.Sprintf("%s %s", FormatChips(stats.TotalWagered), ChipsEmoji), Inline: true},
+		{Name: "Won", Value: fmt.Sprintf("%s %s", FormatChips(stats.TotalWon), ChipsEmoji), Inline: true},
+		{Name: "Net", Value: formatSignedChips(stats.NetProfit()), Inline: true},
+		{Name: "Biggest Win", Value: fmt.Sprintf("%s %s", FormatChips(stats.BiggestWin), ChipsEmoji), Inline: true},
+		{Name: "Biggest Loss", Value: fmt.Sprintf("%s %s", FormatChips(stats.BiggestLoss), ChipsEmoji), Inline: true},
+		{Name: "Current Streak", Value: streak, Inline: true},
+		{Name: "Best Win Streak", Value: strconv.Itoa(stats.BestWinStreak), Inline: true},
+		{Name: "Worst Loss Streak", Value: strconv.Itoa(stats.BestLossStreak), Inline: true},
+	}
+	return embed
+}
+
+// GameStatsOverviewEmbed summarizes a user's record across every game they have played
+func GameStatsOverviewEmbed(stats []*GameStats, discordUser *discordgo.User) *discordgo.MessageEmbed {
+	embed := CreateBrandedEmbed("📊 Game Stats", "<@"+discordUser.ID+">", BotColor)
+	embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: discordUser.AvatarURL("")}
+
+	if len(stats) == 0 {
+		embed.Description += " hasn't played any games yet."
+		return embed
+	}
+
+	for _, gs := range stats {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: GameDisplayName(gs.GameType),
+			Value: fmt.Sprintf("%s played · %sW / %sL (%.1f%%)\nNet %s",
+				FormatNumber(gs.GamesPlayed), FormatNumber(gs.Wins), FormatNumber(gs.Losses), gs.WinRate(), formatSignedChips(gs.NetProfit())),
+			Inline: true,
+		})
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:   "\u200b",
+		Value:  "Use `/stats game:` for streaks and biggest wins.",
+		Inline: false,
+	})
+	return embed
+}
+
+func formatSignedChips(amount int64) string {
+	sign := getProfitPrefix(amount)
+	if amount < 0 {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s%s %s", sign, FormatChips(abs(amount)), ChipsEmoji)
+}
+
 // Helper functions
 func FormatChips(amount int64) string {
 	return FormatNumber(amount)
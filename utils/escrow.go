@@ -0,0 +1,391 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jackc/pgx/v5"
+)
+
+// Bet reservation statuses
+const (
+	ReservationHeld      = "held"
+	ReservationSettled   = "settled"
+	ReservationRefunded  = "refunded"
+	ReservationForfeited = "forfeited"
+)
+
+var (
+	// ErrInsufficientChips is returned when a stake cannot be escrowed from the user's balance
+	ErrInsufficientChips = errors.New("insufficient chips")
+	// ErrReservationClosed is returned when settling or topping up a reservation that is no longer held
+	ErrReservationClosed = errors.New("bet reservation already closed")
+	// ErrStakesHeld is returned when a balance reset is refused because bets are still in escrow
+	ErrStakesHeld = errors.New("bets are still in play")
+)
+
+// StakeTimeoutPolicy decides what happens to an escrowed stake when a game times out
+type StakeTimeoutPolicy int
+
+const (
+	// StakeRefundOnTimeout returns the full stake to the player
+	StakeRefundOnTimeout StakeTimeoutPolicy = iota
+	// StakeForfeitOnTimeout keeps the stake and records a loss
+	StakeForfeitOnTimeout
+)
+
+// BetReservation is a stake debited from a user's balance and held until the game settles
+type BetReservation struct {
+	ID        int64
+	UserID    int64
+	GameType  string
+	GameID    string
+	Amount    int64
+	Status    string
+	CreatedAt time.Time
+	mu        sync.Mutex
+}
+
+// ReserveBet atomically debits amount from the user's balance and holds it for a game.
+// Returns ErrInsufficientChips if the balance cannot cover the stake.
+func ReserveBet(userID int64, gameType, gameID string, amount int64) (*BetReservation, *User, error) {
+	if amount <= 0 {
+		return nil, nil, fmt.Errorf("invalid bet amount: %d", amount)
+	}
+	if ShuttingDown() {
+		return nil, nil, ErrShuttingDown
+	}
+	// Backstop for rounds started from buttons, which skip the dispatch gate
+	if err := CheckPlayAllowed(userID); err != nil {
+		return nil, nil, err
+	}
+	if err := CheckPlayLimits(userID, amount); err != nil {
+		return nil, nil, err
+	}
+
+	res := &BetReservation{
+		UserID:    userID,
+		GameType:  gameType,
+		GameID:    gameID,
+		Status:    ReservationHeld,
+		CreatedAt: time.Now(),
+	}
+
+	user, err := res.debit(amount, func(ctx context.Context, tx pgx.Tx) error {
+		return tx.QueryRow(ctx, `
+			INSERT INTO bet_reservations (user_id, game_type, game_id, amount)
+			VALUES ($1, $2, NULLIF($3, ''), $4)
+			RETURNING id, created_at`,
+			userID, gameType, gameID, amount).Scan(&res.ID, &res.CreatedAt)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res.Amount = amount
+	notePlay(userID, time.Now())
+	return res, user, nil
+}
+
+// Increase escrows an additional amount on a held reservation (doubles, splits, extra bets)
+func (r *BetReservation) Increase(amount int64) (*User, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("invalid bet amount: %d", amount)
+	}
+	// Doubles, splits and extra side bets count towards the player's limits too
+	if err := CheckPlayLimits(r.UserID, amount); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Status != ReservationHeld {
+		return nil, ErrReservationClosed
+	}
+
+	user, err := r.debit(amount, func(ctx context.Context, tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			UPDATE bet_reservations SET amount = amount + $2
+			WHERE id = $1 AND status = 'held'`,
+			r.ID, amount)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrReservationClosed
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.Amount += amount
+	return user, nil
+}
+
+// Held returns the amount currently escrowed on the reservation
+func (r *BetReservation) Held() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.Amount
+}
+
+// debit removes amount from the user's balance only if it can be covered, running
+// record in the same transaction so the escrow row and the debit land together
+func (r *BetReservation) debit(amount int64, record func(ctx context.Context, tx pgx.Tx) error) (*User, error) {
+	updates := UserUpdateData{
+		ChipsIncrement: -amount,
+		Reason:         TxReasonBet,
+		GameType:       r.GameType,
+		GameID:         r.GameID,
+	}
+
+	if DB == nil {
+		user, err := GetCachedUser(r.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user data: %w", err)
+		}
+		if user.Chips < amount {
+			return nil, ErrInsufficientChips
+		}
+		return UpdateCachedUser(r.UserID, updates)
+	}
+
+	// Make sure the users row exists before the conditional debit
+	if _, err := GetUser(r.UserID); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	setParts, args := buildUserUpdateSet(r.UserID, updates)
+	args = append(args, amount)
+	user, err := updateUserInTx(ctx, tx, setParts, args, fmt.Sprintf("chips >= $%d", len(args)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInsufficientChips
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := record(ctx, tx); err != nil {
+		PutUserToPool(user)
+		if errors.Is(err, ErrReservationClosed) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to record bet reservation: %w", err)
+	}
+
+	if err := recordChipTransaction(ctx, tx, user, updates); err != nil {
+		PutUserToPool(user)
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		PutUserToPool(user)
+		return nil, fmt.Errorf("failed to commit bet reservation: %w", err)
+	}
+
+	if Cache != nil {
+		Cache.Update(user.UserID, user)
+	}
+
+	return user, nil
+}
+
+// SettleBet closes a held reservation, crediting payout (the total returned to the
+// player, stake included) together with any stat changes in stats. A payout below
+// zero debits the difference from the player's balance.
+func SettleBet(r *BetReservation, payout int64, stats UserUpdateData, session *discordgo.Session, interaction *discordgo.InteractionCreate) (*User, error) {
+	if stats.Reason == "" {
+		stats.Reason = TxReasonGame
+	}
+	return r.close(ReservationSettled, payout, stats, session, interaction)
+}
+
+// RefundBet closes a held reservation and returns the full stake without touching stats
+func RefundBet(r *BetReservation) (*User, error) {
+	r.mu.Lock()
+	amount := r.Amount
+	r.mu.Unlock()
+	return r.close(ReservationRefunded, amount, UserUpdateData{Reason: TxReasonRefund}, nil, nil)
+}
+
+// ForfeitBet closes a held reservation, keeping the stake and recording a loss
+func ForfeitBet(r *BetReservation) (*User, error) {
+	return r.close(ReservationForfeited, 0, UserUpdateData{LossesIncrement: 1}, nil, nil)
+}
+
+// close marks the reservation with status and applies the credit in one transaction
+func (r *BetReservation) close(status string, payout int64, updates UserUpdateData, session *discordgo.Session, interaction *discordgo.InteractionCreate) (*User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Status != ReservationHeld {
+		return nil, ErrReservationClosed
+	}
+
+	updates.ChipsIncrement = payout
+	if updates.GameType == "" {
+		updates.GameType = r.GameType
+	}
+	if updates.GameID == "" {
+		updates.GameID = r.GameID
+	}
+
+	if DB == nil {
+		user, err := UpdateCachedUserWithNotification(r.UserID, updates, session, interaction)
+		if err != nil {
+			return nil, err
+		}
+		r.Status = status
+		return user, nil
+	}
+
+	ctx := context.Background()
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE bet_reservations SET status = $2, payout = $3, settled_at = NOW()
+		WHERE id = $1 AND status = 'held'`,
+		r.ID, status, payout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close bet reservation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrReservationClosed
+	}
+
+	// Refunds are not games played; settlements and forfeits count towards per-game stats
+	if status != ReservationRefunded {
+		if err := recordGameStatsInTx(ctx, tx, r.UserID, r.GameType, r.Amount, payout); err != nil {
+			return nil, err
+		}
+		if err := recordSeasonStatsInTx(ctx, tx, r.UserID, r.Amount, payout); err != nil {
+			return nil, err
+		}
+	}
+
+	var user *User
+	setParts, args := buildUserUpdateSet(r.UserID, updates)
+	if len(setParts) > 0 {
+		user, err = updateUserInTx(ctx, tx, setParts, args)
+		if err != nil {
+			return nil, err
+		}
+		if updates.ChipsIncrement != 0 {
+			if err := recordChipTransaction(ctx, tx, user, updates); err != nil {
+				PutUserToPool(user)
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit bet settlement: %w", err)
+	}
+	r.Status = status
+
+	if user == nil {
+		user, err = GetCachedUser(r.UserID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		afterUserUpdate(user, session, interaction)
+	}
+
+	if status != ReservationRefunded {
+		recordGameMoney(r.GameType, r.Amount, payout)
+		emitGameFinished(r, r.Amount, payout, user, session, interaction)
+		realityCheck(session, interaction, r.UserID, payout-r.Amount)
+		announceBigWin(session, interaction, r, payout-r.Amount)
+	}
+	return user, nil
+}
+
+// rowQuerier is satisfied by both a pooled connection and a transaction
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// checkNoHeldStakes locks the user's held reservations and returns ErrStakesHeld if there
+// are any. Balance resets call it under the user's row lock so stakes escrowed before the
+// reset can't settle on top of the fresh balance.
+func checkNoHeldStakes(ctx context.Context, db rowQuerier, userID int64) error {
+	var games int
+	var held int64
+	err := db.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(amount), 0) FROM (
+			SELECT amount FROM bet_reservations
+			WHERE user_id = $1 AND status = 'held'
+			FOR UPDATE
+		) held`, userID).Scan(&games, &held)
+	if err != nil {
+		return fmt.Errorf("failed to check held bets: %w", err)
+	}
+	if games > 0 {
+		return fmt.Errorf("%w: %d chips in %d unfinished games", ErrStakesHeld, held, games)
+	}
+	return nil
+}
+
+// RefundOrphanedReservations refunds every reservation still held from a previous process,
+// except those owned by a game snapshot; RestoreGames resumes or refunds those.
+// Call once at startup before any game can create new reservations.
+func RefundOrphanedReservations() (int, error) {
+	if DB == nil {
+		return 0, nil
+	}
+
+	ctx := context.Background()
+	rows, err := DB.Query(ctx, `
+		SELECT id, user_id, game_type, COALESCE(game_id, ''), amount, created_at
+		FROM bet_reservations
+		WHERE status = 'held'
+		  AND id NOT IN (SELECT reservation_id FROM game_snapshots WHERE reservation_id IS NOT NULL)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query held reservations: %w", err)
+	}
+
+	var orphaned []*BetReservation
+	for rows.Next() {
+		r := &BetReservation{Status: ReservationHeld}
+		if err := rows.Scan(&r.ID, &r.UserID, &r.GameType, &r.GameID, &r.Amount, &r.CreatedAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan reservation: %w", err)
+		}
+		orphaned = append(orphaned, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to iterate held reservations: %w", err)
+	}
+
+	refunded := 0
+	for _, r := range orphaned {
+		if _, err := RefundBet(r); err != nil {
+			log.Printf("⚠️ Failed to refund orphaned reservation %d for user %d: %v", r.ID, r.UserID, err)
+			continue
+		}
+		refunded++
+	}
+
+	return refunded, nil
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// heldStakesRow answers checkNoHeldStakes with a fixed count and total
+type heldStakesRow struct {
+	games int
+	held  int64
+	query *string
+}
+
+func (r heldStakesRow) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	*r.query = sql
+	return r
+}
+
+func (r heldStakesRow) Scan(dest ...any) error {
+	*dest[0].(*int) = r.games
+	*dest[1].(*int64) = r.held
+	return nil
+}
+
+func TestCheckNoHeldStakesRefusesOpenGames(t *testing.T) {
+	var query string
+	err := checkNoHeldStakes(context.Background(), heldStakesRow{games: 2, held: 5000, query: &query}, 1)
+	if !errors.Is(err, ErrStakesHeld) {
+		t.Fatalf("err = %v, want ErrStakesHeld", err)
+	}
+	if !strings.Contains(err.Error(), "5000 chips in 2") {
+		t.Errorf("error does not describe the held stakes: %v", err)
+	}
+	// The reservations must stay locked until the reset commits
+	if !strings.Contains(query, "FOR UPDATE") || !strings.Contains(query, "status = 'held'") {
+		t.Errorf("held reservations are not locked: %s", query)
+	}
+
+	if err := checkNoHeldStakes(context.Background(), heldStakesRow{query: &query}, 1); err != nil {
+		t.Fatalf("no held stakes: err = %v", err)
+	}
+}
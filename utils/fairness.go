@@ -0,0 +1,364 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"hrc-go/utils/rng"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jackc/pgx/v5"
+)
+
+// MaxClientSeedLength caps the client seed a player may choose
+const MaxClientSeedLength = 64
+
+// ErrFairRoundNotFound is returned when a round ID does not exist
+var ErrFairRoundNotFound = errors.New("fair round not found")
+
+// FairRound is one provably-fair round. The server seed hash is published before
+// play; the server seed itself is revealed once the round settles, after which the
+// outcome can be recomputed from (server seed, client seed, nonce).
+type FairRound struct {
+	ID             int64
+	UserID         int64
+	GameType       string
+	GameID         string
+	ServerSeed     string
+	ServerSeedHash string
+	ClientSeed     string
+	Nonce          int64
+	Params         JSONB
+	Revealed       bool
+	CreatedAt      time.Time
+	RevealedAt     *time.Time
+	src            *rng.FairSource
+	mu             sync.Mutex
+}
+
+// Offline client seeds and nonces, used when the database is unavailable
+var (
+	offlineSeedsMu sync.Mutex
+	offlineSeeds   = make(map[int64]*fairSeedState)
+)
+
+type fairSeedState struct {
+	clientSeed string
+	nonce      int64
+}
+
+// randomSeed returns a fresh 32-byte hex seed
+func randomSeed() string {
+	var b [32]byte
+	_, _ = rand.Read(b[:]) // crypto/rand.Read never returns an error
+	return hex.EncodeToString(b[:])
+}
+
+// SetClientSeed replaces the player's client seed for future rounds
+func SetClientSeed(userID int64, clientSeed string) error {
+	if clientSeed == "" || len(clientSeed) > MaxClientSeedLength {
+		return fmt.Errorf("client seed must be 1-%d characters", MaxClientSeedLength)
+	}
+
+	if DB == nil {
+		offlineSeedsMu.Lock()
+		defer offlineSeedsMu.Unlock()
+		state, ok := offlineSeeds[userID]
+		if !ok {
+			state = &fairSeedState{}
+			offlineSeeds[userID] = state
+		}
+		state.clientSeed = clientSeed
+		return nil
+	}
+
+	ctx := context.Background()
+	_, err := DB.Exec(ctx, `
+		INSERT INTO fairness_seeds (user_id, client_seed)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET client_seed = EXCLUDED.client_seed, updated_at = NOW()`,
+		userID, clientSeed)
+	if err != nil {
+		return fmt.Errorf("failed to set client seed: %w", err)
+	}
+
+	return nil
+}
+
+// GetClientSeed returns the player's current client seed and the nonce of their last round
+func GetClientSeed(userID int64) (string, int64, error) {
+	if DB == nil {
+		offlineSeedsMu.Lock()
+		defer offlineSeedsMu.Unlock()
+		if state, ok := offlineSeeds[userID]; ok && state.clientSeed != "" {
+			return state.clientSeed, state.nonce, nil
+		}
+		return "", 0, nil
+	}
+
+	ctx := context.Background()
+	var clientSeed string
+	var nonce int64
+	err := DB.QueryRow(ctx, `SELECT client_seed, nonce FROM fairness_seeds WHERE user_id = $1`, userID).Scan(&clientSeed, &nonce)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get client seed: %w", err)
+	}
+
+	return clientSeed, nonce, nil
+}
+
+// nextClientNonce returns the player's client seed and advances their nonce
+func nextClientNonce(userID int64) (string, int64, error) {
+	if DB == nil {
+		offlineSeedsMu.Lock()
+		defer offlineSeedsMu.Unlock()
+		state, ok := offlineSeeds[userID]
+		if !ok {
+			state = &fairSeedState{}
+			offlineSeeds[userID] = state
+		}
+		if state.clientSeed == "" {
+			state.clientSeed = randomSeed()[:16]
+		}
+		state.nonce++
+		return state.clientSeed, state.nonce, nil
+	}
+
+	ctx := context.Background()
+	var clientSeed string
+	var nonce int64
+	err := DB.QueryRow(ctx, `
+		INSERT INTO fairness_seeds (user_id, client_seed, nonce)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (user_id) DO UPDATE SET nonce = fairness_seeds.nonce + 1, updated_at = NOW()
+		RETURNING client_seed, nonce`,
+		userID, randomSeed()[:16]).Scan(&clientSeed, &nonce)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to advance nonce: %w", err)
+	}
+
+	return clientSeed, nonce, nil
+}
+
+// NewFairRound commits a fresh server seed for the player's next round. If the round
+// cannot be persisted it is still played from the committed seeds, just without an ID.
+func NewFairRound(userID int64, gameType, gameID string, params JSONB) *FairRound {
+	round := &FairRound{
+		UserID:     userID,
+		GameType:   gameType,
+		GameID:     gameID,
+		ServerSeed: randomSeed(),
+		Params:     params,
+		CreatedAt:  time.Now(),
+	}
+	round.ServerSeedHash = rng.HashServerSeed(round.ServerSeed)
+
+	clientSeed, nonce, err := nextClientNonce(userID)
+	if err != nil {
+		log.Printf("⚠️ Failed to load client seed for user %d: %v", userID, err)
+		clientSeed, nonce = randomSeed()[:16], 0
+	}
+	round.ClientSeed = clientSeed
+	round.Nonce = nonce
+	round.src = rng.NewFair(round.ServerSeed, round.ClientSeed, round.Nonce)
+
+	if DB != nil {
+		ctx := context.Background()
+		err := DB.QueryRow(ctx, `
+			INSERT INTO fair_rounds (user_id, game_type, game_id, server_seed, server_seed_hash, client_seed, nonce, params)
+			VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8)
+			RETURNING id, created_at`,
+			userID, gameType, gameID, round.ServerSeed, round.ServerSeedHash, round.ClientSeed, round.Nonce, params).
+			Scan(&round.ID, &round.CreatedAt)
+		if err != nil {
+			log.Printf("⚠️ Failed to record fair round for user %d: %v", userID, err)
+		}
+	}
+
+	return round
+}
+
+// Source returns the deterministic outcome source for the round
+func (r *FairRound) Source() rng.Source {
+	return r.src
+}
+
+// Reveal publishes the round's server seed; safe to call more than once
+func (r *FairRound) Reveal() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Revealed {
+		return
+	}
+	now := time.Now()
+	r.Revealed = true
+	r.RevealedAt = &now
+
+	if DB == nil || r.ID == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	if _, err := DB.Exec(ctx, `UPDATE fair_rounds SET revealed = TRUE, revealed_at = NOW() WHERE id = $1`, r.ID); err != nil {
+		log.Printf("⚠️ Failed to reveal fair round %d: %v", r.ID, err)
+	}
+}
+
+// fairRoundFields is FairRound without its methods, for JSON encoding
+type fairRoundFields FairRound
+
+// fairRoundSnapshot is how a round is stored in a game snapshot. Snapshots sit in plain
+// game_snapshots rows, so the server seed is left out and reloaded from fair_rounds on
+// restore. A round that never got a fair_rounds row has nowhere else to keep its seed
+// and carries it in the snapshot instead.
+type fairRoundSnapshot struct {
+	*fairRoundFields
+	ServerSeed string `json:",omitempty"`
+	Draws      int64  `json:"draws"`
+}
+
+// MarshalJSON encodes the round along with how far its source has been consumed, so a
+// game snapshot restored after a restart continues drawing from the same position
+func (r *FairRound) MarshalJSON() ([]byte, error) {
+	snap := fairRoundSnapshot{fairRoundFields: (*fairRoundFields)(r)}
+	if r.src != nil {
+		snap.Draws = r.src.Draws()
+	}
+	if r.ID == 0 {
+		snap.ServerSeed = r.ServerSeed
+	}
+	return json.Marshal(snap)
+}
+
+// UnmarshalJSON restores a round encoded by MarshalJSON and rebuilds its source
+func (r *FairRound) UnmarshalJSON(data []byte) error {
+	return r.decodeSnapshot(data, loadServerSeed)
+}
+
+func (r *FairRound) decodeSnapshot(data []byte, lookup func(int64) (string, error)) error {
+	snap := fairRoundSnapshot{fairRoundFields: (*fairRoundFields)(r)}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return err
+	}
+
+	r.ServerSeed = snap.ServerSeed
+	if r.ServerSeed == "" {
+		if r.ID == 0 {
+			return fmt.Errorf("fair round snapshot has no server seed")
+		}
+		seed, err := lookup(r.ID)
+		if err != nil {
+			return err
+		}
+		r.ServerSeed = seed
+	}
+	if rng.HashServerSeed(r.ServerSeed) != r.ServerSeedHash {
+		return fmt.Errorf("server seed for fair round %d does not match its committed hash", r.ID)
+	}
+
+	r.src = rng.NewFair(r.ServerSeed, r.ClientSeed, r.Nonce)
+	r.src.Skip(snap.Draws)
+	return nil
+}
+
+// loadServerSeed reads a round's server seed, revealed or not, for resuming its game
+func loadServerSeed(id int64) (string, error) {
+	if DB == nil {
+		return "", fmt.Errorf("database not connected")
+	}
+
+	ctx := context.Background()
+	var seed string
+	err := DB.QueryRow(ctx, `SELECT server_seed FROM fair_rounds WHERE id = $1`, id).Scan(&seed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrFairRoundNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get server seed: %w", err)
+	}
+	return seed, nil
+}
+
+// IsRevealed reports whether the round's server seed has been published
+func (r *FairRound) IsRevealed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.Revealed
+}
+
+// GetFairRound loads a round by ID. The server seed is blanked until the round is revealed.
+func GetFairRound(id int64) (*FairRound, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx := context.Background()
+	round := &FairRound{ID: id}
+	err := DB.QueryRow(ctx, `
+		SELECT user_id, game_type, COALESCE(game_id, ''), server_seed, server_seed_hash,
+		       client_seed, nonce, params, revealed, created_at, revealed_at
+		FROM fair_rounds WHERE id = $1`, id).
+		Scan(&round.UserID, &round.GameType, &round.GameID, &round.ServerSeed, &round.ServerSeedHash,
+			&round.ClientSeed, &round.Nonce, &round.Params, &round.Revealed, &round.CreatedAt, &round.RevealedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrFairRoundNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fair round: %w", err)
+	}
+
+	if !round.Revealed {
+		round.ServerSeed = ""
+	}
+
+	return round, nil
+}
+
+// FairnessFooter describes the round for an embed footer: the seed hash while the
+// round is live, and how to verify it once the seed has been revealed
+func FairnessFooter(r *FairRound) string {
+	if r == nil {
+		return ""
+	}
+
+	if r.IsRevealed() {
+		if r.ID > 0 {
+			return fmt.Sprintf("Fair round #%d • /fairness verify round:%d", r.ID, r.ID)
+		}
+		return fmt.Sprintf("Seed %s • nonce %d", r.ServerSeed, r.Nonce)
+	}
+
+	if r.ID > 0 {
+		return fmt.Sprintf("Fair round #%d • hash %s…", r.ID, r.ServerSeedHash[:16])
+	}
+	return fmt.Sprintf("Fair round • hash %s…", r.ServerSeedHash[:16])
+}
+
+// AnnotateFairness appends the round's fairness line to the embed footer
+func AnnotateFairness(embed *discordgo.MessageEmbed, r *FairRound) {
+	if embed == nil || r == nil {
+		return
+	}
+
+	text := FairnessFooter(r)
+	if embed.Footer == nil {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: text}
+		return
+	}
+	if embed.Footer.Text == "" {
+		embed.Footer.Text = text
+		return
+	}
+	embed.Footer.Text += " | " + text
+}
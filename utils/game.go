@@ -1,40 +1,92 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"strconv"
 	"sync"
 	"time"
 
+	"hrc-go/utils/rng"
+
 	"github.com/bwmarrin/discordgo"
 )
 
-// Game represents the base game interface that all casino games must implement
-type Game interface {
-	GetUserID() int64
-	GetBet() int64
-	GetGameType() string
-	ValidateBet() error
-	EndGame(profit int64) (*User, error)
-	IsGameOver() bool
-	GetInteraction() *discordgo.InteractionCreate
-}
-
 // BaseGame provides common functionality for all casino games
 type BaseGame struct {
 	UserID               int64
 	Bet                  int64
 	GameType             string
-	UserData             *User
+	GameID               string
+	LedgerReason         string // Overrides the chip ledger reason recorded by EndGame
+	UserData             *User  `json:"-"`
 	IsGameOverFlag       bool
 	CountWinLossMinRatio float64 // Minimum fraction of pre-game chips required for W/L counting
+	Reservation          *BetReservation
+	TimeoutPolicy        StakeTimeoutPolicy
+	Round                *FairRound // Provably-fair round driving the game's outcomes, if any
 	Interaction          *discordgo.InteractionCreate
-	Session              *discordgo.Session
+	Session              *discordgo.Session `json:"-"`
 	CreatedAt            time.Time
 	mu                   sync.RWMutex
 }
 
+// GameConfig sets the house bet limits and idle timeouts for each game type
+type GameConfig struct {
+	BetLimits map[string]BetLimit      `yaml:"bet_limits"` // servers can only narrow these with /config
+	Timeouts  map[string]time.Duration `yaml:"timeouts"`   // how long a round may sit idle before it is closed
+}
+
+// Games holds the active game rules, set once at startup by config.Apply
+var Games = GameConfig{
+	BetLimits: map[string]BetLimit{},
+	Timeouts: map[string]time.Duration{
+		"baccarat":         3 * time.Minute,
+		"blackjack":        10 * time.Minute,
+		"craps":            2 * time.Minute,
+		"higher_or_lower":  5 * time.Minute,
+		"mines":            10 * time.Minute,
+		"roulette":         5 * time.Minute,
+		"three_card_poker": 90 * time.Second,
+	},
+}
+
+// defaultGameTimeout applies to games without a configured timeout
+const defaultGameTimeout = 5 * time.Minute
+
+// Timeout returns how long a game's rounds may sit idle
+func (c GameConfig) Timeout(gameType string) time.Duration {
+	if d, ok := c.Timeouts[gameType]; ok && d > 0 {
+		return d
+	}
+	return defaultGameTimeout
+}
+
+// ErrHouseLimit is matched by every bet outside the house limits
+var ErrHouseLimit = errors.New("outside the house bet limits")
+
+// HouseLimitError is returned when a bet is outside the house limits for its game
+type HouseLimitError struct {
+	Message string
+}
+
+func (e *HouseLimitError) Error() string { return e.Message }
+
+func (e *HouseLimitError) Unwrap() error { return ErrHouseLimit }
+
+// CheckBet applies the house minimum and maximum bet for a game
+func (c GameConfig) CheckBet(gameType string, amount int64) error {
+	limit := c.BetLimits[gameType]
+	if limit.Min > 0 && amount < limit.Min {
+		return &HouseLimitError{fmt.Sprintf("The minimum bet for %s is **%s** chips.", GameDisplayName(gameType), FormatChips(limit.Min))}
+	}
+	if limit.Max > 0 && amount > limit.Max {
+		return &HouseLimitError{fmt.Sprintf("The maximum bet for %s is **%s** chips.", GameDisplayName(gameType), FormatChips(limit.Max))}
+	}
+	return nil
+}
+
 // Achievement check debouncing
 var (
 	lastAchievementCheck    = make(map[int64]time.Time)
@@ -53,6 +105,7 @@ func NewBaseGame(session *discordgo.Session, interaction *discordgo.InteractionC
 		UserID:               userIDInt,
 		Bet:                  bet,
 		GameType:             gameType,
+		GameID:               interaction.ID,
 		IsGameOverFlag:       false,
 		CountWinLossMinRatio: 0.0,
 		Interaction:          interaction,
@@ -66,6 +119,30 @@ func (bg *BaseGame) GetUserID() int64 {
 	return bg.UserID
 }
 
+// GetGameID returns the game ID
+func (bg *BaseGame) GetGameID() string {
+	return bg.GameID
+}
+
+// GetCreatedAt returns when the game started
+func (bg *BaseGame) GetCreatedAt() time.Time {
+	return bg.CreatedAt
+}
+
+// GetReservation returns the escrowed stake, if any
+func (bg *BaseGame) GetReservation() *BetReservation {
+	return bg.Reservation
+}
+
+// FairSource returns the source of the game's fair round, or the default source if
+// the game has none
+func (bg *BaseGame) FairSource() rng.Source {
+	if bg.Round != nil && bg.Round.src != nil {
+		return bg.Round.src
+	}
+	return rng.Default()
+}
+
 // GetBet returns the bet amount
 func (bg *BaseGame) GetBet() int64 {
 	return bg.Bet
@@ -88,7 +165,7 @@ func (bg *BaseGame) IsGameOver() bool {
 	return bg.IsGameOverFlag
 }
 
-// ValidateBet checks if the player has enough chips for the bet
+// ValidateBet checks the player can cover the bet and escrows it for the game
 func (bg *BaseGame) ValidateBet() error {
 	user, err := GetCachedUser(bg.UserID)
 	if err != nil {
@@ -98,12 +175,124 @@ func (bg *BaseGame) ValidateBet() error {
 	bg.UserData = user
 
 	if user.Chips < bg.Bet {
-		return fmt.Errorf("insufficient chips: need %d, have %d", bg.Bet, user.Chips)
+		return fmt.Errorf("%w: need %d, have %d", ErrInsufficientChips, bg.Bet, user.Chips)
+	}
+	// Backstop for rounds started from buttons, which skip the dispatch gate
+	if bg.Interaction != nil {
+		if err := CheckGuildGame(bg.Interaction.GuildID, bg.Interaction.ChannelID, bg.GameType, bg.Bet); err != nil {
+			return err
+		}
+	}
+
+	bg.mu.Lock()
+	defer bg.mu.Unlock()
+	return bg.reserveStake(bg.Bet)
+}
+
+// AddStake escrows an additional wager (double down, split, side bet) on the running game
+func (bg *BaseGame) AddStake(amount int64) error {
+	bg.mu.Lock()
+	defer bg.mu.Unlock()
+
+	if bg.IsGameOverFlag {
+		return fmt.Errorf("game is already over")
+	}
+	return bg.reserveStake(amount)
+}
+
+// Stake returns the amount currently held in escrow for the game
+func (bg *BaseGame) Stake() int64 {
+	if bg.Reservation == nil {
+		return 0
+	}
+	return bg.Reservation.Held()
+}
+
+// StartFairRound commits a provably-fair round for the game and returns the source
+// its outcomes must be drawn from. params records anything needed to replay the
+// outcome (mine count, deck count).
+func (bg *BaseGame) StartFairRound(params JSONB) rng.Source {
+	bg.Round = NewFairRound(bg.UserID, bg.GameType, bg.GameID, params)
+	return bg.Round.Source()
+}
+
+// NewFairDeck starts a fair round for the game and returns a shoe shuffled from it
+func (bg *BaseGame) NewFairDeck(numDecks int, game string) *Deck {
+	return NewDeckWithSource(numDecks, game, bg.StartFairRound(JSONB{"decks": numDecks}))
+}
+
+// reserveStake opens or tops up the game's reservation; callers hold bg.mu
+func (bg *BaseGame) reserveStake(amount int64) error {
+	var user *User
+	var err error
+	if bg.Reservation == nil {
+		bg.Reservation, user, err = ReserveBet(bg.UserID, bg.GameType, bg.GameID, amount)
+	} else {
+		user, err = bg.Reservation.Increase(amount)
+	}
+
+	if errors.Is(err, ErrInsufficientChips) {
+		have := int64(0)
+		if current, cerr := GetCachedUser(bg.UserID); cerr == nil {
+			have = current.Chips
+		}
+		return fmt.Errorf("%w: need %d, have %d", ErrInsufficientChips, amount, have)
+	}
+	if IsPlayBlocked(err) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reserve bet: %w", err)
 	}
 
+	bg.UserData = user
 	return nil
 }
 
+// RefundStake ends the game and returns the escrowed stake untouched
+func (bg *BaseGame) RefundStake() (*User, error) {
+	return bg.closeStake(RefundBet)
+}
+
+// ForfeitStake ends the game, keeping the escrowed stake as a loss
+func (bg *BaseGame) ForfeitStake() (*User, error) {
+	return bg.closeStake(ForfeitBet)
+}
+
+// ExpireStake applies the game's TimeoutPolicy to the escrowed stake
+func (bg *BaseGame) ExpireStake() (*User, error) {
+	if bg.TimeoutPolicy == StakeForfeitOnTimeout {
+		return bg.ForfeitStake()
+	}
+	return bg.RefundStake()
+}
+
+func (bg *BaseGame) closeStake(closeFn func(*BetReservation) (*User, error)) (*User, error) {
+	bg.mu.Lock()
+	defer bg.mu.Unlock()
+
+	if bg.IsGameOverFlag {
+		return bg.UserData, nil
+	}
+	bg.IsGameOverFlag = true
+
+	if bg.Round != nil {
+		bg.Round.Reveal()
+	}
+
+	if bg.Reservation == nil {
+		return bg.UserData, nil
+	}
+
+	user, err := closeFn(bg.Reservation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release stake: %w", err)
+	}
+
+	bg.UserData = user
+	return user, nil
+}
+
 // EndGame finalizes the game and updates user stats
 func (bg *BaseGame) EndGame(profit int64) (*User, error) {
 	bg.mu.Lock()
@@ -114,24 +303,41 @@ func (bg *BaseGame) EndGame(profit int64) (*User, error) {
 	}
 	bg.IsGameOverFlag = true
 
+	if bg.Round != nil {
+		bg.Round.Reveal()
+	}
+
 	// Calculate XP gain
 	var xpGain int64 = 0
 	if profit > 0 {
-		xpGain = profit * XPPerProfit
+		xpGain = profit * Economy.XPPerProfit
 	}
 
 	// Determine if this game should count towards wins/losses
 	shouldCountWL := true
 	if bg.CountWinLossMinRatio > 0.0 && bg.UserData != nil {
-		requiredBet := int64(math.Ceil(float64(bg.UserData.Chips) * bg.CountWinLossMinRatio))
+		// Escrowed stakes have already left the balance; add them back for the pre-game figure
+		preGameChips := bg.UserData.Chips
+		if bg.Reservation != nil {
+			preGameChips += bg.Reservation.Held()
+		}
+		requiredBet := int64(math.Ceil(float64(preGameChips) * bg.CountWinLossMinRatio))
 		shouldCountWL = bg.Bet >= requiredBet
 	}
 
+	reason := bg.LedgerReason
+	if reason == "" {
+		reason = TxReasonGame
+	}
+
 	// Prepare update data
 	updates := UserUpdateData{
 		ChipsIncrement:     profit,
 		TotalXPIncrement:   xpGain,
 		CurrentXPIncrement: xpGain,
+		Reason:             reason,
+		GameType:           bg.GameType,
+		GameID:             bg.GameID,
 	}
 
 	if profit > 0 && shouldCountWL {
@@ -140,8 +346,16 @@ func (bg *BaseGame) EndGame(profit int64) (*User, error) {
 		updates.LossesIncrement = 1
 	}
 
-	// Update user in database and cache
-	updatedUser, err := UpdateCachedUserWithNotification(bg.UserID, updates, bg.Session, bg.Interaction)
+	// Settle the escrowed stake, or apply the profit directly when nothing was reserved
+	var updatedUser *User
+	var err error
+	if bg.Reservation != nil {
+		payout := bg.Reservation.Held() + profit
+		updates.ChipsIncrement = 0
+		updatedUser, err = SettleBet(bg.Reservation, payout, updates, bg.Session, bg.Interaction)
+	} else {
+		updatedUser, err = UpdateCachedUserWithNotification(bg.UserID, updates, bg.Session, bg.Interaction)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
@@ -246,60 +460,3 @@ func (bg *BaseGame) UpdateOriginalResponse(embed *discordgo.MessageEmbed, compon
 func parseUserID(userIDStr string) (int64, error) {
 	return strconv.ParseInt(userIDStr, 10, 64)
 }
-
-// GameManager manages active games
-type GameManager struct {
-	games map[string]Game
-	mutex sync.RWMutex
-}
-
-// Global game manager instance
-var Games = &GameManager{
-	games: make(map[string]Game),
-}
-
-// AddGame adds a game to the active games
-func (gm *GameManager) AddGame(gameID string, game Game) {
-	gm.mutex.Lock()
-	defer gm.mutex.Unlock()
-	gm.games[gameID] = game
-}
-
-// GetGame retrieves a game by ID
-func (gm *GameManager) GetGame(gameID string) (Game, bool) {
-	gm.mutex.RLock()
-	defer gm.mutex.RUnlock()
-	game, exists := gm.games[gameID]
-	return game, exists
-}
-
-// RemoveGame removes a game from active games
-func (gm *GameManager) RemoveGame(gameID string) {
-	gm.mutex.Lock()
-	defer gm.mutex.Unlock()
-	delete(gm.games, gameID)
-}
-
-// CleanupExpiredGames removes games that have been inactive too long
-func (gm *GameManager) CleanupExpiredGames(maxAge time.Duration) {
-	gm.mutex.Lock()
-	defer gm.mutex.Unlock()
-
-	now := time.Now()
-	expiredGames := make([]string, 0)
-
-	for gameID, game := range gm.games {
-		if baseGame, ok := game.(*BaseGame); ok {
-			if now.Sub(baseGame.CreatedAt) > maxAge {
-				expiredGames = append(expiredGames, gameID)
-			}
-		}
-	}
-
-	for _, gameID := range expiredGames {
-		delete(gm.games, gameID)
-	}
-
-	if len(expiredGames) > 0 {
-	}
-}
@@ -1,44 +1,57 @@
 package utils
 
 import (
+	"log"
 	"sync"
 	"time"
 )
 
-// GameState represents common game state information
+// GameState is an in-flight game tracked by the GameStateManager. Every game package
+// stores its active games here instead of keeping its own map and cleanup goroutine.
 type GameState interface {
+	GetGameID() string
 	GetUserID() int64
 	GetGameType() string
 	GetCreatedAt() time.Time
 	GetExpiresAt() time.Time
+	// IsExpired decides when the sweeper untracks the game and calls Cleanup
 	IsExpired() bool
+	// Cleanup is called once after an expired game has been untracked; it should
+	// settle the stake according to the game's timeout rules and update its message
 	Cleanup()
 }
 
+// gameSweepInterval is how often expired games are collected
+const gameSweepInterval = 30 * time.Second
+
 // GameStateManager provides centralized management for all game states
 type GameStateManager struct {
 	// Organize games by type for efficient cleanup
-	gamesByType map[string]map[interface{}]GameState
+	gamesByType map[string]map[string]GameState
 	// Index by user for quick user-specific queries
-	gamesByUser   map[int64][]interface{}
+	gamesByUser   map[int64]map[string]GameState
 	mutex         sync.RWMutex
 	cleanupTicker *time.Ticker
 	done          chan bool
 }
 
 // Global game state manager
-var GameStateMgr *GameStateManager
+var GameStateMgr = newGameStateManager()
 
-// InitializeGameManager sets up centralized game state management
-func InitializeGameManager() {
-	GameStateMgr = &GameStateManager{
-		gamesByType: make(map[string]map[interface{}]GameState),
-		gamesByUser: make(map[int64][]interface{}),
+func newGameStateManager() *GameStateManager {
+	return &GameStateManager{
+		gamesByType: make(map[string]map[string]GameState),
+		gamesByUser: make(map[int64]map[string]GameState),
 		done:        make(chan bool),
 	}
+}
 
-	// Start coordinated cleanup routine every 90 seconds
-	GameStateMgr.cleanupTicker = time.NewTicker(90 * time.Second)
+// InitializeGameManager starts the coordinated cleanup routine
+func InitializeGameManager() {
+	if GameStateMgr.cleanupTicker != nil {
+		return
+	}
+	GameStateMgr.cleanupTicker = time.NewTicker(gameSweepInterval)
 	go GameStateMgr.cleanupRoutine()
 }
 
@@ -50,68 +63,129 @@ func CloseGameManager() {
 	}
 }
 
-// RegisterGame adds a game to centralized tracking
-func (gsm *GameStateManager) RegisterGame(gameType string, gameID interface{}, game GameState) {
+// userKey indexes a game in gamesByUser
+func userKey(game GameState) string {
+	return game.GetGameType() + ":" + game.GetGameID()
+}
+
+// RegisterGame adds a game to centralized tracking, replacing any game with the same ID
+func (gsm *GameStateManager) RegisterGame(game GameState) {
 	gsm.mutex.Lock()
 	defer gsm.mutex.Unlock()
+	gsm.register(game)
+}
 
-	// Initialize game type map if it doesn't exist
-	if gsm.gamesByType[gameType] == nil {
-		gsm.gamesByType[gameType] = make(map[interface{}]GameState)
+// RegisterUserGame tracks game only if its user has no other active game of the same
+// type. Returns the existing game and false when one is already running.
+func (gsm *GameStateManager) RegisterUserGame(game GameState) (GameState, bool) {
+	gsm.mutex.Lock()
+	defer gsm.mutex.Unlock()
+
+	for _, existing := range gsm.gamesByUser[game.GetUserID()] {
+		if existing.GetGameType() == game.GetGameType() {
+			return existing, false
+		}
 	}
 
-	// Add to type index
-	gsm.gamesByType[gameType][gameID] = game
+	gsm.register(game)
+	return game, true
+}
+
+// register adds game to both indexes; callers hold gsm.mutex
+func (gsm *GameStateManager) register(game GameState) {
+	gameType := game.GetGameType()
+	if gsm.gamesByType[gameType] == nil {
+		gsm.gamesByType[gameType] = make(map[string]GameState)
+	}
+	gsm.gamesByType[gameType][game.GetGameID()] = game
 
-	// Add to user index
 	userID := game.GetUserID()
-	gsm.gamesByUser[userID] = append(gsm.gamesByUser[userID], gameID)
+	if gsm.gamesByUser[userID] == nil {
+		gsm.gamesByUser[userID] = make(map[string]GameState)
+	}
+	gsm.gamesByUser[userID][userKey(game)] = game
 }
 
-// UnregisterGame removes a game from centralized tracking
-func (gsm *GameStateManager) UnregisterGame(gameType string, gameID interface{}) {
+// UnregisterGame removes a game from centralized tracking without running Cleanup and
+// drops its snapshot
+func (gsm *GameStateManager) UnregisterGame(gameType, gameID string) {
 	gsm.mutex.Lock()
-	defer gsm.mutex.Unlock()
+	_, removed := gsm.unregister(gameType, gameID)
+	gsm.mutex.Unlock()
 
-	// Get the game to find user ID for cleanup
-	if gameMap, exists := gsm.gamesByType[gameType]; exists {
-		if game, exists := gameMap[gameID]; exists {
-			userID := game.GetUserID()
+	if removed {
+		DeleteGameSnapshot(gameType, gameID)
+	}
+}
 
-			// Remove from type index
-			delete(gameMap, gameID)
+// unregister removes a game from both indexes; callers hold gsm.mutex
+func (gsm *GameStateManager) unregister(gameType, gameID string) (GameState, bool) {
+	gameMap, exists := gsm.gamesByType[gameType]
+	if !exists {
+		return nil, false
+	}
+	game, exists := gameMap[gameID]
+	if !exists {
+		return nil, false
+	}
 
-			// Remove from user index
-			userGames := gsm.gamesByUser[userID]
-			for i, id := range userGames {
-				if id == gameID {
-					gsm.gamesByUser[userID] = append(userGames[:i], userGames[i+1:]...)
-					break
-				}
-			}
+	delete(gameMap, gameID)
 
-			// Clean up empty slices
-			if len(gsm.gamesByUser[userID]) == 0 {
-				delete(gsm.gamesByUser, userID)
-			}
+	userID := game.GetUserID()
+	delete(gsm.gamesByUser[userID], userKey(game))
+	if len(gsm.gamesByUser[userID]) == 0 {
+		delete(gsm.gamesByUser, userID)
+	}
+
+	return game, true
+}
+
+// GetGame returns the tracked game of gameType with gameID
+func (gsm *GameStateManager) GetGame(gameType, gameID string) (GameState, bool) {
+	gsm.mutex.RLock()
+	defer gsm.mutex.RUnlock()
+	game, exists := gsm.gamesByType[gameType][gameID]
+	return game, exists
+}
+
+// GetUserGame returns the user's active game of gameType, if any
+func (gsm *GameStateManager) GetUserGame(gameType string, userID int64) (GameState, bool) {
+	gsm.mutex.RLock()
+	defer gsm.mutex.RUnlock()
+	for _, game := range gsm.gamesByUser[userID] {
+		if game.GetGameType() == gameType {
+			return game, true
 		}
 	}
+	return nil, false
 }
 
-// GetUserGames returns all active games for a specific user
-func (gsm *GameStateManager) GetUserGames(userID int64) map[string][]interface{} {
+// GetGames returns a snapshot of every tracked game of gameType
+func (gsm *GameStateManager) GetGames(gameType string) []GameState {
 	gsm.mutex.RLock()
 	defer gsm.mutex.RUnlock()
+	games := make([]GameState, 0, len(gsm.gamesByType[gameType]))
+	for _, game := range gsm.gamesByType[gameType] {
+		games = append(games, game)
+	}
+	return games
+}
 
-	result := make(map[string][]interface{})
-	userGameIDs := gsm.gamesByUser[userID]
+// CountGames returns how many games of gameType are active
+func (gsm *GameStateManager) CountGames(gameType string) int {
+	gsm.mutex.RLock()
+	defer gsm.mutex.RUnlock()
+	return len(gsm.gamesByType[gameType])
+}
 
-	for _, gameID := range userGameIDs {
-		for gameType, gameMap := range gsm.gamesByType {
-			if _, exists := gameMap[gameID]; exists {
-				result[gameType] = append(result[gameType], gameID)
-			}
-		}
+// GetUserGames returns the IDs of all active games for a user, grouped by type
+func (gsm *GameStateManager) GetUserGames(userID int64) map[string][]string {
+	gsm.mutex.RLock()
+	defer gsm.mutex.RUnlock()
+
+	result := make(map[string][]string)
+	for _, game := range gsm.gamesByUser[userID] {
+		result[game.GetGameType()] = append(result[game.GetGameType()], game.GetGameID())
 	}
 
 	return result
@@ -149,89 +223,70 @@ func (gsm *GameStateManager) cleanupRoutine() {
 	}
 }
 
-// cleanupExpiredGames removes expired games across all types in a single pass
+// cleanupExpiredGames untracks expired games across all types in a single pass, then
+// runs each game's Cleanup outside the lock so it may touch the manager itself
 func (gsm *GameStateManager) cleanupExpiredGames() {
 	gsm.mutex.Lock()
-	defer gsm.mutex.Unlock()
-
-	now := time.Now()
-	totalCleaned := 0
-	cleanedByType := make(map[string]int)
-
-	// Single pass through all games to find expired ones
-	expiredGames := make(map[string][]interface{})
-
+	var expired []GameState
 	for gameType, gameMap := range gsm.gamesByType {
 		for gameID, game := range gameMap {
-			if game.IsExpired() || now.After(game.GetExpiresAt()) {
-				expiredGames[gameType] = append(expiredGames[gameType], gameID)
+			if game.IsExpired() {
+				if g, ok := gsm.unregister(gameType, gameID); ok {
+					expired = append(expired, g)
+				}
 			}
 		}
 	}
+	gsm.mutex.Unlock()
 
-	// Remove expired games and update indices
-	for gameType, gameIDs := range expiredGames {
-		gameMap := gsm.gamesByType[gameType]
-
-		for _, gameID := range gameIDs {
-			if game, exists := gameMap[gameID]; exists {
-				// Call game-specific cleanup
-				game.Cleanup()
-
-				// Remove from type index
-				delete(gameMap, gameID)
+	for _, game := range expired {
+		runGameCleanup(game)
+	}
+}
 
-				// Remove from user index
-				userID := game.GetUserID()
-				userGames := gsm.gamesByUser[userID]
-				for i, id := range userGames {
-					if id == gameID {
-						gsm.gamesByUser[userID] = append(userGames[:i], userGames[i+1:]...)
-						break
-					}
-				}
+// runGameCleanup calls game.Cleanup, containing any panic to the one game, then drops
+// the game's snapshot
+func runGameCleanup(game GameState) {
+	defer DeleteGameSnapshot(game.GetGameType(), game.GetGameID())
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("⚠️ Cleanup panicked for %s game %s: %v", game.GetGameType(), game.GetGameID(), r)
+		}
+	}()
+	game.Cleanup()
+}
 
-				// Clean up empty user entry
-				if len(gsm.gamesByUser[userID]) == 0 {
-					delete(gsm.gamesByUser, userID)
-				}
+// untrackAll removes every game from the manager without cleaning it up
+func (gsm *GameStateManager) untrackAll() []GameState {
+	gsm.mutex.Lock()
+	defer gsm.mutex.Unlock()
 
-				totalCleaned++
-				cleanedByType[gameType]++
+	var games []GameState
+	for gameType, gameMap := range gsm.gamesByType {
+		for gameID := range gameMap {
+			if g, ok := gsm.unregister(gameType, gameID); ok {
+				games = append(games, g)
 			}
 		}
 	}
-
-	// Cleanup completed silently for performance
-	// Only log if significant cleanup occurred
-	if totalCleaned > 10 {
-	}
-	_ = cleanedByType // Mark as used for build
+	return games
 }
 
-// ForceCleanupUserGames removes all games for a specific user (useful for disconnections)
+// ForceCleanupUserGames expires all games for a specific user (useful for disconnections)
 func (gsm *GameStateManager) ForceCleanupUserGames(userID int64) int {
 	gsm.mutex.Lock()
-	defer gsm.mutex.Unlock()
-
-	userGameIDs := gsm.gamesByUser[userID]
-	if len(userGameIDs) == 0 {
-		return 0
+	var games []GameState
+	for _, game := range gsm.gamesByUser[userID] {
+		games = append(games, game)
 	}
-
-	cleaned := 0
-	for _, gameID := range userGameIDs {
-		for _, gameMap := range gsm.gamesByType {
-			if game, exists := gameMap[gameID]; exists {
-				game.Cleanup()
-				delete(gameMap, gameID)
-				cleaned++
-			}
-		}
+	for _, game := range games {
+		gsm.unregister(game.GetGameType(), game.GetGameID())
 	}
+	gsm.mutex.Unlock()
 
-	// Remove user entry
-	delete(gsm.gamesByUser, userID)
+	for _, game := range games {
+		runGameCleanup(game)
+	}
 
-	return cleaned
+	return len(games)
 }
@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jackc/pgx/v5"
+)
+
+// PersistentGame is a GameState that survives restarts. The game is saved as JSON after
+// every action and decoded into its module's NewState on startup.
+type PersistentGame interface {
+	GameState
+	// GetReservation returns the stake escrowed for the game, if any
+	GetReservation() *BetReservation
+	// Resume reattaches a decoded game to the session and edits its message to show it
+	// was resumed. Returning an error refunds the game instead.
+	Resume(s *discordgo.Session) error
+}
+
+// GameResumedNotice is shown on a game's message after it has been restored
+const GameResumedNotice = "♻️ Game resumed after a bot restart."
+
+var restoreOnce sync.Once
+
+// SaveGameSnapshot persists the game's current state. Games call it after every action
+// that changes state; it is a no-op offline or for games that cannot be resumed.
+func SaveGameSnapshot(game GameState) {
+	pg, ok := game.(PersistentGame)
+	if DB == nil || !ok {
+		return
+	}
+
+	state, err := json.Marshal(pg)
+	if err != nil {
+		log.Printf("⚠️ Failed to encode %s game %s: %v", game.GetGameType(), game.GetGameID(), err)
+		return
+	}
+
+	var reservationID *int64
+	if res := pg.GetReservation(); res != nil && res.ID > 0 {
+		reservationID = &res.ID
+	}
+
+	ctx := context.Background()
+	_, err = DB.Exec(ctx, `
+		INSERT INTO game_snapshots (game_type, game_id, user_id, reservation_id, state)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (game_type, game_id) DO UPDATE
+		SET reservation_id = EXCLUDED.reservation_id, state = EXCLUDED.state, updated_at = NOW()`,
+		game.GetGameType(), game.GetGameID(), game.GetUserID(), reservationID, state)
+	if err != nil {
+		log.Printf("⚠️ Failed to save %s game %s: %v", game.GetGameType(), game.GetGameID(), err)
+	}
+}
+
+// DeleteGameSnapshot drops a finished game's snapshot
+func DeleteGameSnapshot(gameType, gameID string) {
+	if DB == nil {
+		return
+	}
+
+	ctx := context.Background()
+	if _, err := DB.Exec(ctx, `DELETE FROM game_snapshots WHERE game_type = $1 AND game_id = $2`, gameType, gameID); err != nil {
+		log.Printf("⚠️ Failed to delete %s game snapshot %s: %v", gameType, gameID, err)
+	}
+}
+
+type gameSnapshot struct {
+	gameType      string
+	gameID        string
+	userID        int64
+	reservationID *int64
+	state         []byte
+}
+
+// RestoreGames rehydrates every snapshotted game after the bot connects. Games that
+// cannot be decoded or resumed have their stake refunded. Runs once per process.
+func RestoreGames(s *discordgo.Session) {
+	restoreOnce.Do(func() {
+		resumed, refunded, err := restoreGames(s)
+		if err != nil {
+			log.Printf("⚠️ Game restore failed: %v", err)
+			return
+		}
+		if resumed+refunded > 0 {
+			log.Printf("♻️ Restored %d games, refunded %d that could not resume", resumed, refunded)
+		}
+	})
+}
+
+func restoreGames(s *discordgo.Session) (int, int, error) {
+	if DB == nil {
+		return 0, 0, nil
+	}
+
+	ctx := context.Background()
+	rows, err := DB.Query(ctx, `SELECT game_type, game_id, user_id, reservation_id, state FROM game_snapshots`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to query game snapshots: %w", err)
+	}
+
+	var snapshots []gameSnapshot
+	for rows.Next() {
+		var snap gameSnapshot
+		if err := rows.Scan(&snap.gameType, &snap.gameID, &snap.userID, &snap.reservationID, &snap.state); err != nil {
+			rows.Close()
+			return 0, 0, fmt.Errorf("failed to scan game snapshot: %w", err)
+		}
+		snapshots = append(snapshots, snap)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to iterate game snapshots: %w", err)
+	}
+
+	resumed, refunded := 0, 0
+	for _, snap := range snapshots {
+		game, err := restoreGame(s, snap)
+		if err == nil {
+			GameStateMgr.RegisterGame(game)
+			resumed++
+			continue
+		}
+
+		log.Printf("⚠️ Could not resume %s game %s for user %d: %v", snap.gameType, snap.gameID, snap.userID, err)
+		if snap.reservationID != nil {
+			ok, err := refundReservation(*snap.reservationID)
+			if err != nil {
+				log.Printf("⚠️ Failed to refund reservation %d: %v", *snap.reservationID, err)
+				continue
+			}
+			if ok {
+				refunded++
+			}
+		}
+		DeleteGameSnapshot(snap.gameType, snap.gameID)
+	}
+
+	return resumed, refunded, nil
+}
+
+// restoreGame decodes a snapshot into its module's state and resumes it
+func restoreGame(s *discordgo.Session, snap gameSnapshot) (GameState, error) {
+	module, ok := GetGameModule(snap.gameType)
+	if !ok || module.NewState == nil {
+		return nil, fmt.Errorf("game type cannot be resumed")
+	}
+
+	game, ok := module.NewState().(PersistentGame)
+	if !ok {
+		return nil, fmt.Errorf("game type cannot be resumed")
+	}
+	if err := json.Unmarshal(snap.state, game); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	// The stake must still be in escrow; otherwise the game already settled
+	if res := game.GetReservation(); res != nil {
+		if err := syncReservation(res); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := game.Resume(s); err != nil {
+		return nil, fmt.Errorf("failed to resume: %w", err)
+	}
+	return game, nil
+}
+
+// syncReservation reloads a decoded reservation's amount and status from the database
+func syncReservation(r *BetReservation) error {
+	ctx := context.Background()
+	err := DB.QueryRow(ctx, `SELECT amount, status FROM bet_reservations WHERE id = $1`, r.ID).Scan(&r.Amount, &r.Status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("reservation %d not found", r.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load reservation: %w", err)
+	}
+	if r.Status != ReservationHeld {
+		return ErrReservationClosed
+	}
+	return nil
+}
+
+// refundReservation refunds a reservation by ID if it is still held, reporting whether
+// anything was refunded
+func refundReservation(id int64) (bool, error) {
+	r := &BetReservation{ID: id}
+	ctx := context.Background()
+	err := DB.QueryRow(ctx, `
+		SELECT user_id, game_type, COALESCE(game_id, ''), amount, status, created_at
+		FROM bet_reservations WHERE id = $1`, id).
+		Scan(&r.UserID, &r.GameType, &r.GameID, &r.Amount, &r.Status, &r.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load reservation: %w", err)
+	}
+	if r.Status != ReservationHeld {
+		return false, nil
+	}
+
+	if _, err := RefundBet(r); err != nil {
+		return false, err
+	}
+	return true, nil
+}
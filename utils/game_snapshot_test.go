@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFairRoundSnapshotResumesDraws(t *testing.T) {
+	round := NewFairRound(42, "craps", "game-1", nil)
+	src := round.Source()
+	for i := 0; i < 5; i++ {
+		src.Intn(6)
+	}
+
+	data, err := json.Marshal(&BaseGame{UserID: 42, GameType: "craps", GameID: "game-1", Round: round})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var restored BaseGame
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if restored.Round.ServerSeedHash != round.ServerSeedHash || restored.Round.Nonce != round.Nonce {
+		t.Fatalf("round commitment changed across snapshot")
+	}
+
+	resumed := restored.FairSource()
+	for i := 0; i < 20; i++ {
+		if want, got := src.Intn(6), resumed.Intn(6); want != got {
+			t.Fatalf("draw %d after restore diverged: %d != %d", i, got, want)
+		}
+	}
+}
+
+func TestFairRoundSnapshotOmitsServerSeed(t *testing.T) {
+	round := NewFairRound(42, "slots", "game-2", nil)
+	round.ID = 7
+	src := round.Source()
+	src.Intn(10)
+
+	data, err := json.Marshal(round)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(data), round.ServerSeed) {
+		t.Fatal("snapshot of a recorded round contains its unrevealed server seed")
+	}
+
+	var restored FairRound
+	lookup := func(id int64) (string, error) {
+		if id != 7 {
+			t.Errorf("looked up round %d, want 7", id)
+		}
+		return round.ServerSeed, nil
+	}
+	if err := restored.decodeSnapshot(data, lookup); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if want, got := src.Intn(10), restored.Source().Intn(10); want != got {
+		t.Errorf("draw after restore = %d, want %d", got, want)
+	}
+
+	wrong := func(int64) (string, error) { return "not-the-seed", nil }
+	if err := new(FairRound).decodeSnapshot(data, wrong); err == nil {
+		t.Error("a seed that doesn't match the committed hash should not restore")
+	}
+}
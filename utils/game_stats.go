@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GameStats is a user's lifetime record in one game type
+type GameStats struct {
+	UserID         int64
+	GameType       string
+	GamesPlayed    int64
+	Wins           int64
+	Losses         int64
+	TotalWagered   int64
+	TotalWon       int64 // total paid back to the player, stakes included
+	BiggestWin     int64
+	BiggestLoss    int64
+	CurrentStreak  int // positive for consecutive wins, negative for consecutive losses
+	BestWinStreak  int
+	BestLossStreak int
+	UpdatedAt      time.Time
+}
+
+var gameDisplayNames = map[string]string{
+	"blackjack":        "Blackjack",
+	"baccarat":         "Baccarat",
+	"craps":            "Craps",
+	"derby":            "Derby",
+	"higher_or_lower":  "Higher or Lower",
+	"mines":            "Mines",
+	"roulette":         "Roulette",
+	"slots":            "Slots",
+	"three_card_poker": "Three Card Poker",
+}
+
+// IsGameType reports whether gameType names one of the casino's games
+func IsGameType(gameType string) bool {
+	_, ok := gameDisplayNames[gameType]
+	return ok
+}
+
+// GameDisplayName returns the human-readable name of a game type
+func GameDisplayName(gameType string) string {
+	if name, ok := gameDisplayNames[gameType]; ok {
+		return name
+	}
+	return gameType
+}
+
+// NetProfit is everything won minus everything wagered
+func (gs *GameStats) NetProfit() int64 {
+	return gs.TotalWon - gs.TotalWagered
+}
+
+// WinRate is the share of decided games that were won, as a percentage
+func (gs *GameStats) WinRate() float64 {
+	if gs.Wins+gs.Losses == 0 {
+		return 0
+	}
+	return float64(gs.Wins) * 100 / float64(gs.Wins+gs.Losses)
+}
+
+// apply folds one finished game into the stats. Pushes count as played without
+// touching the streak.
+func (gs *GameStats) apply(wagered, payout int64) {
+	profit := payout - wagered
+	gs.GamesPlayed++
+	gs.TotalWagered += wagered
+	gs.TotalWon += payout
+
+	switch {
+	case profit > 0:
+		gs.Wins++
+		if profit > gs.BiggestWin {
+			gs.BiggestWin = profit
+		}
+		if gs.CurrentStreak > 0 {
+			gs.CurrentStreak++
+		} else {
+			gs.CurrentStreak = 1
+		}
+		if gs.CurrentStreak > gs.BestWinStreak {
+			gs.BestWinStreak = gs.CurrentStreak
+		}
+	case profit < 0:
+		gs.Losses++
+		if -profit > gs.BiggestLoss {
+			gs.BiggestLoss = -profit
+		}
+		if gs.CurrentStreak < 0 {
+			gs.CurrentStreak--
+		} else {
+			gs.CurrentStreak = -1
+		}
+		if -gs.CurrentStreak > gs.BestLossStreak {
+			gs.BestLossStreak = -gs.CurrentStreak
+		}
+	}
+}
+
+const gameStatsColumns = `user_id, game_type, games_played, wins, losses, total_wagered, total_won,
+	biggest_win, biggest_loss, current_streak, best_win_streak, best_loss_streak, updated_at`
+
+func scanGameStats(row pgx.Row, gs *GameStats) error {
+	return row.Scan(&gs.UserID, &gs.GameType, &gs.GamesPlayed, &gs.Wins, &gs.Losses, &gs.TotalWagered, &gs.TotalWon,
+		&gs.BiggestWin, &gs.BiggestLoss, &gs.CurrentStreak, &gs.BestWinStreak, &gs.BestLossStreak, &gs.UpdatedAt)
+}
+
+// recordGameStatsInTx adds a settled stake to the user's per-game stats inside the
+// settlement transaction, so stats and balance can never disagree
+func recordGameStatsInTx(ctx context.Context, tx pgx.Tx, userID int64, gameType string, wagered, payout int64) error {
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO user_game_stats (user_id, game_type) VALUES ($1, $2)
+		ON CONFLICT (user_id, game_type) DO NOTHING`,
+		userID, gameType); err != nil {
+		return fmt.Errorf("failed to record game stats: %w", err)
+	}
+
+	var gs GameStats
+	row := tx.QueryRow(ctx, `SELECT `+gameStatsColumns+` FROM user_game_stats WHERE user_id = $1 AND game_type = $2 FOR UPDATE`, userID, gameType)
+	if err := scanGameStats(row, &gs); err != nil {
+		return fmt.Errorf("failed to load game stats: %w", err)
+	}
+	gs.apply(wagered, payout)
+
+	_, err := tx.Exec(ctx, `
+		UPDATE user_game_stats
+		SET games_played = $3, wins = $4, losses = $5, total_wagered = $6, total_won = $7,
+			biggest_win = $8, biggest_loss = $9, current_streak = $10, best_win_streak = $11,
+			best_loss_streak = $12, updated_at = NOW()
+		WHERE user_id = $1 AND game_type = $2`,
+		userID, gameType, gs.GamesPlayed, gs.Wins, gs.Losses, gs.TotalWagered, gs.TotalWon,
+		gs.BiggestWin, gs.BiggestLoss, gs.CurrentStreak, gs.BestWinStreak, gs.BestLossStreak)
+	if err != nil {
+		return fmt.Errorf("failed to record game stats: %w", err)
+	}
+	return nil
+}
+
+// GetGameStats returns a user's stats for one game type, zeroed if they never played it
+func GetGameStats(userID int64, gameType string) (*GameStats, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	gs := &GameStats{UserID: userID, GameType: gameType}
+	ctx := context.Background()
+	row := DB.QueryRow(ctx, `SELECT `+gameStatsColumns+` FROM user_game_stats WHERE user_id = $1 AND game_type = $2`, userID, gameType)
+	if err := scanGameStats(row, gs); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get game stats: %w", err)
+	}
+	return gs, nil
+}
+
+// GetAllGameStats returns a user's stats for every game type they have played,
+// most played first
+func GetAllGameStats(userID int64) ([]*GameStats, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx := context.Background()
+	rows, err := DB.Query(ctx, `
+		SELECT `+gameStatsColumns+` FROM user_game_stats
+		WHERE user_id = $1 AND games_played > 0
+		ORDER BY games_played DESC, game_type`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []*GameStats
+	for rows.Next() {
+		gs := &GameStats{}
+		if err := scanGameStats(rows, gs); err != nil {
+			return nil, fmt.Errorf("failed to scan game stats: %w", err)
+		}
+		stats = append(stats, gs)
+	}
+	return stats, rows.Err()
+}
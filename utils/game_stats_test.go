@@ -0,0 +1,24 @@
+package utils
+
+import "testing"
+
+func TestGameStatsApplyTracksStreaks(t *testing.T) {
+	var gs GameStats
+	for _, payout := range []int64{200, 300, 100, 0, 0, 0, 100, 250} {
+		gs.apply(100, payout)
+	}
+
+	if gs.GamesPlayed != 8 || gs.Wins != 3 || gs.Losses != 3 {
+		t.Fatalf("played=%d wins=%d losses=%d", gs.GamesPlayed, gs.Wins, gs.Losses)
+	}
+	if gs.TotalWagered != 800 || gs.TotalWon != 950 || gs.NetProfit() != 150 {
+		t.Fatalf("wagered=%d won=%d net=%d", gs.TotalWagered, gs.TotalWon, gs.NetProfit())
+	}
+	if gs.BiggestWin != 200 || gs.BiggestLoss != 100 {
+		t.Fatalf("biggest win=%d loss=%d", gs.BiggestWin, gs.BiggestLoss)
+	}
+	// The push before the last win leaves the streak where the losses put it
+	if gs.CurrentStreak != 1 || gs.BestWinStreak != 2 || gs.BestLossStreak != 3 {
+		t.Fatalf("streak=%d best win=%d best loss=%d", gs.CurrentStreak, gs.BestWinStreak, gs.BestLossStreak)
+	}
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// guildMemberRefresh is how often a member already seen in a guild is written again
+const guildMemberRefresh = time.Hour
+
+// GuildMemberExpiry is how long a member stays on a server's leaderboards after their
+// last interaction there when leaves are not reported by the gateway
+const GuildMemberExpiry = 30 * 24 * time.Hour
+
+// memberSightings remembers who was recently written to guild_members so repeat
+// interactions skip the database. Entries older than guildMemberRefresh are swept out.
+type memberSightings struct {
+	sync.Mutex
+	at        map[[2]int64]time.Time
+	lastSweep time.Time
+}
+
+var seenGuildMembers = &memberSightings{at: make(map[[2]int64]time.Time)}
+
+// note records a sighting and reports whether it should be written to the database.
+// Stale entries are swept at most once per refresh period.
+func (m *memberSightings) note(key [2]int64, now time.Time) bool {
+	m.Lock()
+	defer m.Unlock()
+	if now.Sub(m.lastSweep) >= guildMemberRefresh {
+		for k, last := range m.at {
+			if now.Sub(last) >= guildMemberRefresh {
+				delete(m.at, k)
+			}
+		}
+		m.lastSweep = now
+	}
+	if last, ok := m.at[key]; ok && now.Sub(last) < guildMemberRefresh {
+		return false
+	}
+	m.at[key] = now
+	return true
+}
+
+func (m *memberSightings) forget(key [2]int64) {
+	m.Lock()
+	delete(m.at, key)
+	m.Unlock()
+}
+
+// TrackGuildMember records that a user belongs to a guild, for server-scoped leaderboards.
+// Repeat sightings within guildMemberRefresh skip the database.
+func TrackGuildMember(guildID, userID int64) error {
+	if DB == nil || guildID == 0 || userID == 0 {
+		return nil
+	}
+
+	key := [2]int64{guildID, userID}
+	if !seenGuildMembers.note(key, time.Now()) {
+		return nil
+	}
+
+	ctx := context.Background()
+	_, err := DB.Exec(ctx, `
+		INSERT INTO guild_members (guild_id, user_id) VALUES ($1, $2)
+		ON CONFLICT (guild_id, user_id) DO UPDATE SET seen_at = NOW()`, guildID, userID)
+	if err != nil {
+		seenGuildMembers.forget(key)
+		return fmt.Errorf("failed to track guild member: %w", err)
+	}
+	return nil
+}
+
+// RemoveGuildMember forgets a user who left a guild
+func RemoveGuildMember(guildID, userID int64) error {
+	seenGuildMembers.forget([2]int64{guildID, userID})
+	if DB == nil {
+		return nil
+	}
+
+	ctx := context.Background()
+	if _, err := DB.Exec(ctx, `DELETE FROM guild_members WHERE guild_id = $1 AND user_id = $2`, guildID, userID); err != nil {
+		return fmt.Errorf("failed to remove guild member: %w", err)
+	}
+	return nil
+}
+
+// ExpireGuildMembers forgets members not seen in a guild since maxAge ago
+func ExpireGuildMembers(maxAge time.Duration) (int64, error) {
+	if DB == nil {
+		return 0, nil
+	}
+
+	ctx := context.Background()
+	tag, err := DB.Exec(ctx, `DELETE FROM guild_members WHERE seen_at < $1`, time.Now().Add(-maxAge))
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire guild members: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+var guildMemberExpiryOnce sync.Once
+
+// StartGuildMemberExpiry expires members every interval. Without the guild members
+// intent leaves are never reported, so this is what takes departed members off server
+// leaderboards. Safe to call on every reconnect; only the first call starts the loop.
+func StartGuildMemberExpiry(interval time.Duration) {
+	if DB == nil {
+		return
+	}
+	guildMemberExpiryOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for ; ; <-ticker.C {
+				if n, err := ExpireGuildMembers(GuildMemberExpiry); err != nil {
+					log.Printf("⚠️ %v", err)
+				} else if n > 0 {
+					log.Printf("🧹 Expired %d guild members not seen in %s", n, GuildMemberExpiry)
+				}
+			}
+		}()
+	})
+}
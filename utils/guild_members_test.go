@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemberSightingsSkipRepeatsAndSweep(t *testing.T) {
+	m := &memberSightings{at: make(map[[2]int64]time.Time)}
+	now := time.Now()
+	a, b := [2]int64{1, 10}, [2]int64{1, 20}
+
+	if !m.note(a, now) || !m.note(b, now) {
+		t.Fatal("first sightings should be written")
+	}
+	if m.note(a, now.Add(time.Minute)) {
+		t.Error("repeat sighting within the refresh period was written")
+	}
+	if !m.note(a, now.Add(guildMemberRefresh)) {
+		t.Error("sighting after the refresh period was not written")
+	}
+	// b was not seen again, so the sweep that ran with the last note dropped it
+	if _, ok := m.at[b]; ok || len(m.at) != 1 {
+		t.Errorf("stale sightings were kept: %v", m.at)
+	}
+}
@@ -0,0 +1,342 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jackc/pgx/v5"
+)
+
+// DefaultBigWinThreshold is the profit a round needs before it is announced
+const DefaultBigWinThreshold int64 = 100000
+
+// GuildLocales are the languages a server can choose with /config locale
+var GuildLocales = []discordgo.Locale{
+	discordgo.EnglishUS,
+	discordgo.EnglishGB,
+	discordgo.German,
+	discordgo.French,
+	discordgo.SpanishES,
+	discordgo.PortugueseBR,
+}
+
+// bigWinMessages is the big-win announcement in each server language: the title, then
+// a format taking the player, profit, game and bet
+var bigWinMessages = map[discordgo.Locale][2]string{
+	discordgo.EnglishUS:    {"🎉 Big Win!", "<@%d> just won **%s** chips on **%s** with a **%s** chip bet!"},
+	discordgo.EnglishGB:    {"🎉 Big Win!", "<@%d> just won **%s** chips on **%s** with a **%s** chip bet!"},
+	discordgo.German:       {"🎉 Großer Gewinn!", "<@%d> hat gerade **%s** Chips bei **%s** mit einem Einsatz von **%s** Chips gewonnen!"},
+	discordgo.French:       {"🎉 Gros gain !", "<@%d> vient de gagner **%s** jetons au **%s** avec une mise de **%s** jetons !"},
+	discordgo.SpanishES:    {"🎉 ¡Gran premio!", "¡<@%d> acaba de ganar **%s** fichas en **%s** con una apuesta de **%s** fichas!"},
+	discordgo.PortugueseBR: {"🎉 Grande vitória!", "<@%d> acabou de ganhar **%s** fichas em **%s** com uma aposta de **%s** fichas!"},
+}
+
+// ErrGuildRule is matched by every error a server's casino settings return
+var ErrGuildRule = errors.New("blocked by server settings")
+
+// GuildRuleError is returned when a server's settings stop a game or bet
+type GuildRuleError struct {
+	Message string
+}
+
+func (e *GuildRuleError) Error() string { return e.Message }
+
+func (e *GuildRuleError) Unwrap() error { return ErrGuildRule }
+
+// BetLimit bounds a single bet on one game; zero leaves that side unbounded
+type BetLimit struct {
+	Min int64 `yaml:"min"`
+	Max int64 `yaml:"max"`
+}
+
+// GuildSettings is a server's casino configuration. Settings returned by
+// GetGuildSettings are shared and must not be modified; use UpdateGuildSettings.
+type GuildSettings struct {
+	GuildID           int64
+	DisabledGames     []string
+	AllowedChannels   []int64 // empty allows every channel
+	BetLimits         map[string]BetLimit
+	AnnounceChannelID int64 // 0 turns big-win announcements off
+	BigWinThreshold   int64
+	Locale            discordgo.Locale
+}
+
+// DefaultGuildSettings allows every game in every channel with no extra bet limits
+func DefaultGuildSettings(guildID int64) *GuildSettings {
+	return &GuildSettings{
+		GuildID:         guildID,
+		BetLimits:       make(map[string]BetLimit),
+		BigWinThreshold: DefaultBigWinThreshold,
+		Locale:          discordgo.EnglishUS,
+	}
+}
+
+// BigWinMessage returns the title and text announcing a big win in the server's language
+func (g *GuildSettings) BigWinMessage(userID, profit int64, gameType string, bet int64) (string, string) {
+	msg, ok := bigWinMessages[g.Locale]
+	if !ok {
+		msg = bigWinMessages[discordgo.EnglishUS]
+	}
+	return msg[0], fmt.Sprintf(msg[1], userID, FormatChips(profit), GameDisplayName(gameType), FormatChips(bet))
+}
+
+// GameEnabled reports whether a game may be played on the server
+func (g *GuildSettings) GameEnabled(gameType string) bool {
+	return !slices.Contains(g.DisabledGames, gameType)
+}
+
+// ChannelAllowed reports whether games may be played in a channel
+func (g *GuildSettings) ChannelAllowed(channelID int64) bool {
+	return len(g.AllowedChannels) == 0 || slices.Contains(g.AllowedChannels, channelID)
+}
+
+// CheckBet applies the server's minimum and maximum bet for a game
+func (g *GuildSettings) CheckBet(gameType string, amount int64) error {
+	limit, ok := g.BetLimits[gameType]
+	if !ok {
+		return nil
+	}
+	if limit.Min > 0 && amount < limit.Min {
+		return &GuildRuleError{fmt.Sprintf("The minimum bet for %s on this server is **%s** chips.", GameDisplayName(gameType), FormatChips(limit.Min))}
+	}
+	if limit.Max > 0 && amount > limit.Max {
+		return &GuildRuleError{fmt.Sprintf("The maximum bet for %s on this server is **%s** chips.", GameDisplayName(gameType), FormatChips(limit.Max))}
+	}
+	return nil
+}
+
+type guildSettingsCacheEntry struct {
+	settings *GuildSettings
+	loadedAt time.Time
+}
+
+var guildSettingsCache = struct {
+	sync.RWMutex
+	byGuild map[int64]guildSettingsCacheEntry
+}{byGuild: make(map[int64]guildSettingsCacheEntry)}
+
+// GetGuildSettings returns a server's settings, or the defaults if it has none
+func GetGuildSettings(guildID int64) (*GuildSettings, error) {
+	if DB == nil || guildID == 0 {
+		return DefaultGuildSettings(guildID), nil
+	}
+
+	guildSettingsCache.RLock()
+	entry, ok := guildSettingsCache.byGuild[guildID]
+	guildSettingsCache.RUnlock()
+	if ok && time.Since(entry.loadedAt) < restrictionCacheTTL {
+		return entry.settings, nil
+	}
+
+	ctx := context.Background()
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	settings, err := loadGuildSettings(ctx, tx, guildID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	guildSettingsCache.Lock()
+	guildSettingsCache.byGuild[guildID] = guildSettingsCacheEntry{settings: settings, loadedAt: time.Now()}
+	guildSettingsCache.Unlock()
+	return settings, nil
+}
+
+// lastGuildSettings returns the cached settings for a server however stale, or nil
+func lastGuildSettings(guildID int64) *GuildSettings {
+	guildSettingsCache.RLock()
+	defer guildSettingsCache.RUnlock()
+	return guildSettingsCache.byGuild[guildID].settings
+}
+
+// UpdateGuildSettings applies change to a server's settings under a row lock and saves
+// the result. Returning an error from change leaves the settings untouched.
+func UpdateGuildSettings(guildID int64, change func(*GuildSettings) error) (*GuildSettings, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx := context.Background()
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO guild_settings (guild_id) VALUES ($1)
+		ON CONFLICT (guild_id) DO NOTHING`, guildID); err != nil {
+		return nil, fmt.Errorf("failed to create guild settings: %w", err)
+	}
+	settings, err := loadGuildSettings(ctx, tx, guildID, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(settings); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE guild_settings SET disabled_games = $2, allowed_channels = $3, announce_channel_id = $4,
+			big_win_threshold = $5, locale = $6, updated_at = NOW()
+		WHERE guild_id = $1`,
+		guildID, settings.DisabledGames, settings.AllowedChannels, settings.AnnounceChannelID,
+		settings.BigWinThreshold, string(settings.Locale)); err != nil {
+		return nil, fmt.Errorf("failed to save guild settings: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM guild_bet_limits WHERE guild_id = $1`, guildID); err != nil {
+		return nil, fmt.Errorf("failed to save guild bet limits: %w", err)
+	}
+	for game, limit := range settings.BetLimits {
+		if limit.Min == 0 && limit.Max == 0 {
+			continue
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO guild_bet_limits (guild_id, game_type, min_bet, max_bet) VALUES ($1, $2, $3, $4)`,
+			guildID, game, limit.Min, limit.Max); err != nil {
+			return nil, fmt.Errorf("failed to save guild bet limits: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit guild settings: %w", err)
+	}
+
+	guildSettingsCache.Lock()
+	guildSettingsCache.byGuild[guildID] = guildSettingsCacheEntry{settings: settings, loadedAt: time.Now()}
+	guildSettingsCache.Unlock()
+	return settings, nil
+}
+
+// loadGuildSettings reads a server's settings and bet limits, locking the row if forUpdate
+func loadGuildSettings(ctx context.Context, tx pgx.Tx, guildID int64, forUpdate bool) (*GuildSettings, error) {
+	settings := DefaultGuildSettings(guildID)
+	query := `
+		SELECT disabled_games, allowed_channels, announce_channel_id, big_win_threshold, locale
+		FROM guild_settings WHERE guild_id = $1`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+	var locale string
+	err := tx.QueryRow(ctx, query, guildID).Scan(&settings.DisabledGames, &settings.AllowedChannels,
+		&settings.AnnounceChannelID, &settings.BigWinThreshold, &locale)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guild settings: %w", err)
+	}
+	settings.Locale = discordgo.Locale(locale)
+
+	rows, err := tx.Query(ctx, `SELECT game_type, min_bet, max_bet FROM guild_bet_limits WHERE guild_id = $1`, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guild bet limits: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var game string
+		var limit BetLimit
+		if err := rows.Scan(&game, &limit.Min, &limit.Max); err != nil {
+			return nil, fmt.Errorf("failed to scan guild bet limit: %w", err)
+		}
+		settings.BetLimits[game] = limit
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get guild bet limits: %w", err)
+	}
+	return settings, nil
+}
+
+// ErrGuildSettingsUnavailable is the rule shown when a server's settings can't be loaded
+// and none were cached, so its disabled games and channels can't be honoured
+var ErrGuildSettingsUnavailable = &GuildRuleError{"⚠️ Couldn't load this server's casino settings right now, please try again in a moment."}
+
+// CheckGuildGame applies the house bet limits and a server's settings to a game about to
+// be played in a channel. A zero bet skips the bet limits. DMs are only held to the house
+// limits. If the settings can't be loaded the last cached copy is used, and with none the
+// game is refused.
+func CheckGuildGame(guildID, channelID, gameType string, bet int64) error {
+	return checkGuildGame(guildID, channelID, gameType, bet, GetGuildSettings)
+}
+
+func checkGuildGame(guildID, channelID, gameType string, bet int64, lookup func(int64) (*GuildSettings, error)) error {
+	if bet > 0 {
+		if err := Games.CheckBet(gameType, bet); err != nil {
+			return err
+		}
+	}
+	gid, err := strconv.ParseInt(guildID, 10, 64)
+	if err != nil || gid == 0 {
+		return nil
+	}
+	settings, err := lookup(gid)
+	if err != nil {
+		log.Printf("Error checking guild settings for %d: %v", gid, err)
+		if settings = lastGuildSettings(gid); settings == nil {
+			return ErrGuildSettingsUnavailable
+		}
+	}
+
+	if !settings.GameEnabled(gameType) {
+		return &GuildRuleError{fmt.Sprintf("🚫 %s is disabled on this server.", GameDisplayName(gameType))}
+	}
+	cid, _ := strconv.ParseInt(channelID, 10, 64)
+	if !settings.ChannelAllowed(cid) {
+		msg := "🎰 The casino isn't open in this channel."
+		if len(settings.AllowedChannels) > 0 {
+			msg += fmt.Sprintf(" Try <#%d>.", settings.AllowedChannels[0])
+		}
+		return &GuildRuleError{msg}
+	}
+	if bet > 0 {
+		return settings.CheckBet(gameType, bet)
+	}
+	return nil
+}
+
+// GateGuildGame replies ephemerally and returns false if the server has disabled a game
+// or closed the channel to the casino
+func GateGuildGame(s *discordgo.Session, i *discordgo.InteractionCreate, gameType string) bool {
+	if gameType == "" {
+		return true
+	}
+	if err := CheckGuildGame(i.GuildID, i.ChannelID, gameType, 0); err != nil {
+		SendInteractionResponse(s, i, CreateBrandedEmbed("Not Available Here", err.Error(), 0xE74C3C), nil, true)
+		return false
+	}
+	return true
+}
+
+// announceBigWin posts a round's profit to the server's announcement channel if it
+// clears the server's big-win threshold
+func announceBigWin(session *discordgo.Session, interaction *discordgo.InteractionCreate, r *BetReservation, profit int64) {
+	if session == nil || interaction == nil || interaction.GuildID == "" || profit <= 0 {
+		return
+	}
+	gid, err := strconv.ParseInt(interaction.GuildID, 10, 64)
+	if err != nil {
+		return
+	}
+	settings, err := GetGuildSettings(gid)
+	if err != nil || settings.AnnounceChannelID == 0 || profit < settings.BigWinThreshold {
+		return
+	}
+
+	title, text := settings.BigWinMessage(r.UserID, profit, r.GameType, r.Amount)
+	embed := CreateBrandedEmbed(title, text, 0xF1C40F)
+	go func() {
+		if _, err := session.ChannelMessageSendEmbed(strconv.FormatInt(settings.AnnounceChannelID, 10), embed); err != nil {
+			log.Printf("Error announcing big win in guild %d: %v", gid, err)
+		}
+	}()
+}
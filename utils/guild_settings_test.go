@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestGuildSettingsRules(t *testing.T) {
+	g := DefaultGuildSettings(1)
+	if !g.GameEnabled("slots") || !g.ChannelAllowed(42) {
+		t.Fatal("default settings should allow every game in every channel")
+	}
+
+	g.DisabledGames = []string{"slots"}
+	g.AllowedChannels = []int64{7}
+	if g.GameEnabled("slots") || !g.GameEnabled("mines") {
+		t.Error("only slots should be disabled")
+	}
+	if g.ChannelAllowed(42) || !g.ChannelAllowed(7) {
+		t.Error("only channel 7 should be allowed")
+	}
+
+	g.BetLimits["mines"] = BetLimit{Min: 100, Max: 5000}
+	for _, tc := range []struct {
+		bet  int64
+		want bool
+	}{{50, false}, {100, true}, {5000, true}, {5001, false}} {
+		err := g.CheckBet("mines", tc.bet)
+		if (err == nil) != tc.want {
+			t.Errorf("CheckBet(%d) = %v, want allowed %v", tc.bet, err, tc.want)
+		}
+		if err != nil && !IsPlayBlocked(err) {
+			t.Errorf("CheckBet(%d) error should be shown to the player", tc.bet)
+		}
+	}
+	if err := g.CheckBet("slots", 1); err != nil {
+		t.Errorf("games without limits should accept any bet: %v", err)
+	}
+}
+
+func TestHouseBetLimitsApplyInDMs(t *testing.T) {
+	saved := Games
+	defer func() { Games = saved }()
+	Games = GameConfig{BetLimits: map[string]BetLimit{"slots": {Max: 1000}}}
+
+	err := CheckGuildGame("", "", "slots", 5000)
+	if !errors.Is(err, ErrHouseLimit) || !IsPlayBlocked(err) {
+		t.Errorf("bet over the house limit = %v, want a house limit error", err)
+	}
+	if err := CheckGuildGame("", "", "slots", 1000); err != nil {
+		t.Errorf("bet at the house limit = %v", err)
+	}
+	if Games.Timeout("slots") != defaultGameTimeout {
+		t.Error("games without a timeout should use the default")
+	}
+}
+
+func TestBigWinMessageUsesServerLocale(t *testing.T) {
+	g := DefaultGuildSettings(1)
+	for _, locale := range GuildLocales {
+		if _, ok := bigWinMessages[locale]; !ok {
+			t.Errorf("no big-win announcement for %s", locale)
+		}
+	}
+
+	g.Locale = discordgo.German
+	title, text := g.BigWinMessage(42, 250000, "slots", 5000)
+	if title != "🎉 Großer Gewinn!" || !strings.Contains(text, "<@42>") || !strings.Contains(text, FormatChips(250000)) {
+		t.Errorf("German announcement = %q, %q", title, text)
+	}
+
+	g.Locale = "xx"
+	if title, _ := g.BigWinMessage(42, 1, "slots", 1); title != "🎉 Big Win!" {
+		t.Errorf("unknown locale should fall back to English, got %q", title)
+	}
+}
+
+func TestGuildGameFallsBackToCachedSettings(t *testing.T) {
+	const gid = 987654321
+	defer func() {
+		guildSettingsCache.Lock()
+		delete(guildSettingsCache.byGuild, gid)
+		guildSettingsCache.Unlock()
+	}()
+	broken := func(int64) (*GuildSettings, error) { return nil, errors.New("connection refused") }
+
+	err := checkGuildGame("987654321", "1", "slots", 0, broken)
+	if !errors.Is(err, ErrGuildRule) || !IsPlayBlocked(err) {
+		t.Fatalf("lookup failure with nothing cached = %v, want the game refused", err)
+	}
+
+	cached := DefaultGuildSettings(gid)
+	cached.DisabledGames = []string{"slots"}
+	guildSettingsCache.Lock()
+	guildSettingsCache.byGuild[gid] = guildSettingsCacheEntry{settings: cached}
+	guildSettingsCache.Unlock()
+
+	if err := checkGuildGame("987654321", "1", "slots", 0, broken); err == nil || err == ErrGuildSettingsUnavailable {
+		t.Errorf("stale cached settings should still disable slots, got %v", err)
+	}
+	if err := checkGuildGame("987654321", "1", "mines", 0, broken); err != nil {
+		t.Errorf("stale cached settings should allow mines, got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Probe results reported by /readyz and /livez
+const (
+	ProbeOK      = "ok"
+	ProbeFailed  = "fail"
+	ProbeSkipped = "skipped" // the subsystem is not configured
+)
+
+// Readiness thresholds
+const (
+	MaxHeartbeatAge      = 90 * time.Second // gateway heartbeats are acked roughly every 41s
+	MaxCacheCleanupAge   = 5 * time.Minute  // the cache cleanup ticks every 90s
+	MaxTaskQueueFill     = 0.9              // share of the async queue that may be backlogged
+	discordProbeInterval = 30 * time.Second // how long a Discord API probe result is reused
+)
+
+// MaxGatewayStall is how long the gateway may go without a heartbeat ack before the
+// process is considered stuck. It is well past MaxHeartbeatAge so discordgo has time to
+// reconnect on its own first.
+const MaxGatewayStall = 5 * time.Minute
+
+// ProbeResult is the outcome of one readiness check
+type ProbeResult struct {
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ReadinessReport is the /readyz and /livez body; Status is ProbeOK only if no check failed
+type ReadinessReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]ProbeResult `json:"checks"`
+}
+
+// Ready reports whether every check passed or was skipped
+func (r ReadinessReport) Ready() bool {
+	return r.Status == ProbeOK
+}
+
+// CheckReadiness runs every readiness check against the live subsystems
+func CheckReadiness(ctx context.Context, session *discordgo.Session) ReadinessReport {
+	checks := map[string]func() ProbeResult{
+		"database":    func() ProbeResult { return probeDatabase(ctx) },
+		"gateway":     func() ProbeResult { return probeGateway(session, time.Now()) },
+		"discord_api": func() ProbeResult { return probeDiscordAPI(session) },
+		"task_queue":  probeTaskQueue,
+		"user_cache":  func() ProbeResult { return probeUserCache(time.Now()) },
+	}
+
+	report := ReadinessReport{Status: ProbeOK, Checks: make(map[string]ProbeResult, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := check()
+			mu.Lock()
+			report.Checks[name] = result
+			if result.Status == ProbeFailed {
+				report.Status = ProbeFailed
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if ShuttingDown() {
+		report.Checks["shutdown"] = ProbeResult{Status: ProbeFailed, Detail: "shutting down"}
+		report.Status = ProbeFailed
+	}
+	return report
+}
+
+// CheckLiveness reports whether the process is stuck and needs a restart: the gateway
+// stopped acking heartbeats after it had connected, or the cache cleanup loop stopped
+// ticking. Outages a restart would not fix, like the database, are left to readiness.
+func CheckLiveness(session *discordgo.Session, now time.Time) ReadinessReport {
+	report := ReadinessReport{Status: ProbeOK, Checks: map[string]ProbeResult{
+		"gateway":    probeGatewayStall(session, now),
+		"user_cache": probeUserCache(now),
+	}}
+	for _, result := range report.Checks {
+		if result.Status == ProbeFailed {
+			report.Status = ProbeFailed
+		}
+	}
+	return report
+}
+
+// probeGatewayStall fails once heartbeat acks have stopped for MaxGatewayStall. A gateway
+// that has not connected yet is skipped so a slow startup is not restarted.
+func probeGatewayStall(session *discordgo.Session, now time.Time) ProbeResult {
+	if session == nil {
+		return ProbeResult{Status: ProbeSkipped, Detail: "no Discord session"}
+	}
+	session.RLock()
+	lastAck := session.LastHeartbeatAck
+	session.RUnlock()
+
+	if lastAck.IsZero() {
+		return ProbeResult{Status: ProbeSkipped, Detail: "gateway has not connected"}
+	}
+	if age := now.Sub(lastAck); age > MaxGatewayStall {
+		return ProbeResult{Status: ProbeFailed, Detail: fmt.Sprintf("last heartbeat ack %s ago", age.Round(time.Second))}
+	}
+	return ProbeResult{Status: ProbeOK}
+}
+
+func probeDatabase(ctx context.Context) ProbeResult {
+	if DB == nil {
+		if !dbConfigured {
+			return ProbeResult{Status: ProbeSkipped, Detail: "no database configured"}
+		}
+		return ProbeResult{Status: ProbeFailed, Detail: "database not connected"}
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	if err := DB.Ping(ctx); err != nil {
+		return ProbeResult{Status: ProbeFailed, Detail: err.Error()}
+	}
+	return ProbeResult{Status: ProbeOK}
+}
+
+// probeGateway fails until the first heartbeat ack and whenever acks stop arriving
+func probeGateway(session *discordgo.Session, now time.Time) ProbeResult {
+	if session == nil {
+		return ProbeResult{Status: ProbeFailed, Detail: "no Discord session"}
+	}
+	session.RLock()
+	lastAck, lastSent := session.LastHeartbeatAck, session.LastHeartbeatSent
+	session.RUnlock()
+
+	if lastAck.IsZero() || lastSent.IsZero() {
+		return ProbeResult{Status: ProbeFailed, Detail: "gateway has not connected"}
+	}
+	if age := now.Sub(lastAck); age > MaxHeartbeatAge {
+		return ProbeResult{Status: ProbeFailed, Detail: fmt.Sprintf("last heartbeat ack %s ago", age.Round(time.Second))}
+	}
+	return ProbeResult{Status: ProbeOK, Detail: fmt.Sprintf("heartbeat latency %dms", session.HeartbeatLatency().Milliseconds())}
+}
+
+var discordProbe = struct {
+	sync.Mutex
+	result ProbeResult
+	at     time.Time
+}{}
+
+// probeDiscordAPI reuses a recent result so frequent probes don't spend the REST rate limit
+func probeDiscordAPI(session *discordgo.Session) ProbeResult {
+	if session == nil {
+		return ProbeResult{Status: ProbeFailed, Detail: "no Discord session"}
+	}
+	discordProbe.Lock()
+	defer discordProbe.Unlock()
+	if !discordProbe.at.IsZero() && time.Since(discordProbe.at) < discordProbeInterval {
+		return discordProbe.result
+	}
+
+	discordProbe.result = ProbeResult{Status: ProbeOK}
+	if err := DiscordOpt.HealthCheck(session); err != nil {
+		discordProbe.result = ProbeResult{Status: ProbeFailed, Detail: err.Error()}
+	}
+	discordProbe.at = time.Now()
+	return discordProbe.result
+}
+
+func probeTaskQueue() ProbeResult {
+	if TaskMgr == nil {
+		return ProbeResult{Status: ProbeSkipped, Detail: "async tasks not started"}
+	}
+	depth, capacity := TaskMgr.QueueDepth(), cap(TaskMgr.taskQueue)
+	detail := fmt.Sprintf("%d/%d queued", depth, capacity)
+	if float64(depth) > float64(capacity)*MaxTaskQueueFill {
+		return ProbeResult{Status: ProbeFailed, Detail: detail}
+	}
+	return ProbeResult{Status: ProbeOK, Detail: detail}
+}
+
+// probeUserCache fails if the cache's cleanup goroutine has stopped ticking
+func probeUserCache(now time.Time) ProbeResult {
+	if Cache == nil {
+		return ProbeResult{Status: ProbeSkipped, Detail: "cache not started"}
+	}
+	last := GetCacheStats().LastCleanup
+	if age := now.Sub(last); age > MaxCacheCleanupAge {
+		return ProbeResult{Status: ProbeFailed, Detail: fmt.Sprintf("last cleanup %s ago", age.Round(time.Second))}
+	}
+	return ProbeResult{Status: ProbeOK}
+}
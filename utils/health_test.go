@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestProbeGatewayHeartbeatAge(t *testing.T) {
+	now := time.Now()
+	s := &discordgo.Session{}
+	if got := probeGateway(s, now); got.Status != ProbeFailed {
+		t.Errorf("session that never connected = %+v, want fail", got)
+	}
+
+	s.LastHeartbeatSent = now.Add(-10 * time.Second)
+	s.LastHeartbeatAck = now.Add(-9 * time.Second)
+	if got := probeGateway(s, now); got.Status != ProbeOK {
+		t.Errorf("fresh heartbeat = %+v, want ok", got)
+	}
+
+	if got := probeGateway(s, now.Add(MaxHeartbeatAge)); got.Status != ProbeFailed {
+		t.Errorf("stale heartbeat = %+v, want fail", got)
+	}
+}
+
+func TestProbeTaskQueueBacklog(t *testing.T) {
+	saved := TaskMgr
+	defer func() { TaskMgr = saved }()
+
+	TaskMgr = &AsyncTaskManager{taskQueue: make(chan func(), 10)}
+	for n := 0; n < 9; n++ {
+		TaskMgr.taskQueue <- func() {}
+	}
+	if got := probeTaskQueue(); got.Status != ProbeOK {
+		t.Errorf("90%% full queue = %+v, want ok", got)
+	}
+	TaskMgr.taskQueue <- func() {}
+	if got := probeTaskQueue(); got.Status != ProbeFailed {
+		t.Errorf("full queue = %+v, want fail", got)
+	}
+}
+
+func TestCheckLivenessGatewayStall(t *testing.T) {
+	saved := Cache
+	defer func() { Cache = saved }()
+	Cache = nil
+
+	now := time.Now()
+	s := &discordgo.Session{}
+	if got := CheckLiveness(s, now); !got.Ready() {
+		t.Errorf("gateway still connecting = %+v, want live", got)
+	}
+
+	s.LastHeartbeatAck = now.Add(-MaxHeartbeatAge - time.Second)
+	if got := CheckLiveness(s, now); !got.Ready() {
+		t.Errorf("gateway past readiness but within the stall limit = %+v, want live", got)
+	}
+
+	s.LastHeartbeatAck = now.Add(-MaxGatewayStall - time.Second)
+	if got := CheckLiveness(s, now); got.Ready() || got.Checks["gateway"].Status != ProbeFailed {
+		t.Errorf("stalled gateway = %+v, want fail", got)
+	}
+}
@@ -2,11 +2,14 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	"hrc-go/utils/rng"
+
 	"github.com/jackc/pgx/v5"
 )
 
@@ -36,64 +39,36 @@ type Jackpot struct {
 type JackpotManager struct {
 	jackpots map[JackpotType]*Jackpot
 	mutex    sync.RWMutex
+	cfg      JackpotConfig
 }
 
 // Global jackpot manager
 var JackpotMgr *JackpotManager
 
-// Jackpot configuration constants
-const (
-	DefaultSlotsJackpot     = 2500    // 100k starting jackpot for slots
-	DefaultGeneralJackpot   = 2500    // 50k starting jackpot for general games
-	SlotsContributionRate   = 0.10    // 10% of each slots bet goes to jackpot
-	GeneralContributionRate = 0.005   // 0.5% of other game bets goes to jackpot
-	MinimumJackpotAmount    = 10000   // Minimum jackpot before reset
-	JackpotWinThreshold     = 1000000 // Jackpot win probability threshold
-)
-
-// InitializeJackpotManager sets up the jackpot system
-func InitializeJackpotManager() error {
-	JackpotMgr = &JackpotManager{jackpots: make(map[JackpotType]*Jackpot)}
-	if err := JackpotMgr.createJackpotsTable(); err != nil {
-		return fmt.Errorf("failed to create jackpots table: %w", err)
-	}
-	if err := JackpotMgr.loadJackpots(); err != nil {
-		return err
-	}
-	return nil
+// JackpotConfig sets how the slots jackpot is seeded and fed
+type JackpotConfig struct {
+	SlotsSeed             int64   `yaml:"slots_seed"`              // amount the jackpot resets to after a win
+	SlotsContributionRate float64 `yaml:"slots_contribution_rate"` // share of each slots bet added to the jackpot
+	SlotsLossRate         float64 `yaml:"slots_loss_rate"`         // share of each losing spin's net loss added on top
+	MinimumAmount         int64   `yaml:"minimum_amount"`          // the jackpot can't be won below this
 }
 
-// createJackpotsTable creates the jackpots table if it doesn't exist
-func (jm *JackpotManager) createJackpotsTable() error {
-	if DB == nil {
-		return nil // Skip in offline mode
+// DefaultJackpotConfig returns the built-in jackpot rules
+func DefaultJackpotConfig() JackpotConfig {
+	return JackpotConfig{
+		SlotsSeed:             2500,
+		SlotsContributionRate: 0.10,
+		SlotsLossRate:         0.10,
+		MinimumAmount:         10000,
 	}
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-	query := `
-		CREATE TABLE IF NOT EXISTS jackpots (
-			id SERIAL PRIMARY KEY,
-			type VARCHAR(50) NOT NULL UNIQUE,
-			amount BIGINT NOT NULL DEFAULT 0,
-			seed_amount BIGINT NOT NULL DEFAULT 0,
-			contribution_rate DECIMAL(5,4) NOT NULL DEFAULT 0.01,
-			last_winner BIGINT,
-			last_win_amount BIGINT,
-			last_win_time TIMESTAMP WITH TIME ZONE,
-			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
-		)`
-
-	// creating table (silent unless error)
-	_, err := DB.Exec(ctx, query)
-	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return fmt.Errorf("timeout creating jackpots table: %w", err)
-		}
-		return fmt.Errorf("failed to create jackpots table: %w", err)
+// InitializeJackpotManager sets up the jackpot system with the given rules
+func InitializeJackpotManager(cfg JackpotConfig) error {
+	JackpotMgr = &JackpotManager{jackpots: make(map[JackpotType]*Jackpot), cfg: cfg}
+	if err := JackpotMgr.loadJackpots(); err != nil {
+		return err
 	}
-
-	// table ready
 	return nil
 }
 
@@ -145,6 +120,10 @@ func (jm *JackpotManager) loadJackpots() error {
 			continue
 		}
 		jackpot.Type = JackpotType(jackpotType)
+		if jackpot.Type == JackpotSlots { // configured rules win over the persisted ones
+			jackpot.SeedAmount = jm.cfg.SlotsSeed
+			jackpot.ContributionRate = jm.cfg.SlotsContributionRate
+		}
 		temp[jackpot.Type] = &jackpot
 		loaded++
 	}
@@ -172,9 +151,9 @@ func (jm *JackpotManager) initializeDefaultJackpots() {
 	// Only slots jackpot for now
 	defaultJackpots := []*Jackpot{{
 		Type:             JackpotSlots,
-		Amount:           DefaultSlotsJackpot,
-		SeedAmount:       DefaultSlotsJackpot,
-		ContributionRate: SlotsContributionRate,
+		Amount:           jm.cfg.SlotsSeed,
+		SeedAmount:       jm.cfg.SlotsSeed,
+		ContributionRate: jm.cfg.SlotsContributionRate,
 		UpdatedAt:        now,
 	}}
 	jm.mutex.Lock()
@@ -306,6 +285,18 @@ func (jm *JackpotManager) updateJackpotInDB(jackpot *Jackpot) error {
 	return nil
 }
 
+// Flush writes every jackpot's in-memory state to the database, catching any
+// contribution whose write was lost
+func (jm *JackpotManager) Flush() error {
+	var errs []error
+	for _, jackpot := range jm.GetAllJackpots() {
+		if err := jm.updateJackpotInDB(jackpot); err != nil {
+			errs = append(errs, fmt.Errorf("failed to flush %s jackpot: %w", jackpot.Type, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // TryWinJackpot attempts to win the jackpot based on probability
 func (jm *JackpotManager) TryWinJackpot(jackpotType JackpotType, userID int64, betAmount int64, probability float64) (bool, int64, error) {
 	jm.mutex.Lock()
@@ -317,7 +308,7 @@ func (jm *JackpotManager) TryWinJackpot(jackpotType JackpotType, userID int64, b
 	}
 
 	// Check minimum jackpot amount
-	if jackpot.Amount < MinimumJackpotAmount {
+	if jackpot.Amount < jm.cfg.MinimumAmount {
 		return false, 0, nil
 	}
 
@@ -325,15 +316,7 @@ func (jm *JackpotManager) TryWinJackpot(jackpotType JackpotType, userID int64, b
 	betMultiplier := 1.0 + (float64(betAmount)/float64(jackpot.Amount))*0.1
 	adjustedProbability := probability * betMultiplier
 
-	// Simulate random chance (in production, you'd use proper RNG)
-	// For now, use simple probability check
-	// adjustedProbability * 1000000 would be threshold if using integer RNG; kept implicit
-
-	// Simplified win condition - in production you'd use crypto/rand
-	timestamp := time.Now().UnixNano()
-	randomValue := float64(timestamp%1000000) / 1000000.0
-
-	if randomValue <= adjustedProbability {
+	if rng.Float64() < adjustedProbability {
 		// JACKPOT WON!
 		winAmount := jackpot.Amount
 
@@ -426,6 +409,11 @@ func (jm *JackpotManager) ResetJackpot(jackpotType JackpotType) error {
 	return nil
 }
 
+// LossContribution is the share of a losing spin's net loss added to the slots jackpot
+func (jm *JackpotManager) LossContribution(loss int64) int64 {
+	return int64(float64(loss) * jm.cfg.SlotsLossRate)
+}
+
 // AddJackpotAmount manually adds amount to jackpot (admin function)
 func (jm *JackpotManager) AddJackpotAmount(jackpotType JackpotType, amount int64) error {
 	jm.mutex.Lock()
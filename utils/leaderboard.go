@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// LeaderboardBoard is a stat players are ranked by
+type LeaderboardBoard string
+
+const (
+	BoardChips      LeaderboardBoard = "chips"
+	BoardXP         LeaderboardBoard = "xp"
+	BoardPrestige   LeaderboardBoard = "prestige"
+	BoardProfit     LeaderboardBoard = "profit"
+	BoardWinRate    LeaderboardBoard = "winrate"
+	BoardBiggestWin LeaderboardBoard = "biggestwin"
+)
+
+// LeaderboardMinGames is how many games a player needs to appear on the win rate board
+const LeaderboardMinGames = 50
+
+// LeaderboardPageSize is how many players each leaderboard page shows
+const LeaderboardPageSize = 10
+
+// leaderboardSources select (user_id, value) for each board. Win rate is in basis points.
+var leaderboardSources = map[LeaderboardBoard]string{
+	BoardChips:    `SELECT user_id, chips AS value FROM users`,
+	BoardXP:       `SELECT user_id, total_xp AS value FROM users`,
+	BoardPrestige: `SELECT user_id, prestige::BIGINT AS value FROM users`,
+	BoardProfit: `SELECT user_id, SUM(total_won - total_wagered)::BIGINT AS value
+		FROM user_game_stats GROUP BY user_id`,
+	BoardWinRate: `SELECT user_id, (SUM(wins) * 10000 / SUM(games_played))::BIGINT AS value
+		FROM user_game_stats GROUP BY user_id HAVING SUM(games_played) >= ` + strconv.Itoa(LeaderboardMinGames),
+	BoardBiggestWin: `SELECT user_id, MAX(biggest_win)::BIGINT AS value
+		FROM user_game_stats GROUP BY user_id HAVING MAX(biggest_win) > 0`,
+}
+
+// IsLeaderboardBoard reports whether board is a known leaderboard
+func IsLeaderboardBoard(board LeaderboardBoard) bool {
+	_, ok := leaderboardSources[board]
+	return ok
+}
+
+// LeaderboardEntry is one player's place on a board
+type LeaderboardEntry struct {
+	Rank   int
+	UserID int64
+	Value  int64
+}
+
+// rankedLeaderboard returns the CTEs ranking a board, limited to members of guild $1
+// unless it is 0. Each ranked row has rank, user_id, value and the total ranked.
+func rankedLeaderboard(board LeaderboardBoard) (string, error) {
+	source, ok := leaderboardSources[board]
+	if !ok {
+		return "", fmt.Errorf("unknown leaderboard %q", board)
+	}
+	return `
+		WITH scores AS (
+			SELECT * FROM (` + source + `) s
+			WHERE $1::BIGINT = 0 OR user_id IN (SELECT user_id FROM guild_members WHERE guild_id = $1)
+		),
+		ranked AS (
+			SELECT ROW_NUMBER() OVER (ORDER BY value DESC, user_id) AS rank, user_id, value,
+			       COUNT(*) OVER () AS total
+			FROM scores
+		)`, nil
+}
+
+// GetLeaderboardPage returns one page (counting from 1) of a board and how many players
+// are ranked on it
+func GetLeaderboardPage(board LeaderboardBoard, guildID int64, page int) ([]LeaderboardEntry, int, error) {
+	if DB == nil {
+		return nil, 0, fmt.Errorf("database not connected")
+	}
+	ranked, err := rankedLeaderboard(board)
+	if err != nil {
+		return nil, 0, err
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	ctx := context.Background()
+	rows, err := DB.Query(ctx, ranked+`
+		SELECT rank, user_id, value, total FROM ranked
+		WHERE rank > $2 ORDER BY rank LIMIT $3`,
+		guildID, (page-1)*LeaderboardPageSize, LeaderboardPageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get leaderboard: %w", err)
+	}
+	defer rows.Close()
+	return scanLeaderboard(rows)
+}
+
+// GetLeaderboardPosition returns a user's entry on a board with radius neighbours either
+// side, or nothing if they are not ranked
+func GetLeaderboardPosition(board LeaderboardBoard, guildID, userID int64, radius int) ([]LeaderboardEntry, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+	ranked, err := rankedLeaderboard(board)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	rows, err := DB.Query(ctx, ranked+`,
+		me AS (SELECT rank FROM ranked WHERE user_id = $2)
+		SELECT r.rank, r.user_id, r.value, r.total FROM ranked r, me
+		WHERE r.rank BETWEEN me.rank - $3 AND me.rank + $3 ORDER BY r.rank`,
+		guildID, userID, radius)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard position: %w", err)
+	}
+	defer rows.Close()
+	entries, _, err := scanLeaderboard(rows)
+	return entries, err
+}
+
+func scanLeaderboard(rows pgx.Rows) ([]LeaderboardEntry, int, error) {
+	var entries []LeaderboardEntry
+	var total int
+	for rows.Next() {
+		var e LeaderboardEntry
+		if err := rows.Scan(&e.Rank, &e.UserID, &e.Value, &total); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan leaderboard entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, total, rows.Err()
+}
+
+// FormatLeaderboardValue renders a board value for display
+func FormatLeaderboardValue(board LeaderboardBoard, value int64) string {
+	switch board {
+	case BoardXP:
+		return FormatChips(value) + " XP"
+	case BoardPrestige:
+		return strconv.FormatInt(value, 10)
+	case BoardWinRate:
+		return fmt.Sprintf("%.1f%%", float64(value)/100)
+	default:
+		return FormatChips(value) + " " + ChipsEmoji
+	}
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatLeaderboardValue(t *testing.T) {
+	if got := FormatLeaderboardValue(BoardWinRate, 5234); got != "52.3%" {
+		t.Errorf("win rate = %q, want 52.3%%", got)
+	}
+	if got := FormatLeaderboardValue(BoardPrestige, 4); got != "4" {
+		t.Errorf("prestige = %q, want 4", got)
+	}
+	if got := FormatLeaderboardValue(BoardXP, 1500); !strings.HasSuffix(got, " XP") {
+		t.Errorf("xp = %q, want an XP suffix", got)
+	}
+}
+
+func TestLeaderboardBoardsHaveSources(t *testing.T) {
+	for _, board := range []LeaderboardBoard{BoardChips, BoardXP, BoardPrestige, BoardProfit, BoardWinRate, BoardBiggestWin} {
+		if _, err := rankedLeaderboard(board); err != nil {
+			t.Errorf("board %q: %v", board, err)
+		}
+	}
+	if IsLeaderboardBoard("nonsense") {
+		t.Error("unknown board reported as valid")
+	}
+}
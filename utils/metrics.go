@@ -0,0 +1,226 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// commandLatencyBuckets are the upper bounds, in seconds, of the command latency histogram
+var commandLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// latencyHistogram is one command's cumulative latency distribution
+type latencyHistogram struct {
+	buckets []uint64 // per bucket, not cumulative; the last slot is +Inf
+	sum     float64
+	count   uint64
+}
+
+// gameMoney is the chips staked on and returned by one game's settled rounds
+type gameMoney struct {
+	wagered int64
+	paidOut int64
+}
+
+var metrics = struct {
+	sync.Mutex
+	commands map[string]*latencyHistogram
+	games    map[string]*gameMoney
+}{
+	commands: make(map[string]*latencyHistogram),
+	games:    make(map[string]*gameMoney),
+}
+
+// ObserveCommandLatency records how long a slash command took to handle
+func ObserveCommandLatency(command string, d time.Duration) {
+	seconds := d.Seconds()
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	h, ok := metrics.commands[command]
+	if !ok {
+		h = &latencyHistogram{buckets: make([]uint64, len(commandLatencyBuckets)+1)}
+		metrics.commands[command] = h
+	}
+	bucket, _ := slices.BinarySearch(commandLatencyBuckets, seconds)
+	h.buckets[bucket]++
+	h.sum += seconds
+	h.count++
+}
+
+// recordGameMoney adds a settled round's stake and payout to its game's counters
+func recordGameMoney(gameType string, wagered, paidOut int64) {
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	m, ok := metrics.games[gameType]
+	if !ok {
+		m = &gameMoney{}
+		metrics.games[gameType] = m
+	}
+	m.wagered += wagered
+	m.paidOut += paidOut
+}
+
+// MetricsHandler serves the bot's metrics in the Prometheus text exposition format
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		bw := bufio.NewWriter(w)
+		WriteMetrics(bw)
+		bw.Flush()
+	})
+}
+
+// WriteMetrics writes every metric family to w
+func WriteMetrics(w *bufio.Writer) {
+	m := metricsWriter{w}
+	writeCommandMetrics(m)
+	writeGameMetrics(m)
+	writeCacheMetrics(m)
+	writeDBMetrics(m)
+	writeRuntimeMetrics(m)
+}
+
+func writeCommandMetrics(m metricsWriter) {
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	m.family("hrc_command_duration_seconds", "Time taken to handle a slash command", "histogram")
+	for _, command := range slices.Sorted(maps.Keys(metrics.commands)) {
+		h := metrics.commands[command]
+		var cumulative uint64
+		for n, bound := range commandLatencyBuckets {
+			cumulative += h.buckets[n]
+			m.sample("hrc_command_duration_seconds_bucket", cumulative, "command", command, "le", strconv.FormatFloat(bound, 'g', -1, 64))
+		}
+		m.sample("hrc_command_duration_seconds_bucket", h.count, "command", command, "le", "+Inf")
+		m.sample("hrc_command_duration_seconds_sum", h.sum, "command", command)
+		m.sample("hrc_command_duration_seconds_count", h.count, "command", command)
+	}
+}
+
+func writeGameMetrics(m metricsWriter) {
+	metrics.Lock()
+	games := make(map[string]gameMoney, len(metrics.games))
+	for game, money := range metrics.games {
+		games[game] = *money
+	}
+	metrics.Unlock()
+
+	m.family("hrc_chips_wagered_total", "Chips staked on settled rounds", "counter")
+	for _, game := range slices.Sorted(maps.Keys(games)) {
+		m.sample("hrc_chips_wagered_total", games[game].wagered, "game", game)
+	}
+	m.family("hrc_chips_paid_out_total", "Chips returned to players by settled rounds", "counter")
+	for _, game := range slices.Sorted(maps.Keys(games)) {
+		m.sample("hrc_chips_paid_out_total", games[game].paidOut, "game", game)
+	}
+
+	stats := GameStateMgr.GetGameStats()
+	m.family("hrc_active_games", "Games currently in progress", "gauge")
+	for _, game := range slices.Sorted(maps.Keys(stats)) {
+		if game == "total" || game == "unique_users" {
+			continue
+		}
+		m.sample("hrc_active_games", stats[game], "game", game)
+	}
+	m.family("hrc_active_players", "Players with a game in progress", "gauge")
+	m.sample("hrc_active_players", stats["unique_users"])
+
+	if JackpotMgr != nil {
+		jackpots := JackpotMgr.GetAllJackpots()
+		m.family("hrc_jackpot_chips", "Current size of each progressive jackpot", "gauge")
+		for _, jt := range slices.Sorted(maps.Keys(jackpots)) {
+			m.sample("hrc_jackpot_chips", jackpots[jt].Amount, "jackpot", string(jt))
+		}
+	}
+}
+
+func writeCacheMetrics(m metricsWriter) {
+	stats := GetCacheStats()
+	m.family("hrc_user_cache_entries", "Users held in the cache", "gauge")
+	m.sample("hrc_user_cache_entries", stats.HotEntries, "tier", "hot")
+	m.sample("hrc_user_cache_entries", stats.ColdEntries, "tier", "cold")
+	m.family("hrc_user_cache_requests_total", "User lookups by whether the cache had them", "counter")
+	m.sample("hrc_user_cache_requests_total", stats.Hits, "result", "hit")
+	m.sample("hrc_user_cache_requests_total", stats.Misses, "result", "miss")
+	m.family("hrc_user_cache_hit_ratio", "Share of user lookups served from the cache", "gauge")
+	m.sample("hrc_user_cache_hit_ratio", stats.HitRatio())
+}
+
+func writeDBMetrics(m metricsWriter) {
+	if DB == nil {
+		return
+	}
+	stat := DB.Stat()
+	m.family("hrc_db_connections", "Database pool connections by state", "gauge")
+	m.sample("hrc_db_connections", stat.AcquiredConns(), "state", "acquired")
+	m.sample("hrc_db_connections", stat.IdleConns(), "state", "idle")
+	m.sample("hrc_db_connections", stat.ConstructingConns(), "state", "constructing")
+	m.family("hrc_db_connections_max", "Largest size the database pool may grow to", "gauge")
+	m.sample("hrc_db_connections_max", stat.MaxConns())
+	m.family("hrc_db_acquires_total", "Connections acquired from the database pool", "counter")
+	m.sample("hrc_db_acquires_total", stat.AcquireCount())
+	m.family("hrc_db_empty_acquires_total", "Acquires that had to wait for a connection", "counter")
+	m.sample("hrc_db_empty_acquires_total", stat.EmptyAcquireCount())
+	m.family("hrc_db_acquire_seconds_total", "Time spent waiting to acquire connections", "counter")
+	m.sample("hrc_db_acquire_seconds_total", stat.AcquireDuration().Seconds())
+}
+
+func writeRuntimeMetrics(m metricsWriter) {
+	if TaskMgr != nil {
+		m.family("hrc_async_tasks_queued", "Background tasks waiting for a worker", "gauge")
+		m.sample("hrc_async_tasks_queued", TaskMgr.QueueDepth())
+		m.family("hrc_async_tasks_capacity", "Size of the background task queue", "gauge")
+		m.sample("hrc_async_tasks_capacity", cap(TaskMgr.taskQueue))
+	}
+
+	d := DiscordOpt.GetMetrics()
+	m.family("hrc_discord_requests_total", "Discord API calls made through the optimizer by outcome", "counter")
+	m.sample("hrc_discord_requests_total", d.SuccessfulReqs, "result", "success")
+	m.sample("hrc_discord_requests_total", d.FailedRequests, "result", "failed")
+	m.sample("hrc_discord_requests_total", d.TimeoutRequests, "result", "timeout")
+	m.family("hrc_discord_latency_average_seconds", "Average Discord API call latency", "gauge")
+	m.sample("hrc_discord_latency_average_seconds", float64(d.AverageLatency)/1000)
+	m.family("hrc_discord_latency_max_seconds", "Slowest Discord API call", "gauge")
+	m.sample("hrc_discord_latency_max_seconds", float64(d.MaxLatency)/1000)
+}
+
+// metricsWriter formats families and samples; write errors surface when the
+// handler flushes, so they are ignored here
+type metricsWriter struct {
+	w *bufio.Writer
+}
+
+func (m metricsWriter) family(name, help, kind string) {
+	fmt.Fprintf(m.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes one value; labels alternate name and value
+func (m metricsWriter) sample(name string, value any, labels ...string) {
+	m.w.WriteString(name)
+	if len(labels) > 0 {
+		m.w.WriteByte('{')
+		for n := 0; n+1 < len(labels); n += 2 {
+			if n > 0 {
+				m.w.WriteByte(',')
+			}
+			fmt.Fprintf(m.w, "%s=\"%s\"", labels[n], escapeLabelValue(labels[n+1]))
+		}
+		m.w.WriteByte('}')
+	}
+	fmt.Fprintf(m.w, " %v\n", value)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelEscaper.Replace(v)
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMetricsExposition(t *testing.T) {
+	ObserveCommandLatency("metrics_test", 300*time.Millisecond)
+	ObserveCommandLatency("metrics_test", 20*time.Second)
+	recordGameMoney(`metrics"test`, 500, 750)
+
+	var out strings.Builder
+	w := bufio.NewWriter(&out)
+	WriteMetrics(w)
+	w.Flush()
+	text := out.String()
+
+	for _, want := range []string{
+		"# TYPE hrc_command_duration_seconds histogram\n",
+		`hrc_command_duration_seconds_bucket{command="metrics_test",le="0.25"} 0` + "\n",
+		`hrc_command_duration_seconds_bucket{command="metrics_test",le="0.5"} 1` + "\n",
+		`hrc_command_duration_seconds_bucket{command="metrics_test",le="+Inf"} 2` + "\n",
+		`hrc_command_duration_seconds_count{command="metrics_test"} 2` + "\n",
+		`hrc_chips_wagered_total{game="metrics\"test"} 500` + "\n",
+		`hrc_chips_paid_out_total{game="metrics\"test"} 750` + "\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedMigrationsAreOrderedAndComplete(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Fatalf("migration %d has version %d; versions must be contiguous from 1", i, m.Version)
+		}
+		if m.Down == "" {
+			t.Fatalf("migration %04d_%s has no down script", m.Version, m.Name)
+		}
+	}
+}
+
+func TestLoadMigrationsRejectsMissingUp(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0001_a.up.sql":   {Data: []byte("SELECT 1;")},
+		"m/0002_b.down.sql": {Data: []byte("SELECT 1;")},
+	}
+	if _, err := loadMigrations(fsys, "m"); err == nil {
+		t.Fatal("expected error for migration without an up script")
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := "-- migrate:no-transaction\n-- comment\nCREATE INDEX a ON t(x);\n\nCREATE INDEX b\n  ON t(y);\n"
+	want := []string{"CREATE INDEX a ON t(x)", "CREATE INDEX b\n  ON t(y)"}
+	if got := splitStatements(script); !reflect.DeepEqual(got, want) {
+		t.Fatalf("splitStatements = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS users (
+	user_id BIGINT PRIMARY KEY,
+	chips BIGINT NOT NULL DEFAULT 0,
+	total_xp BIGINT NOT NULL DEFAULT 0,
+	current_xp BIGINT NOT NULL DEFAULT 0,
+	prestige INTEGER NOT NULL DEFAULT 0,
+	wins INTEGER NOT NULL DEFAULT 0,
+	losses INTEGER NOT NULL DEFAULT 0,
+	daily_bonuses_claimed INTEGER NOT NULL DEFAULT 0,
+	votes_count INTEGER NOT NULL DEFAULT 0,
+	last_hourly TIMESTAMPTZ,
+	last_daily TIMESTAMPTZ,
+	last_weekly TIMESTAMPTZ,
+	last_vote TIMESTAMPTZ,
+	last_bonus TIMESTAMPTZ,
+	premium_settings JSONB,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_chips ON users(chips);
+CREATE INDEX IF NOT EXISTS idx_users_total_xp ON users(total_xp);
+CREATE INDEX IF NOT EXISTS idx_users_wins ON users(wins);
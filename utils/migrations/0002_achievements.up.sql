@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS achievements (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(100) NOT NULL UNIQUE,
+	description TEXT NOT NULL,
+	icon VARCHAR(50) NOT NULL,
+	category VARCHAR(50) NOT NULL,
+	requirement_type VARCHAR(50) NOT NULL,
+	requirement_value BIGINT NOT NULL,
+	chips_reward BIGINT NOT NULL DEFAULT 0,
+	xp_reward BIGINT NOT NULL DEFAULT 0,
+	hidden BOOLEAN NOT NULL DEFAULT false,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS user_achievements (
+	id SERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	achievement_id INTEGER NOT NULL,
+	earned_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(user_id, achievement_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_achievements_user_id ON user_achievements(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_achievements_achievement_id ON user_achievements(achievement_id);
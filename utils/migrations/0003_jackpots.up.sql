@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS jackpots (
+	id SERIAL PRIMARY KEY,
+	type VARCHAR(50) NOT NULL UNIQUE,
+	amount BIGINT NOT NULL DEFAULT 0,
+	seed_amount BIGINT NOT NULL DEFAULT 0,
+	contribution_rate DECIMAL(5,4) NOT NULL DEFAULT 0.01,
+	last_winner BIGINT,
+	last_win_amount BIGINT,
+	last_win_time TIMESTAMP WITH TIME ZONE,
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
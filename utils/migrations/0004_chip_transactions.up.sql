@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS chip_transactions (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	delta BIGINT NOT NULL,
+	balance_after BIGINT NOT NULL,
+	reason VARCHAR(32) NOT NULL,
+	game_type VARCHAR(32),
+	game_id VARCHAR(64),
+	note TEXT,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_chip_transactions_user_created ON chip_transactions(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_chip_transactions_game_id ON chip_transactions(game_id) WHERE game_id IS NOT NULL;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS bet_reservations (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	game_type VARCHAR(32) NOT NULL,
+	game_id VARCHAR(64),
+	amount BIGINT NOT NULL,
+	payout BIGINT,
+	status VARCHAR(16) NOT NULL DEFAULT 'held',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	settled_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_bet_reservations_user_id ON bet_reservations(user_id);
+CREATE INDEX IF NOT EXISTS idx_bet_reservations_held ON bet_reservations(created_at) WHERE status = 'held';
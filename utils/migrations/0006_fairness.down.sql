@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS fair_rounds;
+DROP TABLE IF EXISTS fairness_seeds;
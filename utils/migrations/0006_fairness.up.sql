@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS fairness_seeds (
+	user_id BIGINT PRIMARY KEY,
+	client_seed VARCHAR(64) NOT NULL,
+	nonce BIGINT NOT NULL DEFAULT 0,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS fair_rounds (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	game_type VARCHAR(32) NOT NULL,
+	game_id VARCHAR(64),
+	server_seed VARCHAR(64) NOT NULL,
+	server_seed_hash VARCHAR(64) NOT NULL,
+	client_seed VARCHAR(64) NOT NULL,
+	nonce BIGINT NOT NULL,
+	params JSONB,
+	revealed BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	revealed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_fair_rounds_user_id ON fair_rounds(user_id, created_at DESC);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS game_snapshots (
+	game_type VARCHAR(32) NOT NULL,
+	game_id VARCHAR(64) NOT NULL,
+	user_id BIGINT NOT NULL,
+	reservation_id BIGINT,
+	state JSONB NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (game_type, game_id)
+);
@@ -0,0 +1,13 @@
+-- migrate:no-transaction
+DROP INDEX CONCURRENTLY IF EXISTS idx_users_chips_desc;
+DROP INDEX CONCURRENTLY IF EXISTS idx_users_total_xp_desc;
+DROP INDEX CONCURRENTLY IF EXISTS idx_users_prestige_desc;
+DROP INDEX CONCURRENTLY IF EXISTS idx_users_active_chips;
+DROP INDEX CONCURRENTLY IF EXISTS idx_users_high_xp;
+DROP INDEX CONCURRENTLY IF EXISTS idx_users_last_hourly;
+DROP INDEX CONCURRENTLY IF EXISTS idx_users_last_daily;
+DROP INDEX CONCURRENTLY IF EXISTS idx_users_last_weekly;
+DROP INDEX CONCURRENTLY IF EXISTS idx_users_wins_losses;
+DROP INDEX CONCURRENTLY IF EXISTS idx_users_prestige_xp;
+DROP INDEX CONCURRENTLY IF EXISTS idx_users_premium_gin;
+DROP INDEX CONCURRENTLY IF EXISTS idx_users_created_at;
@@ -0,0 +1,26 @@
+-- migrate:no-transaction
+-- Built concurrently so a deploy does not block writes to users
+
+-- Leaderboard queries with composite indexes (chips, total_xp, prestige)
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_chips_desc ON users(chips DESC, user_id);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_total_xp_desc ON users(total_xp DESC, user_id);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_prestige_desc ON users(prestige DESC, user_id);
+
+-- Partial indexes for high-value users
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_active_chips ON users(chips DESC, user_id) WHERE chips >= 10000;
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_high_xp ON users(total_xp DESC, user_id) WHERE total_xp >= 50000;
+
+-- Bonus cooldown queries
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_last_hourly ON users(last_hourly) WHERE last_hourly IS NOT NULL;
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_last_daily ON users(last_daily) WHERE last_daily IS NOT NULL;
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_last_weekly ON users(last_weekly) WHERE last_weekly IS NOT NULL;
+
+-- Achievement-related queries
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_wins_losses ON users(wins, losses);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_prestige_xp ON users(prestige, total_xp);
+
+-- Premium settings lookups
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_premium_gin ON users USING GIN(premium_settings);
+
+-- Time-based queries
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_created_at ON users(created_at);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS user_game_stats (
+	user_id BIGINT NOT NULL,
+	game_type VARCHAR(32) NOT NULL,
+	games_played BIGINT NOT NULL DEFAULT 0,
+	wins BIGINT NOT NULL DEFAULT 0,
+	losses BIGINT NOT NULL DEFAULT 0,
+	total_wagered BIGINT NOT NULL DEFAULT 0,
+	total_won BIGINT NOT NULL DEFAULT 0,
+	biggest_win BIGINT NOT NULL DEFAULT 0,
+	biggest_loss BIGINT NOT NULL DEFAULT 0,
+	current_streak INTEGER NOT NULL DEFAULT 0,
+	best_win_streak INTEGER NOT NULL DEFAULT 0,
+	best_loss_streak INTEGER NOT NULL DEFAULT 0,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (user_id, game_type)
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS achievement_counters (
+	user_id BIGINT NOT NULL,
+	counter VARCHAR(64) NOT NULL,
+	value BIGINT NOT NULL DEFAULT 0,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (user_id, counter)
+);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS user_streaks (
+	user_id BIGINT NOT NULL,
+	streak_type VARCHAR(16) NOT NULL,
+	current_streak INTEGER NOT NULL DEFAULT 0,
+	best_streak INTEGER NOT NULL DEFAULT 0,
+	times_broken INTEGER NOT NULL DEFAULT 0,
+	last_claim TIMESTAMPTZ,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (user_id, streak_type)
+);
+
+CREATE TABLE IF NOT EXISTS streak_freezes (
+	user_id BIGINT PRIMARY KEY,
+	freezes INTEGER NOT NULL DEFAULT 0,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+	id BIGSERIAL PRIMARY KEY,
+	actor_id BIGINT NOT NULL,
+	action VARCHAR(32) NOT NULL,
+	target_id BIGINT NOT NULL,
+	reason TEXT NOT NULL,
+	details JSONB NOT NULL DEFAULT '{}',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_target ON admin_audit_log(target_id, created_at DESC);
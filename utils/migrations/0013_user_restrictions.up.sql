@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS user_restrictions (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	kind VARCHAR(16) NOT NULL,
+	reason TEXT NOT NULL,
+	created_by BIGINT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	expires_at TIMESTAMPTZ,
+	lifted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_restrictions_active ON user_restrictions(user_id) WHERE lifted_at IS NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_bet_reservations_user_created;
+DROP TABLE IF EXISTS play_limits;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS play_limits (
+	user_id BIGINT NOT NULL,
+	kind VARCHAR(16) NOT NULL,
+	amount BIGINT NOT NULL DEFAULT 0,
+	pending_amount BIGINT,
+	pending_at TIMESTAMPTZ,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (user_id, kind)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bet_reservations_user_created ON bet_reservations(user_id, created_at DESC);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS season_results;
+DROP TABLE IF EXISTS season_stats;
+DROP TABLE IF EXISTS seasons;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS seasons (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(64) NOT NULL,
+	starts_at TIMESTAMPTZ NOT NULL,
+	ends_at TIMESTAMPTZ NOT NULL,
+	closed_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS season_stats (
+	season_id INTEGER NOT NULL REFERENCES seasons(id),
+	user_id BIGINT NOT NULL,
+	net_profit BIGINT NOT NULL DEFAULT 0,
+	wagered BIGINT NOT NULL DEFAULT 0,
+	games_played INTEGER NOT NULL DEFAULT 0,
+	games_won INTEGER NOT NULL DEFAULT 0,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (season_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_season_stats_profit ON season_stats(season_id, net_profit DESC);
+
+CREATE TABLE IF NOT EXISTS season_results (
+	season_id INTEGER NOT NULL REFERENCES seasons(id),
+	board VARCHAR(16) NOT NULL,
+	rank INTEGER NOT NULL,
+	user_id BIGINT NOT NULL,
+	value BIGINT NOT NULL,
+	reward BIGINT NOT NULL DEFAULT 0,
+	badge VARCHAR(32) NOT NULL DEFAULT '',
+	PRIMARY KEY (season_id, board, rank)
+);
+
+CREATE INDEX IF NOT EXISTS idx_season_results_user ON season_results(user_id) WHERE badge <> '';
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS guild_members (
+	guild_id BIGINT NOT NULL,
+	user_id BIGINT NOT NULL,
+	seen_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (guild_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_guild_members_user ON guild_members(user_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS guild_bet_limits;
+DROP TABLE IF EXISTS guild_settings;
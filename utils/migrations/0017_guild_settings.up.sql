@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS guild_settings (
+	guild_id BIGINT PRIMARY KEY,
+	disabled_games TEXT[] NOT NULL DEFAULT '{}',
+	allowed_channels BIGINT[] NOT NULL DEFAULT '{}',
+	announce_channel_id BIGINT NOT NULL DEFAULT 0,
+	big_win_threshold BIGINT NOT NULL DEFAULT 100000,
+	locale VARCHAR(10) NOT NULL DEFAULT 'en-US',
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS guild_bet_limits (
+	guild_id BIGINT NOT NULL,
+	game_type VARCHAR(32) NOT NULL,
+	min_bet BIGINT NOT NULL DEFAULT 0,
+	max_bet BIGINT NOT NULL DEFAULT 0,
+	PRIMARY KEY (guild_id, game_type)
+);
@@ -0,0 +1,405 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// PlayLimitKind identifies a responsible-play limit
+type PlayLimitKind string
+
+const (
+	LimitDailyLoss    PlayLimitKind = "daily_loss"
+	LimitWeeklyLoss   PlayLimitKind = "weekly_loss"
+	LimitDailyWager   PlayLimitKind = "daily_wager"
+	LimitWeeklyWager  PlayLimitKind = "weekly_wager"
+	LimitRealityCheck PlayLimitKind = "reality_check" // minutes of play between reminders
+)
+
+// LimitCoolingOff is how long a raised or removed limit waits before taking effect.
+// Tightening a limit applies immediately.
+const LimitCoolingOff = 24 * time.Hour
+
+// playSessionIdle ends a play session after this long without a bet
+const playSessionIdle = 15 * time.Minute
+
+// ErrPlayLimitReached is matched by every error a responsible-play limit returns
+var ErrPlayLimitReached = errors.New("play limit reached")
+
+// ErrPlayLimitsUnavailable is returned when a user's limits or usage can't be read. Bets
+// are refused rather than let through a limit that could not be checked.
+var ErrPlayLimitsUnavailable = errors.New("couldn't check your play limits right now, please try again in a moment")
+
+// playLimitRule is the rolling window a money limit is measured over
+type playLimitRule struct {
+	window time.Duration
+	loss   bool // limits net losses rather than total wagered
+	label  string
+}
+
+var playLimitRules = map[PlayLimitKind]playLimitRule{
+	LimitDailyLoss:   {window: 24 * time.Hour, loss: true, label: "daily loss"},
+	LimitWeeklyLoss:  {window: 7 * 24 * time.Hour, loss: true, label: "weekly loss"},
+	LimitDailyWager:  {window: 24 * time.Hour, label: "daily wager"},
+	LimitWeeklyWager: {window: 7 * 24 * time.Hour, label: "weekly wager"},
+}
+
+// PlayLimitKinds lists every limit in display order
+var PlayLimitKinds = []PlayLimitKind{LimitDailyLoss, LimitWeeklyLoss, LimitDailyWager, LimitWeeklyWager, LimitRealityCheck}
+
+// PlayLimitLabel is the human name of a limit, e.g. "daily loss"
+func PlayLimitLabel(kind PlayLimitKind) string {
+	if kind == LimitRealityCheck {
+		return "reality check"
+	}
+	return playLimitRules[kind].label
+}
+
+// PlayLimit is one of a user's limits. An amount of 0 means no limit.
+type PlayLimit struct {
+	Kind          PlayLimitKind
+	Amount        int64
+	PendingAmount *int64     // a raise or removal waiting out the cooling-off period
+	PendingAt     *time.Time // when PendingAmount takes effect
+}
+
+// Effective returns the limit in force at now, applying a pending change once it matures
+func (l *PlayLimit) Effective(now time.Time) int64 {
+	if l.PendingAmount != nil && l.PendingAt != nil && !now.Before(*l.PendingAt) {
+		return *l.PendingAmount
+	}
+	return l.Amount
+}
+
+// applyLimitChange sets a limit to amount at now. Money limits that loosen (a higher cap,
+// or removing the cap) wait LimitCoolingOff; anything else applies straight away and
+// cancels a pending change.
+func applyLimitChange(l PlayLimit, amount int64, now time.Time) PlayLimit {
+	current := l.Effective(now)
+	l.Amount, l.PendingAmount, l.PendingAt = current, nil, nil
+
+	loosens := current > 0 && (amount == 0 || amount > current)
+	if l.Kind != LimitRealityCheck && loosens {
+		at := now.Add(LimitCoolingOff)
+		l.PendingAmount, l.PendingAt = &amount, &at
+		return l
+	}
+	l.Amount = amount
+	return l
+}
+
+// LimitError is returned when a bet would break one of the user's limits
+type LimitError struct {
+	Kind  PlayLimitKind
+	Limit int64
+	Used  int64
+}
+
+func (e *LimitError) Error() string {
+	rule := playLimitRules[e.Kind]
+	window := "24 hours"
+	if rule.window > 24*time.Hour {
+		window = "7 days"
+	}
+	return fmt.Sprintf("⛔ This bet would go over your %s limit of %s chips (%s used in the last %s). Check `/limits` to see your limits.",
+		rule.label, FormatChips(e.Limit), FormatChips(e.Used), window)
+}
+
+func (e *LimitError) Unwrap() error { return ErrPlayLimitReached }
+
+// IsPlayBlocked reports whether an escrow error came from the pre-game gate, a play
+// limit, the house limits, the server's settings or a shutdown, whose messages are
+// written for the player
+func IsPlayBlocked(err error) bool {
+	return errors.Is(err, ErrPlayRestricted) || errors.Is(err, ErrRestrictionUnavailable) || errors.Is(err, ErrPlayLimitReached) ||
+		errors.Is(err, ErrPlayLimitsUnavailable) ||
+		errors.Is(err, ErrGuildRule) || errors.Is(err, ErrHouseLimit) || errors.Is(err, ErrShuttingDown)
+}
+
+// BetErrorMessage is what to tell a player whose bet could not be placed: the reason for
+// a blocked or unaffordable bet, and a generic message for anything else
+func BetErrorMessage(err error) string {
+	switch {
+	case IsPlayBlocked(err):
+		return err.Error()
+	case errors.Is(err, ErrInsufficientChips):
+		return "You don't have enough chips for that bet."
+	}
+	return "Could not place your bet. Please try again."
+}
+
+type playLimitCacheEntry struct {
+	limits   map[PlayLimitKind]*PlayLimit
+	loadedAt time.Time
+}
+
+var playLimitCache = struct {
+	sync.RWMutex
+	byUser map[int64]playLimitCacheEntry
+}{byUser: make(map[int64]playLimitCacheEntry)}
+
+// GetPlayLimits returns a user's limits keyed by kind; kinds never set are absent
+func GetPlayLimits(userID int64) (map[PlayLimitKind]*PlayLimit, error) {
+	if DB == nil {
+		return map[PlayLimitKind]*PlayLimit{}, nil
+	}
+
+	playLimitCache.RLock()
+	entry, ok := playLimitCache.byUser[userID]
+	playLimitCache.RUnlock()
+	if ok && time.Since(entry.loadedAt) < restrictionCacheTTL {
+		return entry.limits, nil
+	}
+
+	ctx := context.Background()
+	rows, err := DB.Query(ctx, `SELECT kind, amount, pending_amount, pending_at FROM play_limits WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get play limits: %w", err)
+	}
+	defer rows.Close()
+
+	limits := make(map[PlayLimitKind]*PlayLimit)
+	for rows.Next() {
+		l := &PlayLimit{}
+		if err := rows.Scan(&l.Kind, &l.Amount, &l.PendingAmount, &l.PendingAt); err != nil {
+			return nil, fmt.Errorf("failed to scan play limit: %w", err)
+		}
+		limits[l.Kind] = l
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get play limits: %w", err)
+	}
+
+	playLimitCache.Lock()
+	playLimitCache.byUser[userID] = playLimitCacheEntry{limits: limits, loadedAt: time.Now()}
+	playLimitCache.Unlock()
+	return limits, nil
+}
+
+// SetPlayLimit changes one of a user's limits, subject to the cooling-off period for raises
+func SetPlayLimit(userID int64, kind PlayLimitKind, amount int64) (*PlayLimit, error) {
+	if _, ok := playLimitRules[kind]; !ok && kind != LimitRealityCheck {
+		return nil, fmt.Errorf("unknown play limit %q", kind)
+	}
+	if amount < 0 {
+		return nil, fmt.Errorf("play limit cannot be negative")
+	}
+	if DB == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx := context.Background()
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO play_limits (user_id, kind) VALUES ($1, $2)
+		ON CONFLICT (user_id, kind) DO NOTHING`, userID, kind); err != nil {
+		return nil, fmt.Errorf("failed to set play limit: %w", err)
+	}
+	l := PlayLimit{Kind: kind}
+	err = tx.QueryRow(ctx, `
+		SELECT amount, pending_amount, pending_at FROM play_limits
+		WHERE user_id = $1 AND kind = $2 FOR UPDATE`, userID, kind).
+		Scan(&l.Amount, &l.PendingAmount, &l.PendingAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load play limit: %w", err)
+	}
+
+	l = applyLimitChange(l, amount, time.Now())
+	if _, err := tx.Exec(ctx, `
+		UPDATE play_limits SET amount = $3, pending_amount = $4, pending_at = $5, updated_at = NOW()
+		WHERE user_id = $1 AND kind = $2`,
+		userID, kind, l.Amount, l.PendingAmount, l.PendingAt); err != nil {
+		return nil, fmt.Errorf("failed to set play limit: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit play limit: %w", err)
+	}
+
+	playLimitCache.Lock()
+	delete(playLimitCache.byUser, userID)
+	playLimitCache.Unlock()
+	return &l, nil
+}
+
+// PlayUsage is how much a user has wagered and lost over the limit windows. Held stakes
+// count as lost until they settle.
+type PlayUsage struct {
+	DailyWagered, WeeklyWagered int64
+	DailyLost, WeeklyLost       int64
+}
+
+// Used is how much of a money limit the usage counts against
+func (u PlayUsage) Used(kind PlayLimitKind) int64 {
+	switch kind {
+	case LimitDailyLoss:
+		return max(u.DailyLost, 0)
+	case LimitWeeklyLoss:
+		return max(u.WeeklyLost, 0)
+	case LimitDailyWager:
+		return u.DailyWagered
+	case LimitWeeklyWager:
+		return u.WeeklyWagered
+	}
+	return 0
+}
+
+// GetPlayUsage sums the user's game reservations over the daily and weekly windows
+func GetPlayUsage(userID int64) (PlayUsage, error) {
+	var u PlayUsage
+	if DB == nil {
+		return u, nil
+	}
+
+	now := time.Now()
+	ctx := context.Background()
+	err := DB.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount) FILTER (WHERE created_at > $2), 0),
+		       COALESCE(SUM(amount), 0),
+		       COALESCE(SUM(amount - COALESCE(payout, 0)) FILTER (WHERE created_at > $2), 0),
+		       COALESCE(SUM(amount - COALESCE(payout, 0)), 0)
+		FROM bet_reservations
+		WHERE user_id = $1 AND status <> $4 AND created_at > $3`,
+		userID, now.Add(-24*time.Hour), now.Add(-7*24*time.Hour), ReservationRefunded).
+		Scan(&u.DailyWagered, &u.WeeklyWagered, &u.DailyLost, &u.WeeklyLost)
+	if err != nil {
+		return u, fmt.Errorf("failed to get play usage: %w", err)
+	}
+	return u, nil
+}
+
+// CheckPlayLimits returns a *LimitError if staking amount, on a new round or on top of a
+// running one, could break one of the user's wager or loss limits, and
+// ErrPlayLimitsUnavailable if that can't be checked
+func CheckPlayLimits(userID, amount int64) error {
+	return checkPlayLimits(userID, amount, GetPlayLimits, GetPlayUsage, time.Now())
+}
+
+func checkPlayLimits(userID, amount int64, loadLimits func(int64) (map[PlayLimitKind]*PlayLimit, error),
+	loadUsage func(int64) (PlayUsage, error), now time.Time) error {
+	limits, err := loadLimits(userID)
+	if err != nil {
+		log.Printf("⚠️ Failed to load play limits for %d: %v", userID, err)
+		return ErrPlayLimitsUnavailable
+	}
+
+	active := false
+	for kind, l := range limits {
+		if kind != LimitRealityCheck && l.Effective(now) > 0 {
+			active = true
+		}
+	}
+	if !active {
+		return nil
+	}
+
+	usage, err := loadUsage(userID)
+	if err != nil {
+		log.Printf("⚠️ Failed to load play usage for %d: %v", userID, err)
+		return ErrPlayLimitsUnavailable
+	}
+	for _, kind := range PlayLimitKinds {
+		l, ok := limits[kind]
+		if !ok || kind == LimitRealityCheck {
+			continue
+		}
+		limit := l.Effective(now)
+		if used := usage.Used(kind); limit > 0 && used+amount > limit {
+			return &LimitError{Kind: kind, Limit: limit, Used: used}
+		}
+	}
+	return nil
+}
+
+// playSession tracks a stretch of continuous play for reality checks
+type playSession struct {
+	started   time.Time
+	lastPlay  time.Time
+	lastCheck time.Time
+	profit    int64
+}
+
+var playSessions = struct {
+	sync.Mutex
+	byUser    map[int64]*playSession
+	lastSweep time.Time
+}{byUser: make(map[int64]*playSession)}
+
+// notePlay extends the user's play session, starting a new one after a long enough break.
+// Sessions that have already ended are swept out at most once per idle period.
+func notePlay(userID int64, now time.Time) {
+	playSessions.Lock()
+	defer playSessions.Unlock()
+
+	if now.Sub(playSessions.lastSweep) > playSessionIdle {
+		for id, ps := range playSessions.byUser {
+			if now.Sub(ps.lastPlay) > playSessionIdle {
+				delete(playSessions.byUser, id)
+			}
+		}
+		playSessions.lastSweep = now
+	}
+
+	ps, ok := playSessions.byUser[userID]
+	if !ok || now.Sub(ps.lastPlay) > playSessionIdle {
+		ps = &playSession{started: now, lastCheck: now}
+		playSessions.byUser[userID] = ps
+	}
+	ps.lastPlay = now
+}
+
+// recordSessionResult adds a settled round to the session and returns a copy of it when a
+// reality check is due. A due check is only consumed when it can be sent.
+func recordSessionResult(userID, profit int64, every time.Duration, canSend bool, now time.Time) (playSession, bool) {
+	playSessions.Lock()
+	defer playSessions.Unlock()
+
+	ps, ok := playSessions.byUser[userID]
+	if !ok {
+		return playSession{}, false
+	}
+	ps.profit += profit
+	if every <= 0 || !canSend || now.Sub(ps.lastCheck) < every {
+		return playSession{}, false
+	}
+	ps.lastCheck = now
+	return *ps, true
+}
+
+// realityCheck sends the reminder followup once the user's chosen interval of continuous
+// play has passed
+func realityCheck(session *discordgo.Session, interaction *discordgo.InteractionCreate, userID, profit int64) {
+	var every time.Duration
+	if limits, err := GetPlayLimits(userID); err == nil {
+		if l, ok := limits[LimitRealityCheck]; ok {
+			every = time.Duration(l.Effective(time.Now())) * time.Minute
+		}
+	}
+
+	now := time.Now()
+	ps, due := recordSessionResult(userID, profit, every, session != nil && interaction != nil, now)
+	if !due {
+		return
+	}
+
+	result := fmt.Sprintf("up **%s**", FormatChips(ps.profit))
+	if ps.profit < 0 {
+		result = fmt.Sprintf("down **%s**", FormatChips(-ps.profit))
+	}
+	embed := CreateBrandedEmbed("⏰ Reality Check",
+		fmt.Sprintf("You've been playing for **%s** and are %s chips this session.\n\nConsider taking a break. You can set limits with `/limits` or step away with `/selfexclude`.",
+			FormatDuration(now.Sub(ps.started)), result), 0x3498DB)
+	if err := SendFollowupMessage(session, interaction, embed, nil, true); err != nil {
+		log.Printf("⚠️ Reality check for %d failed: %v", userID, err)
+	}
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestApplyLimitChangeCoolsOffRaises(t *testing.T) {
+	now := time.Now()
+	l := applyLimitChange(PlayLimit{Kind: LimitDailyLoss}, 10000, now)
+	if l.Amount != 10000 || l.PendingAt != nil {
+		t.Fatalf("setting a first limit = %+v, want immediate 10000", l)
+	}
+
+	lowered := applyLimitChange(l, 5000, now)
+	if lowered.Amount != 5000 || lowered.PendingAt != nil {
+		t.Fatalf("lowering = %+v, want immediate 5000", lowered)
+	}
+
+	raised := applyLimitChange(lowered, 20000, now)
+	if raised.Effective(now) != 5000 {
+		t.Errorf("raise took effect immediately: %d", raised.Effective(now))
+	}
+	if got := raised.Effective(now.Add(LimitCoolingOff)); got != 20000 {
+		t.Errorf("raise after cooling off = %d, want 20000", got)
+	}
+
+	removed := applyLimitChange(lowered, 0, now)
+	if removed.Effective(now) != 5000 || removed.Effective(now.Add(LimitCoolingOff)) != 0 {
+		t.Errorf("removal = %+v, want pending removal", removed)
+	}
+
+	check := applyLimitChange(PlayLimit{Kind: LimitRealityCheck, Amount: 30}, 120, now)
+	if check.Effective(now) != 120 {
+		t.Errorf("reality check change = %+v, want immediate 120", check)
+	}
+}
+
+func TestNotePlaySweepsEndedSessions(t *testing.T) {
+	start := time.Now().Add(time.Hour) // past any sweep left by other tests
+	notePlay(-1, start)
+	notePlay(-2, start.Add(playSessionIdle/2))
+	notePlay(-3, start.Add(playSessionIdle+time.Minute))
+
+	playSessions.Lock()
+	_, idle := playSessions.byUser[-1]
+	_, active := playSessions.byUser[-2]
+	playSessions.Unlock()
+	if idle {
+		t.Error("an ended session was kept")
+	}
+	if !active {
+		t.Error("a session still in play was swept")
+	}
+}
+
+func TestCheckPlayLimitsFailsClosed(t *testing.T) {
+	now := time.Now()
+	limits := func(int64) (map[PlayLimitKind]*PlayLimit, error) {
+		return map[PlayLimitKind]*PlayLimit{LimitDailyWager: {Kind: LimitDailyWager, Amount: 1000}}, nil
+	}
+	usage := func(int64) (PlayUsage, error) { return PlayUsage{DailyWagered: 900}, nil }
+	broken := errors.New("connection refused")
+
+	if err := checkPlayLimits(1, 100, limits, usage, now); err != nil {
+		t.Errorf("stake within the limit = %v, want nil", err)
+	}
+	if err := checkPlayLimits(1, 101, limits, usage, now); !errors.Is(err, ErrPlayLimitReached) {
+		t.Errorf("stake over the limit = %v, want ErrPlayLimitReached", err)
+	}
+
+	noLimits := func(int64) (map[PlayLimitKind]*PlayLimit, error) { return nil, broken }
+	if err := checkPlayLimits(1, 100, noLimits, usage, now); !errors.Is(err, ErrPlayLimitsUnavailable) || !IsPlayBlocked(err) {
+		t.Errorf("limits lookup failure = %v, want ErrPlayLimitsUnavailable", err)
+	}
+	noUsage := func(int64) (PlayUsage, error) { return PlayUsage{}, broken }
+	if err := checkPlayLimits(1, 100, limits, noUsage, now); !errors.Is(err, ErrPlayLimitsUnavailable) {
+		t.Errorf("usage lookup failure = %v, want ErrPlayLimitsUnavailable", err)
+	}
+}
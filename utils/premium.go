@@ -1,8 +1,6 @@
 package utils
 
 import (
-	"strconv"
-
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -15,7 +13,7 @@ const (
 // HasPremiumRole checks if the member has the premium role
 func HasPremiumRole(member *discordgo.Member) bool {
 	for _, r := range member.Roles {
-		if r == strconv.FormatInt(PremiumRoleID, 10) { // role IDs in discordgo are strings
+		if r == IDs.PremiumRoleID {
 			return true
 		}
 	}
@@ -0,0 +1,220 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrNotEligibleForPrestige is returned when a user's current XP is below the prestige requirement
+var ErrNotEligibleForPrestige = errors.New("not eligible to prestige")
+
+// PrestigeReward is one tier of the prestige track, granted on reaching Level
+type PrestigeReward struct {
+	Level           int     `json:"level"`
+	Chips           int64   `json:"chips"`            // paid on top of the starting balance
+	BonusMultiplier float64 `json:"bonus_multiplier"` // permanent bonus added to claimed bonuses, e.g. 0.05 for +5%
+	Badge           string  `json:"badge"`            // profile badge shown while at this tier
+}
+
+// MaxPrestigeTrackMultiplier caps the combined permanent bonus from the prestige track
+const MaxPrestigeTrackMultiplier = 0.5
+
+// DefaultPrestigeTrack is used unless PRESTIGE_TRACK_FILE points at a JSON track
+var DefaultPrestigeTrack = []PrestigeReward{
+	{Level: 1, Chips: 2500, BonusMultiplier: 0.02, Badge: PrestigeEmojis[1]},
+	{Level: 2, Chips: 5000, BonusMultiplier: 0.02, Badge: PrestigeEmojis[2]},
+	{Level: 3, Chips: 10000, BonusMultiplier: 0.03, Badge: PrestigeEmojis[3]},
+	{Level: 4, Chips: 20000, BonusMultiplier: 0.03, Badge: PrestigeEmojis[4]},
+	{Level: 5, Chips: 35000, BonusMultiplier: 0.05, Badge: PrestigeEmojis[5]},
+	{Level: 6, Chips: 50000, BonusMultiplier: 0.05, Badge: "💠"},
+	{Level: 7, Chips: 75000, BonusMultiplier: 0.05, Badge: "🔱"},
+	{Level: 8, Chips: 100000, BonusMultiplier: 0.05, Badge: "🌌"},
+	{Level: 9, Chips: 150000, BonusMultiplier: 0.05, Badge: "👑"},
+	{Level: 10, Chips: 250000, BonusMultiplier: 0.1, Badge: "🏆"},
+}
+
+var prestigeTrack = struct {
+	sync.RWMutex
+	tiers []PrestigeReward
+}{tiers: DefaultPrestigeTrack}
+
+// LoadPrestigeTrack replaces the prestige track with the tiers in a JSON file
+func LoadPrestigeTrack(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read prestige track: %w", err)
+	}
+	var tiers []PrestigeReward
+	if err := json.Unmarshal(data, &tiers); err != nil {
+		return fmt.Errorf("failed to parse prestige track: %w", err)
+	}
+	return SetPrestigeTrack(tiers)
+}
+
+// SetPrestigeTrack replaces the prestige track; tiers must have distinct positive levels
+func SetPrestigeTrack(tiers []PrestigeReward) error {
+	if len(tiers) == 0 {
+		return fmt.Errorf("prestige track is empty")
+	}
+	sorted := append([]PrestigeReward(nil), tiers...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a].Level < sorted[b].Level })
+	for idx, tier := range sorted {
+		if tier.Level <= 0 {
+			return fmt.Errorf("prestige tier has invalid level %d", tier.Level)
+		}
+		if idx > 0 && sorted[idx-1].Level == tier.Level {
+			return fmt.Errorf("prestige track repeats level %d", tier.Level)
+		}
+		if tier.Chips < 0 || tier.BonusMultiplier < 0 {
+			return fmt.Errorf("prestige tier %d has a negative reward", tier.Level)
+		}
+	}
+
+	prestigeTrack.Lock()
+	prestigeTrack.tiers = sorted
+	prestigeTrack.Unlock()
+	return nil
+}
+
+// PrestigeRewardFor returns the tier granted on reaching a prestige level. Levels past the
+// end of the track repeat its last tier.
+func PrestigeRewardFor(level int) PrestigeReward {
+	prestigeTrack.RLock()
+	defer prestigeTrack.RUnlock()
+
+	reward := PrestigeReward{Level: level}
+	for _, tier := range prestigeTrack.tiers {
+		if tier.Level > level {
+			break
+		}
+		reward = tier
+	}
+	reward.Level = level
+	return reward
+}
+
+// PrestigeBonusMultiplier is the permanent bonus earned from every tier up to a level
+func PrestigeBonusMultiplier(level int) float64 {
+	prestigeTrack.RLock()
+	defer prestigeTrack.RUnlock()
+
+	var total float64
+	var last PrestigeReward
+	for _, tier := range prestigeTrack.tiers {
+		if tier.Level > level {
+			break
+		}
+		total += tier.BonusMultiplier
+		last = tier
+	}
+	// Levels beyond the track keep earning the last tier's bonus
+	if n := len(prestigeTrack.tiers); n > 0 && level > prestigeTrack.tiers[n-1].Level {
+		total += float64(level-last.Level) * last.BonusMultiplier
+	}
+	if total > MaxPrestigeTrackMultiplier {
+		total = MaxPrestigeTrackMultiplier
+	}
+	return total
+}
+
+// PrestigeBadge returns the profile badge for a prestige level, falling back to roman
+// numerals when the track has no badge for it
+func PrestigeBadge(level int) string {
+	if level <= 0 {
+		return ""
+	}
+	if badge := PrestigeRewardFor(level).Badge; badge != "" {
+		return badge
+	}
+	if em, ok := PrestigeEmojis[level]; ok {
+		return em
+	}
+	roman := []string{"", "I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X"}
+	if level < len(roman) {
+		return roman[level]
+	}
+	return strconv.Itoa(level)
+}
+
+// IsPrestigeEligible reports whether a user has the XP to prestige
+func IsPrestigeEligible(user *User) bool {
+	return user.CurrentXP >= PrestigeRequiredXP(user.Prestige)
+}
+
+// PrestigeRequiredXP is the current XP needed to prestige from a level
+func PrestigeRequiredXP(prestige int) int64 {
+	return GetXPForLevel(len(Ranks)-1, prestige)
+}
+
+// PrestigeUser resets a user's chips and current XP and advances their prestige in one
+// transaction. Eligibility is re-checked under the row lock, so concurrent settlements and
+// repeated confirmations cannot prestige twice or leave the balance stale. Returns
+// ErrStakesHeld while any game still holds a stake.
+func PrestigeUser(userID int64, session *discordgo.Session, interaction *discordgo.InteractionCreate) (*User, PrestigeReward, error) {
+	if DB == nil {
+		return nil, PrestigeReward{}, fmt.Errorf("database not connected")
+	}
+
+	ctx := context.Background()
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, PrestigeReward{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var chips, currentXP int64
+	var prestige int
+	err = tx.QueryRow(ctx, `SELECT chips, current_xp, prestige FROM users WHERE user_id = $1 FOR UPDATE`, userID).
+		Scan(&chips, &currentXP, &prestige)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, PrestigeReward{}, ErrNotEligibleForPrestige
+	}
+	if err != nil {
+		return nil, PrestigeReward{}, fmt.Errorf("failed to lock user for prestige: %w", err)
+	}
+	if currentXP < PrestigeRequiredXP(prestige) {
+		return nil, PrestigeReward{}, ErrNotEligibleForPrestige
+	}
+	if err := checkNoHeldStakes(ctx, tx, userID); err != nil {
+		return nil, PrestigeReward{}, err
+	}
+
+	newPrestige := prestige + 1
+	reward := PrestigeRewardFor(newPrestige)
+	updates := UserUpdateData{
+		ChipsIncrement:     Economy.StartingChips + reward.Chips - chips,
+		CurrentXPIncrement: -currentXP,
+		Prestige:           &newPrestige,
+		Reason:             TxReasonPrestige,
+		Note:               fmt.Sprintf("prestige %d", newPrestige),
+	}
+	setParts, args := buildUserUpdateSet(userID, updates)
+	user, err := updateUserInTx(ctx, tx, setParts, args)
+	if err != nil {
+		return nil, PrestigeReward{}, err
+	}
+	if updates.ChipsIncrement != 0 {
+		if err := recordChipTransaction(ctx, tx, user, updates); err != nil {
+			PutUserToPool(user)
+			return nil, PrestigeReward{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		PutUserToPool(user)
+		return nil, PrestigeReward{}, fmt.Errorf("failed to commit prestige: %w", err)
+	}
+
+	ResetPrestigeReady(userID)
+	afterUserUpdate(user, session, interaction)
+	return user, reward, nil
+}
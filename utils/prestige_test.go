@@ -0,0 +1,31 @@
+package utils
+
+import "testing"
+
+func TestPrestigeTrackRepeatsLastTier(t *testing.T) {
+	defer SetPrestigeTrack(DefaultPrestigeTrack)
+	if err := SetPrestigeTrack([]PrestigeReward{
+		{Level: 2, Chips: 200, BonusMultiplier: 0.1, Badge: "b2"},
+		{Level: 1, Chips: 100, BonusMultiplier: 0.05, Badge: "b1"},
+	}); err != nil {
+		t.Fatalf("SetPrestigeTrack: %v", err)
+	}
+
+	if r := PrestigeRewardFor(1); r.Chips != 100 || r.Badge != "b1" {
+		t.Fatalf("level 1 reward = %+v", r)
+	}
+	if r := PrestigeRewardFor(4); r.Level != 4 || r.Chips != 200 || r.Badge != "b2" {
+		t.Fatalf("level 4 reward = %+v", r)
+	}
+	// 0.05 + 0.1 for the track, then 0.1 for each level past it, capped
+	if m := PrestigeBonusMultiplier(3); m < 0.249 || m > 0.251 {
+		t.Fatalf("level 3 multiplier = %v", m)
+	}
+	if m := PrestigeBonusMultiplier(40); m != MaxPrestigeTrackMultiplier {
+		t.Fatalf("level 40 multiplier = %v", m)
+	}
+
+	if err := SetPrestigeTrack([]PrestigeReward{{Level: 1}, {Level: 1}}); err == nil {
+		t.Fatal("expected duplicate levels to be rejected")
+	}
+}
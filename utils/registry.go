@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// InteractionHandler handles a single Discord interaction
+type InteractionHandler func(s *discordgo.Session, i *discordgo.InteractionCreate)
+
+// Route sends interactions whose custom ID starts with Prefix to Handle
+type Route struct {
+	Prefix string
+	Handle InteractionHandler
+	game   string // owning game type, set by RegisterGameModule
+}
+
+// GameModule is how a package in games/ plugs into the bot. Each game registers one
+// from init(); main.go builds its command list and routes interactions from the registry.
+type GameModule struct {
+	GameType      string
+	Command       *discordgo.ApplicationCommand
+	HandleCommand InteractionHandler
+	Components    []Route // button and select menu custom-ID prefixes
+	Modals        []Route // modal custom-ID prefixes
+	// NewState returns an empty game of this type for restoring persisted state; nil
+	// if the game cannot be resumed
+	NewState func() GameState
+	// Start runs once after the bot connects, for games with background work
+	Start func(s *discordgo.Session)
+	// Active counts rounds the module runs outside the GameStateManager, such as races
+	// and tables, so shutdown can wait for them to finish
+	Active func() int
+	// Stop runs at shutdown once the drain deadline passes; it settles or refunds
+	// whatever the module still holds and edits its messages to ShutdownNotice
+	Stop func(s *discordgo.Session)
+}
+
+var (
+	registryMu      sync.RWMutex
+	gameModules     []GameModule
+	commandRoutes   = make(map[string]InteractionHandler)
+	commandGames    = make(map[string]string)
+	componentRoutes []Route
+	modalRoutes     []Route
+)
+
+// RegisterGameModule adds a game to the registry. It panics on a duplicate game type
+// or command name, since that can only be a programming error.
+func RegisterGameModule(m GameModule) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, existing := range gameModules {
+		if existing.GameType == m.GameType {
+			panic("utils: game module registered twice: " + m.GameType)
+		}
+	}
+
+	if m.Command != nil {
+		if _, exists := commandRoutes[m.Command.Name]; exists {
+			panic("utils: command registered twice: " + m.Command.Name)
+		}
+		commandRoutes[m.Command.Name] = m.HandleCommand
+		commandGames[m.Command.Name] = m.GameType
+	}
+
+	gameModules = append(gameModules, m)
+	componentRoutes = addRoutes(componentRoutes, ownedRoutes(m.Components, m.GameType))
+	modalRoutes = addRoutes(modalRoutes, ownedRoutes(m.Modals, m.GameType))
+}
+
+// ownedRoutes tags a module's routes with its game type
+func ownedRoutes(routes []Route, gameType string) []Route {
+	owned := make([]Route, len(routes))
+	for n, r := range routes {
+		r.game = gameType
+		owned[n] = r
+	}
+	return owned
+}
+
+// RegisterComponentRoute routes non-game buttons (profile, premium, vote) through the registry
+func RegisterComponentRoute(prefix string, handle InteractionHandler) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	componentRoutes = addRoutes(componentRoutes, []Route{{Prefix: prefix, Handle: handle}})
+}
+
+// addRoutes merges routes keeping the longest prefixes first, so "craps_bet_select"
+// wins over "craps_"
+func addRoutes(routes, add []Route) []Route {
+	routes = append(routes, add...)
+	sort.SliceStable(routes, func(a, b int) bool {
+		return len(routes[a].Prefix) > len(routes[b].Prefix)
+	})
+	return routes
+}
+
+// GameModules returns the registered games in registration order
+func GameModules() []GameModule {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]GameModule(nil), gameModules...)
+}
+
+// GetGameModule returns the registered module for gameType
+func GetGameModule(gameType string) (GameModule, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, m := range gameModules {
+		if m.GameType == gameType {
+			return m, true
+		}
+	}
+	return GameModule{}, false
+}
+
+// GameCommands returns the slash command definitions of every registered game, sorted by name
+func GameCommands() []*discordgo.ApplicationCommand {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	commands := make([]*discordgo.ApplicationCommand, 0, len(gameModules))
+	for _, m := range gameModules {
+		if m.Command != nil {
+			commands = append(commands, m.Command)
+		}
+	}
+	sort.Slice(commands, func(a, b int) bool { return commands[a].Name < commands[b].Name })
+	return commands
+}
+
+// DispatchCommand runs the registered handler for a slash command once the user passes
+// the pre-game gate and the server's settings. Returns false if no game owns the command.
+func DispatchCommand(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	name := i.ApplicationCommandData().Name
+	registryMu.RLock()
+	handle, ok := commandRoutes[name]
+	gameType := commandGames[name]
+	registryMu.RUnlock()
+
+	if !ok || handle == nil {
+		return false
+	}
+	if gatePlay(s, i, gameType) {
+		handle(s, i)
+	}
+	return true
+}
+
+// DispatchComponent runs the handler whose prefix matches the component's custom ID
+func DispatchComponent(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	return dispatchRoute(componentRoutes, i.MessageComponentData().CustomID, s, i)
+}
+
+// DispatchModal runs the handler whose prefix matches the modal's custom ID. Game modals
+// place bets, so they pass the pre-game gate and the server's settings first.
+func DispatchModal(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	return dispatchRoute(modalRoutes, i.ModalSubmitData().CustomID, s, i, gatePlay)
+}
+
+// gatePlay runs the pre-game gate, then the server's settings for the game
+func gatePlay(s *discordgo.Session, i *discordgo.InteractionCreate, gameType string) bool {
+	return GatePlay(s, i) && GateGuildGame(s, i, gameType)
+}
+
+func dispatchRoute(routes []Route, customID string, s *discordgo.Session, i *discordgo.InteractionCreate, gates ...func(*discordgo.Session, *discordgo.InteractionCreate, string) bool) bool {
+	registryMu.RLock()
+	var route Route
+	for _, r := range routes {
+		if strings.HasPrefix(customID, r.Prefix) {
+			route = r
+			break
+		}
+	}
+	registryMu.RUnlock()
+
+	if route.Handle == nil {
+		return false
+	}
+	for _, gate := range gates {
+		if !gate(s, i, route.game) {
+			return true
+		}
+	}
+	route.Handle(s, i)
+	return true
+}
+
+// StartGameModules runs every registered game's Start hook
+func StartGameModules(s *discordgo.Session) {
+	for _, m := range GameModules() {
+		if m.Start != nil {
+			m.Start(s)
+		}
+	}
+	log.Printf("🎲 %d game modules started", len(GameModules()))
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type testGame struct {
+	id, gameType string
+	userID       int64
+	expired      bool
+	cleaned      int
+}
+
+func (g *testGame) GetGameID() string       { return g.id }
+func (g *testGame) GetUserID() int64        { return g.userID }
+func (g *testGame) GetGameType() string     { return g.gameType }
+func (g *testGame) GetCreatedAt() time.Time { return time.Time{} }
+func (g *testGame) GetExpiresAt() time.Time { return time.Time{} }
+func (g *testGame) IsExpired() bool         { return g.expired }
+func (g *testGame) Cleanup()                { g.cleaned++ }
+
+func TestRegisterUserGameRejectsSecondGameOfType(t *testing.T) {
+	gsm := newGameStateManager()
+	first := &testGame{id: "a", gameType: "mines", userID: 1}
+
+	if _, ok := gsm.RegisterUserGame(first); !ok {
+		t.Fatal("first game should register")
+	}
+	existing, ok := gsm.RegisterUserGame(&testGame{id: "b", gameType: "mines", userID: 1})
+	if ok || existing != first {
+		t.Fatalf("second mines game registered: ok=%v existing=%v", ok, existing)
+	}
+	if _, ok := gsm.RegisterUserGame(&testGame{id: "c", gameType: "slots", userID: 1}); !ok {
+		t.Fatal("game of another type should register")
+	}
+
+	gsm.UnregisterGame("mines", "a")
+	if _, ok := gsm.GetUserGame("mines", 1); ok {
+		t.Fatal("unregistered game still tracked")
+	}
+}
+
+func TestCleanupExpiredGamesRunsCleanupOnce(t *testing.T) {
+	gsm := newGameStateManager()
+	live := &testGame{id: "live", gameType: "horl", userID: 1}
+	stale := &testGame{id: "stale", gameType: "horl", userID: 2, expired: true}
+	gsm.RegisterGame(live)
+	gsm.RegisterGame(stale)
+
+	gsm.cleanupExpiredGames()
+	gsm.cleanupExpiredGames()
+
+	if stale.cleaned != 1 {
+		t.Fatalf("stale game cleaned %d times, want 1", stale.cleaned)
+	}
+	if live.cleaned != 0 || gsm.CountGames("horl") != 1 {
+		t.Fatalf("live game was collected")
+	}
+}
+
+func TestAddRoutesPrefersLongestPrefix(t *testing.T) {
+	var hit string
+	routes := addRoutes(nil, []Route{
+		{Prefix: "craps_", Handle: func(_ *discordgo.Session, _ *discordgo.InteractionCreate) { hit = "button" }},
+		{Prefix: "craps_bet_select", Handle: func(_ *discordgo.Session, _ *discordgo.InteractionCreate) { hit = "select" }},
+	})
+
+	dispatchRoute(routes, "craps_bet_select", nil, nil)
+	if hit != "select" {
+		t.Fatalf("craps_bet_select routed to %q", hit)
+	}
+	dispatchRoute(routes, "craps_roll", nil, nil)
+	if hit != "button" {
+		t.Fatalf("craps_roll routed to %q", hit)
+	}
+}
@@ -0,0 +1,244 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jackc/pgx/v5"
+)
+
+// Restriction kinds stored in user_restrictions
+const (
+	RestrictionBan           = "ban"            // admin ban, permanent or timed
+	RestrictionCooldown      = "cooldown"       // short admin timeout
+	RestrictionSelfExclusion = "self_exclusion" // voluntary, cannot be lifted early
+)
+
+// Bounds on how long a player can self-exclude for (1 day to 1 year)
+const (
+	MinSelfExclusion = 24 * time.Hour
+	MaxSelfExclusion = 365 * 24 * time.Hour
+)
+
+// ErrPlayRestricted is matched by every error the pre-game gate returns
+var ErrPlayRestricted = errors.New("play restricted")
+
+// ErrRestrictionUnavailable is returned when a user's restrictions can't be read. The
+// gate fails closed so a lookup error never lets a banned or self-excluded player in.
+var ErrRestrictionUnavailable = errors.New("couldn't verify your account status right now, please try again in a moment")
+
+// RestrictedError is returned when an active restriction stops a user from playing
+type RestrictedError struct {
+	Restriction *Restriction
+}
+
+func (e *RestrictedError) Error() string { return e.Restriction.Message() }
+
+func (e *RestrictedError) Unwrap() error { return ErrPlayRestricted }
+
+// restrictionCacheTTL bounds how long a cached restriction lookup is trusted
+const restrictionCacheTTL = time.Minute
+
+// Restriction blocks a user from the casino until it expires or is lifted
+type Restriction struct {
+	ID        int64
+	UserID    int64
+	Kind      string
+	Reason    string
+	CreatedBy int64
+	CreatedAt time.Time
+	ExpiresAt *time.Time // nil for permanent restrictions
+}
+
+// Message explains the restriction to the blocked user, including when it ends
+func (r *Restriction) Message() string {
+	var msg string
+	switch r.Kind {
+	case RestrictionSelfExclusion:
+		msg = "🛑 You are self-excluded from the casino"
+	case RestrictionCooldown:
+		msg = "⏸️ You are on a casino cooldown"
+	default:
+		msg = "🚫 You are banned from the casino"
+	}
+	if r.ExpiresAt != nil {
+		msg += fmt.Sprintf(" until <t:%d:f> (<t:%d:R>).", r.ExpiresAt.Unix(), r.ExpiresAt.Unix())
+	} else {
+		msg += "."
+	}
+	if r.Kind == RestrictionSelfExclusion {
+		msg += " Self-exclusion can't be lifted early."
+	} else if r.Reason != "" {
+		msg += "\n**Reason:** " + r.Reason
+	}
+	return msg
+}
+
+type restrictionCacheEntry struct {
+	restriction *Restriction
+	loadedAt    time.Time
+}
+
+var restrictionCache = struct {
+	sync.RWMutex
+	byUser map[int64]restrictionCacheEntry
+}{byUser: make(map[int64]restrictionCacheEntry)}
+
+// AddRestriction records a new restriction on a user
+func AddRestriction(userID int64, kind, reason string, createdBy int64, expiresAt *time.Time) (*Restriction, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	r := &Restriction{UserID: userID, Kind: kind, Reason: reason, CreatedBy: createdBy, ExpiresAt: expiresAt}
+	ctx := context.Background()
+	err := DB.QueryRow(ctx, `
+		INSERT INTO user_restrictions (user_id, kind, reason, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		userID, kind, reason, createdBy, expiresAt).Scan(&r.ID, &r.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add restriction: %w", err)
+	}
+	invalidateRestriction(userID)
+	return r, nil
+}
+
+// LiftRestrictions ends a user's active restrictions of the given kinds, returning how many
+// were lifted
+func LiftRestrictions(userID int64, kinds ...string) (int64, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database not connected")
+	}
+
+	ctx := context.Background()
+	tag, err := DB.Exec(ctx, `
+		UPDATE user_restrictions SET lifted_at = NOW()
+		WHERE user_id = $1 AND kind = ANY($2) AND lifted_at IS NULL`, userID, kinds)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lift restrictions: %w", err)
+	}
+	invalidateRestriction(userID)
+	return tag.RowsAffected(), nil
+}
+
+// ActiveRestriction returns the restriction currently blocking a user, or nil. Permanent
+// restrictions win over timed ones, then the one ending last.
+func ActiveRestriction(userID int64) (*Restriction, error) {
+	if DB == nil {
+		return nil, nil
+	}
+
+	restrictionCache.RLock()
+	entry, ok := restrictionCache.byUser[userID]
+	restrictionCache.RUnlock()
+	if ok && time.Since(entry.loadedAt) < restrictionCacheTTL {
+		if entry.restriction == nil || entry.restriction.ExpiresAt == nil || time.Now().Before(*entry.restriction.ExpiresAt) {
+			return entry.restriction, nil
+		}
+	}
+
+	r := &Restriction{}
+	ctx := context.Background()
+	err := DB.QueryRow(ctx, `
+		SELECT id, user_id, kind, reason, created_by, created_at, expires_at
+		FROM user_restrictions
+		WHERE user_id = $1 AND lifted_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+		ORDER BY expires_at DESC NULLS FIRST
+		LIMIT 1`, userID).
+		Scan(&r.ID, &r.UserID, &r.Kind, &r.Reason, &r.CreatedBy, &r.CreatedAt, &r.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		r = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get restriction: %w", err)
+	}
+
+	restrictionCache.Lock()
+	restrictionCache.byUser[userID] = restrictionCacheEntry{restriction: r, loadedAt: time.Now()}
+	restrictionCache.Unlock()
+	return r, nil
+}
+
+// SelfExclude blocks a user from playing for length, at their own request
+func SelfExclude(userID int64, length time.Duration) (*Restriction, error) {
+	if length < MinSelfExclusion || length > MaxSelfExclusion {
+		return nil, fmt.Errorf("self-exclusion length %s is out of range", length)
+	}
+	until := time.Now().Add(length)
+	return AddRestriction(userID, RestrictionSelfExclusion, "self-exclusion", userID, &until)
+}
+
+// CheckPlayAllowed is the pre-game gate: it returns a *RestrictedError while the user is
+// banned, on cooldown or self-excluded, and ErrRestrictionUnavailable if that can't be
+// checked
+func CheckPlayAllowed(userID int64) error {
+	return checkPlayAllowed(userID, ActiveRestriction)
+}
+
+func checkPlayAllowed(userID int64, lookup func(int64) (*Restriction, error)) error {
+	restriction, err := lookup(userID)
+	if err != nil {
+		log.Printf("⚠️ Failed to check restrictions for %d: %v", userID, err)
+		return ErrRestrictionUnavailable
+	}
+	if restriction != nil {
+		return &RestrictedError{Restriction: restriction}
+	}
+	return nil
+}
+
+// GatePlay runs the pre-game gate for an interaction's user, replying with an ephemeral
+// explanation and returning false when they may not play
+func GatePlay(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	user := i.User
+	if i.Member != nil && i.Member.User != nil {
+		user = i.Member.User
+	}
+	if user == nil {
+		return true
+	}
+	userID, err := ParseUserID(user.ID)
+	if err != nil {
+		return true
+	}
+	if ShuttingDown() {
+		SendInteractionResponse(s, i, CreateBrandedEmbed("Maintenance", ErrShuttingDown.Error(), 0x95A5A6), nil, true)
+		return false
+	}
+	if err := CheckPlayAllowed(userID); err != nil {
+		SendInteractionResponse(s, i, CreateBrandedEmbed("Access Restricted", err.Error(), 0xE74C3C), nil, true)
+		return false
+	}
+	return true
+}
+
+func invalidateRestriction(userID int64) {
+	restrictionCache.Lock()
+	delete(restrictionCache.byUser, userID)
+	restrictionCache.Unlock()
+}
+
+// ParseDurationSpec parses a restriction length such as "30m", "12h", "7d" or "2w"
+func ParseDurationSpec(spec string) (time.Duration, error) {
+	spec = strings.TrimSpace(strings.ToLower(spec))
+	if len(spec) < 2 {
+		return 0, fmt.Errorf("invalid duration %q", spec)
+	}
+	units := map[byte]time.Duration{'m': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	unit, ok := units[spec[len(spec)-1]]
+	if !ok {
+		return 0, fmt.Errorf("invalid duration %q", spec)
+	}
+	n, err := strconv.Atoi(spec[:len(spec)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", spec)
+	}
+	return time.Duration(n) * unit, nil
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDurationSpec(t *testing.T) {
+	cases := map[string]time.Duration{
+		"30m": 30 * time.Minute,
+		"12h": 12 * time.Hour,
+		"7d":  7 * 24 * time.Hour,
+		"2W":  14 * 24 * time.Hour,
+	}
+	for spec, want := range cases {
+		got, err := ParseDurationSpec(spec)
+		if err != nil || got != want {
+			t.Errorf("ParseDurationSpec(%q) = %v, %v; want %v", spec, got, err, want)
+		}
+	}
+	for _, spec := range []string{"", "d", "7", "0d", "-1d", "7y"} {
+		if _, err := ParseDurationSpec(spec); err == nil {
+			t.Errorf("ParseDurationSpec(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestRestrictedErrorMatchesSentinel(t *testing.T) {
+	until := time.Unix(1900000000, 0)
+	err := error(&RestrictedError{Restriction: &Restriction{Kind: RestrictionSelfExclusion, ExpiresAt: &until}})
+	if !errors.Is(err, ErrPlayRestricted) {
+		t.Fatalf("errors.Is(%v, ErrPlayRestricted) = false", err)
+	}
+	if !strings.Contains(err.Error(), "<t:1900000000:f>") {
+		t.Errorf("message %q does not show the expiry", err.Error())
+	}
+}
+
+func TestCheckPlayAllowedFailsClosed(t *testing.T) {
+	failing := func(int64) (*Restriction, error) { return nil, errors.New("connection reset") }
+	err := checkPlayAllowed(1, failing)
+	if !errors.Is(err, ErrRestrictionUnavailable) || !IsPlayBlocked(err) {
+		t.Errorf("failed lookup = %v, want ErrRestrictionUnavailable", err)
+	}
+
+	clear := func(int64) (*Restriction, error) { return nil, nil }
+	if err := checkPlayAllowed(1, clear); err != nil {
+		t.Errorf("no restriction = %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package rng
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// FairSource derives every draw from HMAC-SHA256 keyed by a server seed, so a round's
+// outcome is fixed by (server seed, client seed, nonce) and can be recomputed by anyone
+// once the server seed is revealed.
+//
+// Byte stream: block k is HMAC-SHA256(key=serverSeed, msg="<clientSeed>:<nonce>:<k>"),
+// k = 0, 1, 2, ...; each block yields four big-endian uint64 values in order.
+//
+// Derived values:
+//   - Int63n(n): draw u, reject while u >= floor(2^64/n)*n, return u % n
+//   - Float64: (u >> 11) / 2^53
+//   - Shuffle(n): for i = n-1 down to 1, j = Intn(i+1), swap(i, j)
+//   - Perm(n): identity slice [0..n) shuffled as above
+type FairSource struct {
+	serverSeed []byte
+	clientSeed string
+	nonce      int64
+
+	mu     sync.Mutex
+	block  []byte
+	offset int
+	cursor int64
+	draws  int64
+}
+
+// NewFair returns the deterministic source for one provably-fair round
+func NewFair(serverSeed, clientSeed string, nonce int64) *FairSource {
+	return &FairSource{serverSeed: []byte(serverSeed), clientSeed: clientSeed, nonce: nonce}
+}
+
+// HashServerSeed returns the hex SHA-256 commitment published before a round
+func HashServerSeed(serverSeed string) string {
+	sum := sha256.Sum256([]byte(serverSeed))
+	return hex.EncodeToString(sum[:])
+}
+
+func (f *FairSource) next() uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.offset+8 > len(f.block) {
+		mac := hmac.New(sha256.New, f.serverSeed)
+		fmt.Fprintf(mac, "%s:%d:%d", f.clientSeed, f.nonce, f.cursor)
+		f.block = mac.Sum(nil)
+		f.offset = 0
+		f.cursor++
+	}
+
+	v := binary.BigEndian.Uint64(f.block[f.offset : f.offset+8])
+	f.offset += 8
+	f.draws++
+	return v
+}
+
+// Draws returns how many uint64 values have been consumed from the stream
+func (f *FairSource) Draws() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.draws
+}
+
+// Skip discards n values, so a source rebuilt from its seeds resumes where another left off
+func (f *FairSource) Skip(n int64) {
+	for ; n > 0; n-- {
+		f.next()
+	}
+}
+
+// Int63n returns a uniform int64 in [0, n) using rejection sampling
+func (f *FairSource) Int63n(n int64) int64 {
+	if n <= 0 {
+		panic("rng: invalid argument to Int63n")
+	}
+	return int64(uniform(f.next, uint64(n)))
+}
+
+// uniform draws from next until u < floor(2^64/n)*n and returns u % n. That bound is
+// 2^64 - (2^64 mod n), so when n divides 2^64 nothing is rejected.
+func uniform(next func() uint64, n uint64) uint64 {
+	rem := (math.MaxUint64%n + 1) % n
+	for {
+		u := next()
+		if u <= math.MaxUint64-rem {
+			return u % n
+		}
+	}
+}
+
+// Intn returns a uniform int in [0, n)
+func (f *FairSource) Intn(n int) int {
+	if n <= 0 {
+		panic("rng: invalid argument to Intn")
+	}
+	return int(f.Int63n(int64(n)))
+}
+
+// Float64 returns a uniform float64 in [0.0, 1.0)
+func (f *FairSource) Float64() float64 {
+	return float64(f.next()>>11) / (1 << 53)
+}
+
+// Shuffle performs a Fisher-Yates shuffle from the last element down
+func (f *FairSource) Shuffle(n int, swap func(i, j int)) {
+	for i := n - 1; i > 0; i-- {
+		swap(i, f.Intn(i+1))
+	}
+}
+
+// Perm returns a shuffled permutation of [0, n)
+func (f *FairSource) Perm(n int) []int {
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+	f.Shuffle(n, func(i, j int) { p[i], p[j] = p[j], p[i] })
+	return p
+}
@@ -0,0 +1,125 @@
+package rng
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNewSeededIsDeterministic(t *testing.T) {
+	a, b := NewSeeded(42), NewSeeded(42)
+	for i := 0; i < 100; i++ {
+		if x, y := a.Intn(37), b.Intn(37); x != y {
+			t.Fatalf("draw %d diverged: %d != %d", i, x, y)
+		}
+	}
+	if !reflect.DeepEqual(a.Perm(10), b.Perm(10)) {
+		t.Fatal("Perm diverged for identical seeds")
+	}
+}
+
+func TestSetDefaultRestores(t *testing.T) {
+	original := Default()
+	seeded := NewSeeded(7)
+
+	restore := SetDefault(seeded)
+	if Default() != seeded {
+		t.Fatal("SetDefault did not install the new source")
+	}
+	restore()
+	if Default() != original {
+		t.Fatal("restore did not reinstate the previous source")
+	}
+}
+
+func TestCryptoSourceRanges(t *testing.T) {
+	src := New()
+	for i := 0; i < 1000; i++ {
+		if n := src.Intn(6); n < 0 || n >= 6 {
+			t.Fatalf("Intn(6) out of range: %d", n)
+		}
+		if f := src.Float64(); f < 0 || f >= 1 {
+			t.Fatalf("Float64 out of range: %f", f)
+		}
+	}
+}
+
+func TestFairSourceReplaysFromSeeds(t *testing.T) {
+	a := NewFair("server-seed", "client-seed", 3)
+	b := NewFair("server-seed", "client-seed", 3)
+	for i := 0; i < 100; i++ {
+		if x, y := a.Intn(37), b.Intn(37); x != y {
+			t.Fatalf("draw %d diverged: %d != %d", i, x, y)
+		}
+	}
+	if !reflect.DeepEqual(a.Perm(52), b.Perm(52)) {
+		t.Fatal("Perm diverged for identical seeds")
+	}
+
+	c := NewFair("server-seed", "client-seed", 4)
+	if reflect.DeepEqual(NewFair("server-seed", "client-seed", 3).Perm(52), c.Perm(52)) {
+		t.Fatal("different nonces produced the same permutation")
+	}
+}
+
+func TestFairSourceSkipResumesStream(t *testing.T) {
+	a := NewFair("server-seed", "client-seed", 7)
+	for i := 0; i < 9; i++ {
+		a.Intn(6)
+	}
+
+	b := NewFair("server-seed", "client-seed", 7)
+	b.Skip(a.Draws())
+	for i := 0; i < 20; i++ {
+		if x, y := a.Intn(6), b.Intn(6); x != y {
+			t.Fatalf("draw %d after skip diverged: %d != %d", i, x, y)
+		}
+	}
+}
+
+func TestFairSourceRanges(t *testing.T) {
+	src := NewFair("server-seed", "client-seed", 1)
+	for i := 0; i < 1000; i++ {
+		if n := src.Intn(6); n < 0 || n >= 6 {
+			t.Fatalf("Intn(6) out of range: %d", n)
+		}
+		if f := src.Float64(); f < 0 || f >= 1 {
+			t.Fatalf("Float64 out of range: %f", f)
+		}
+	}
+}
+
+func TestUniformRejectionBound(t *testing.T) {
+	stream := func(values ...uint64) func() uint64 {
+		return func() uint64 {
+			u := values[0]
+			values = values[1:]
+			return u
+		}
+	}
+
+	// 4 divides 2^64, so even the largest value is accepted
+	if got := uniform(stream(math.MaxUint64), 4); got != 3 {
+		t.Errorf("uniform(MaxUint64, 4) = %d, want 3", got)
+	}
+	if got := uniform(stream(math.MaxUint64-2), 1<<32); got != 1<<32-3 {
+		t.Errorf("uniform(MaxUint64-2, 2^32) = %d, want %d", got, uint64(1<<32-3))
+	}
+	// 2^64 mod 3 = 1, so only the largest value falls past floor(2^64/3)*3
+	if got := uniform(stream(math.MaxUint64, 7), 3); got != 1 {
+		t.Errorf("uniform(MaxUint64 then 7, 3) = %d, want the redraw 1", got)
+	}
+	if got := uniform(stream(math.MaxUint64-1), 3); got != (math.MaxUint64-1)%3 {
+		t.Errorf("uniform(MaxUint64-1, 3) = %d, want it accepted", got)
+	}
+	if got := uniform(stream(5), 1); got != 0 {
+		t.Errorf("uniform(5, 1) = %d, want 0", got)
+	}
+}
+
+func TestHashServerSeed(t *testing.T) {
+	const want = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	if got := HashServerSeed("abc"); got != want {
+		t.Fatalf("HashServerSeed(\"abc\") = %s, want %s", got, want)
+	}
+}
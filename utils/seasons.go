@@ -0,0 +1,447 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SeasonBoard is one of the standings tracked each season
+type SeasonBoard string
+
+const (
+	SeasonBoardProfit  SeasonBoard = "profit"
+	SeasonBoardWagered SeasonBoard = "wagered"
+	SeasonBoardWins    SeasonBoard = "wins"
+)
+
+// seasonBoardColumns maps each board to the season_stats column it ranks by
+var seasonBoardColumns = map[SeasonBoard]string{
+	SeasonBoardProfit:  "net_profit",
+	SeasonBoardWagered: "wagered",
+	SeasonBoardWins:    "games_won",
+}
+
+// SeasonBoards lists the boards in display order
+var SeasonBoards = []SeasonBoard{SeasonBoardProfit, SeasonBoardWagered, SeasonBoardWins}
+
+// SeasonReward is paid to the finisher at Rank on the reward board
+type SeasonReward struct {
+	Rank  int    `json:"rank" yaml:"rank"`
+	Chips int64  `json:"chips" yaml:"chips"`
+	Badge string `json:"badge" yaml:"badge"` // shown on the profile of everyone who earned it
+}
+
+// SeasonConfig sets how long seasons run and what they pay
+type SeasonConfig struct {
+	Length      time.Duration  `yaml:"length"`
+	RewardBoard SeasonBoard    `yaml:"reward_board"`
+	Rewards     []SeasonReward `yaml:"rewards"`
+	ResultsKept int            `yaml:"results_kept"` // finishers archived per board
+}
+
+// DefaultSeasonConfig returns the built-in season rules
+func DefaultSeasonConfig() SeasonConfig {
+	return SeasonConfig{
+		Length:      30 * 24 * time.Hour,
+		RewardBoard: SeasonBoardProfit,
+		Rewards: []SeasonReward{
+			{Rank: 1, Chips: 500000, Badge: "🥇"},
+			{Rank: 2, Chips: 250000, Badge: "🥈"},
+			{Rank: 3, Chips: 100000, Badge: "🥉"},
+			{Rank: 4, Chips: 50000, Badge: "🎖️"},
+			{Rank: 5, Chips: 50000, Badge: "🎖️"},
+			{Rank: 6, Chips: 25000},
+			{Rank: 7, Chips: 25000},
+			{Rank: 8, Chips: 25000},
+			{Rank: 9, Chips: 25000},
+			{Rank: 10, Chips: 25000},
+		},
+		ResultsKept: 25,
+	}
+}
+
+// rewardFor returns the reward for a rank on the reward board, if any
+func (c SeasonConfig) rewardFor(rank int) (SeasonReward, bool) {
+	for _, r := range c.Rewards {
+		if r.Rank == rank {
+			return r, true
+		}
+	}
+	return SeasonReward{}, false
+}
+
+// Season is one competitive period
+type Season struct {
+	ID       int
+	Name     string
+	StartsAt time.Time
+	EndsAt   time.Time
+	ClosedAt *time.Time
+}
+
+// AwaitingFinalization reports whether the season has ended but its results have not
+// been archived and paid yet
+func (s *Season) AwaitingFinalization(now time.Time) bool {
+	return s.ClosedAt == nil && !now.Before(s.EndsAt)
+}
+
+// SeasonStanding is a player's position on a season board, live or archived
+type SeasonStanding struct {
+	Rank   int
+	UserID int64
+	Value  int64
+	Reward int64
+	Badge  string
+}
+
+// recordSeasonStatsInTx adds a settled round to the running season, if there is one
+func recordSeasonStatsInTx(ctx context.Context, tx pgx.Tx, userID, wagered, payout int64) error {
+	won := 0
+	if payout > wagered {
+		won = 1
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO season_stats (season_id, user_id, net_profit, wagered, games_played, games_won)
+		SELECT id, $1, $2, $3, 1, $4 FROM seasons
+		WHERE closed_at IS NULL AND starts_at <= NOW() AND ends_at > NOW()
+		ON CONFLICT (season_id, user_id) DO UPDATE SET
+			net_profit = season_stats.net_profit + EXCLUDED.net_profit,
+			wagered = season_stats.wagered + EXCLUDED.wagered,
+			games_played = season_stats.games_played + 1,
+			games_won = season_stats.games_won + EXCLUDED.games_won,
+			updated_at = NOW()`,
+		userID, payout-wagered, wagered, won)
+	if err != nil {
+		return fmt.Errorf("failed to record season stats: %w", err)
+	}
+	return nil
+}
+
+const seasonColumns = `id, name, starts_at, ends_at, closed_at`
+
+func scanSeason(row pgx.Row) (*Season, error) {
+	s := &Season{}
+	if err := row.Scan(&s.ID, &s.Name, &s.StartsAt, &s.EndsAt, &s.ClosedAt); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// CurrentSeason returns the season in progress, or nil between seasons
+func CurrentSeason() (*Season, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+	ctx := context.Background()
+	s, err := scanSeason(DB.QueryRow(ctx, `
+		SELECT `+seasonColumns+` FROM seasons
+		WHERE closed_at IS NULL AND starts_at <= NOW() AND ends_at > NOW()
+		ORDER BY starts_at DESC LIMIT 1`))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current season: %w", err)
+	}
+	return s, nil
+}
+
+// GetSeason returns a season by ID, or nil if there is none
+func GetSeason(id int) (*Season, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+	ctx := context.Background()
+	s, err := scanSeason(DB.QueryRow(ctx, `SELECT `+seasonColumns+` FROM seasons WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get season: %w", err)
+	}
+	return s, nil
+}
+
+// GetSeasonStandings ranks the live stats of a season on one board
+func GetSeasonStandings(seasonID int, board SeasonBoard, limit int) ([]SeasonStanding, error) {
+	column, ok := seasonBoardColumns[board]
+	if !ok {
+		return nil, fmt.Errorf("unknown season board %q", board)
+	}
+	if DB == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx := context.Background()
+	rows, err := DB.Query(ctx, `
+		SELECT user_id, `+column+` FROM season_stats
+		WHERE season_id = $1 AND `+column+` > 0
+		ORDER BY `+column+` DESC, user_id LIMIT $2`, seasonID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get season standings: %w", err)
+	}
+	defer rows.Close()
+
+	var standings []SeasonStanding
+	for rows.Next() {
+		st := SeasonStanding{Rank: len(standings) + 1}
+		if err := rows.Scan(&st.UserID, &st.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan season standing: %w", err)
+		}
+		standings = append(standings, st)
+	}
+	return standings, rows.Err()
+}
+
+// GetSeasonResults returns the archived final standings of a closed season
+func GetSeasonResults(seasonID int, board SeasonBoard) ([]SeasonStanding, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx := context.Background()
+	rows, err := DB.Query(ctx, `
+		SELECT rank, user_id, value, reward, badge FROM season_results
+		WHERE season_id = $1 AND board = $2 ORDER BY rank`, seasonID, board)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get season results: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SeasonStanding
+	for rows.Next() {
+		var st SeasonStanding
+		if err := rows.Scan(&st.Rank, &st.UserID, &st.Value, &st.Reward, &st.Badge); err != nil {
+			return nil, fmt.Errorf("failed to scan season result: %w", err)
+		}
+		results = append(results, st)
+	}
+	return results, rows.Err()
+}
+
+// GetSeasonBadges returns the badges a user has earned, newest season first, e.g. "🥇 S3"
+func GetSeasonBadges(userID int64, limit int) ([]string, error) {
+	if DB == nil {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	rows, err := DB.Query(ctx, `
+		SELECT season_id, badge FROM season_results
+		WHERE user_id = $1 AND badge <> ''
+		ORDER BY season_id DESC LIMIT $2`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get season badges: %w", err)
+	}
+	defer rows.Close()
+
+	var badges []string
+	for rows.Next() {
+		var seasonID int
+		var badge string
+		if err := rows.Scan(&seasonID, &badge); err != nil {
+			return nil, fmt.Errorf("failed to scan season badge: %w", err)
+		}
+		badges = append(badges, fmt.Sprintf("%s S%d", badge, seasonID))
+	}
+	return badges, rows.Err()
+}
+
+// FinalizeSeason snapshots a season's final standings, pays the reward board's top
+// finishers and closes the season, all in one transaction. Finalizing a season twice
+// does nothing the second time.
+func FinalizeSeason(seasonID int, cfg SeasonConfig) error {
+	if DB == nil {
+		return fmt.Errorf("database not connected")
+	}
+
+	ctx := context.Background()
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	season, paid, err := finalizeSeasonInTx(ctx, tx, seasonID, cfg)
+	if err != nil {
+		return err
+	}
+	if season == nil {
+		return nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		for _, u := range paid {
+			PutUserToPool(u)
+		}
+		return fmt.Errorf("failed to commit season close: %w", err)
+	}
+
+	for _, u := range paid {
+		afterUserUpdate(u, nil, nil)
+	}
+	log.Printf("🏁 %s closed", season.Name)
+	return nil
+}
+
+// finalizeSeasonInTx does the work of FinalizeSeason inside tx and returns the closed
+// season with the users it paid, or a nil season if it was already closed
+func finalizeSeasonInTx(ctx context.Context, tx pgx.Tx, seasonID int, cfg SeasonConfig) (season *Season, paid []*User, err error) {
+	defer func() {
+		if err != nil {
+			for _, u := range paid {
+				PutUserToPool(u)
+			}
+			paid = nil
+		}
+	}()
+
+	season, err = scanSeason(tx.QueryRow(ctx, `SELECT `+seasonColumns+` FROM seasons WHERE id = $1 FOR UPDATE`, seasonID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock season: %w", err)
+	}
+	if season.ClosedAt != nil {
+		return nil, nil, nil
+	}
+
+	for _, board := range SeasonBoards {
+		column := seasonBoardColumns[board]
+		rows, err := tx.Query(ctx, `
+			SELECT user_id, `+column+` FROM season_stats
+			WHERE season_id = $1 AND `+column+` > 0
+			ORDER BY `+column+` DESC, user_id LIMIT $2`, seasonID, cfg.ResultsKept)
+		if err != nil {
+			return nil, paid, fmt.Errorf("failed to rank season: %w", err)
+		}
+		var standings []SeasonStanding
+		for rows.Next() {
+			st := SeasonStanding{Rank: len(standings) + 1}
+			if err := rows.Scan(&st.UserID, &st.Value); err != nil {
+				rows.Close()
+				return nil, paid, fmt.Errorf("failed to scan season standing: %w", err)
+			}
+			standings = append(standings, st)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, paid, fmt.Errorf("failed to rank season: %w", err)
+		}
+
+		for _, st := range standings {
+			if board == cfg.RewardBoard {
+				if reward, ok := cfg.rewardFor(st.Rank); ok {
+					st.Reward, st.Badge = reward.Chips, reward.Badge
+				}
+			}
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO season_results (season_id, board, rank, user_id, value, reward, badge)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+				seasonID, board, st.Rank, st.UserID, st.Value, st.Reward, st.Badge); err != nil {
+				return nil, paid, fmt.Errorf("failed to record season result: %w", err)
+			}
+			if st.Reward > 0 {
+				user, err := applyUserUpdateInTx(ctx, tx, st.UserID, UserUpdateData{
+					ChipsIncrement: st.Reward,
+					Reason:         TxReasonSeason,
+					Note:           fmt.Sprintf("%s rank %d", season.Name, st.Rank),
+				})
+				if err != nil {
+					return nil, paid, err
+				}
+				paid = append(paid, user)
+			}
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE seasons SET closed_at = NOW() WHERE id = $1`, seasonID); err != nil {
+		return nil, paid, fmt.Errorf("failed to close season: %w", err)
+	}
+	return season, paid, nil
+}
+
+// ensureSeason opens the next season when none is running. The season is named after
+// the id it is given, so a gap in the sequence cannot produce a duplicate name.
+func ensureSeason(now time.Time, cfg SeasonConfig) error {
+	ctx := context.Background()
+	var open bool
+	var lastEnd *time.Time
+	err := DB.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM seasons WHERE closed_at IS NULL AND ends_at > $1), MAX(ends_at)
+		FROM seasons`, now).Scan(&open, &lastEnd)
+	if err != nil {
+		return fmt.Errorf("failed to check seasons: %w", err)
+	}
+	if open {
+		return nil
+	}
+
+	// Keep seasons back to back unless the bot was away for a whole season
+	start := now
+	if lastEnd != nil && now.Sub(*lastEnd) < cfg.Length {
+		start = *lastEnd
+	}
+	var name string
+	err = DB.QueryRow(ctx, `
+		WITH next AS (SELECT nextval(pg_get_serial_sequence('seasons', 'id')) AS id)
+		INSERT INTO seasons (id, name, starts_at, ends_at)
+		SELECT id, 'Season ' || id, $1, $2 FROM next
+		RETURNING name`, start, start.Add(cfg.Length)).Scan(&name)
+	if err != nil {
+		return fmt.Errorf("failed to open season: %w", err)
+	}
+	log.Printf("🏁 %s opened", name)
+	return nil
+}
+
+// runSeasonJob closes every season past its end and makes sure the next one is open
+func runSeasonJob(cfg SeasonConfig) {
+	ctx := context.Background()
+	rows, err := DB.Query(ctx, `SELECT id FROM seasons WHERE closed_at IS NULL AND ends_at <= NOW() ORDER BY id`)
+	if err != nil {
+		log.Printf("⚠️ Season job failed: %v", err)
+		return
+	}
+	var due []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err == nil {
+			due = append(due, id)
+		}
+	}
+	rows.Close()
+
+	for _, id := range due {
+		if err := FinalizeSeason(id, cfg); err != nil {
+			log.Printf("⚠️ Failed to close season %d: %v", id, err)
+			return
+		}
+	}
+	if err := ensureSeason(time.Now(), cfg); err != nil {
+		log.Printf("⚠️ Season job failed: %v", err)
+	}
+}
+
+var seasonSchedulerOnce sync.Once
+
+// StartSeasonScheduler runs the season job now and then every interval. Safe to call on
+// every reconnect; only the first call starts the loop.
+func StartSeasonScheduler(interval time.Duration, cfg SeasonConfig) {
+	if DB == nil {
+		return
+	}
+	seasonSchedulerOnce.Do(func() {
+		go func() {
+			runSeasonJob(cfg)
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for range ticker.C {
+				runSeasonJob(cfg)
+			}
+		}()
+	})
+}
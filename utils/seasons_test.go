@@ -0,0 +1,190 @@
+package utils
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestSeasonRewardsCoverTopFinishers(t *testing.T) {
+	seasons := DefaultSeasonConfig()
+	for rank := 1; rank <= 10; rank++ {
+		reward, ok := seasons.rewardFor(rank)
+		if !ok || reward.Chips <= 0 {
+			t.Errorf("rank %d has no reward", rank)
+		}
+	}
+	if _, ok := seasons.rewardFor(11); ok {
+		t.Error("rank 11 should not be rewarded")
+	}
+	if _, ok := seasonBoardColumns[seasons.RewardBoard]; !ok {
+		t.Errorf("reward board %q is not a season board", seasons.RewardBoard)
+	}
+}
+
+func TestSeasonAwaitingFinalization(t *testing.T) {
+	now := time.Now()
+	closed := now
+	for _, tc := range []struct {
+		season Season
+		want   bool
+	}{
+		{Season{EndsAt: now.Add(time.Hour)}, false},
+		{Season{EndsAt: now.Add(-time.Hour)}, true},
+		{Season{EndsAt: now.Add(-time.Hour), ClosedAt: &closed}, false},
+	} {
+		if got := tc.season.AwaitingFinalization(now); got != tc.want {
+			t.Errorf("ends %v closed %v: got %v", tc.season.EndsAt.Sub(now), tc.season.ClosedAt != nil, got)
+		}
+	}
+}
+
+// seasonTx stands in for the database while a season is finalized. It ranks from a fixed
+// set of stats and records every result, ledger row and balance change written to it.
+type seasonTx struct {
+	pgx.Tx
+	season  Season
+	stats   map[string][][2]int64 // season_stats column -> (user_id, value), already ranked
+	chips   map[int64]int64
+	results []SeasonStanding
+	ledger  []ChipTransaction
+}
+
+func (tx *seasonTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	switch {
+	case strings.Contains(sql, "FROM seasons"):
+		return scanFunc(func(dest ...any) error {
+			*dest[0].(*int) = tx.season.ID
+			*dest[1].(*string) = tx.season.Name
+			*dest[2].(*time.Time) = tx.season.StartsAt
+			*dest[3].(*time.Time) = tx.season.EndsAt
+			*dest[4].(**time.Time) = tx.season.ClosedAt
+			return nil
+		})
+	case strings.Contains(sql, "UPDATE users"):
+		userID, delta := args[0].(int64), args[1].(int64)
+		tx.chips[userID] += delta
+		return scanFunc(func(dest ...any) error {
+			*dest[0].(*int64) = userID
+			*dest[1].(*int64) = tx.chips[userID]
+			return nil
+		})
+	}
+	panic("unexpected query: " + sql)
+}
+
+func (tx *seasonTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	for column, ranked := range tx.stats {
+		if strings.Contains(sql, "SELECT user_id, "+column) {
+			return &standingRows{rows: ranked}, nil
+		}
+	}
+	return &standingRows{}, nil
+}
+
+func (tx *seasonTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	switch {
+	case strings.Contains(sql, "INSERT INTO season_results"):
+		tx.results = append(tx.results, SeasonStanding{
+			Rank: args[2].(int), UserID: args[3].(int64), Value: args[4].(int64),
+			Reward: args[5].(int64), Badge: args[6].(string),
+		})
+	case strings.Contains(sql, "INSERT INTO chip_transactions"):
+		tx.ledger = append(tx.ledger, ChipTransaction{
+			UserID: args[0].(int64), Delta: args[1].(int64), BalanceAfter: args[2].(int64),
+			Reason: args[3].(string), Note: args[6].(string),
+		})
+	case strings.Contains(sql, "UPDATE seasons SET closed_at"):
+		closed := time.Now()
+		tx.season.ClosedAt = &closed
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+type scanFunc func(dest ...any) error
+
+func (f scanFunc) Scan(dest ...any) error { return f(dest...) }
+
+type standingRows struct {
+	pgx.Rows
+	rows [][2]int64
+	next int
+}
+
+func (r *standingRows) Next() bool { r.next++; return r.next <= len(r.rows) }
+func (r *standingRows) Close()     {}
+func (r *standingRows) Err() error { return nil }
+
+func (r *standingRows) Scan(dest ...any) error {
+	row := r.rows[r.next-1]
+	*dest[0].(*int64), *dest[1].(*int64) = row[0], row[1]
+	return nil
+}
+
+func TestFinalizeSeasonPaysOnceThroughTheLedger(t *testing.T) {
+	cfg := SeasonConfig{
+		RewardBoard: SeasonBoardProfit,
+		Rewards:     []SeasonReward{{Rank: 1, Chips: 500, Badge: "🥇"}, {Rank: 2, Chips: 200}},
+		ResultsKept: 10,
+	}
+	tx := &seasonTx{
+		season: Season{ID: 3, Name: "Season 3", EndsAt: time.Now().Add(-time.Minute)},
+		stats: map[string][][2]int64{
+			"net_profit": {{11, 9000}, {12, 4000}, {13, 100}},
+			"wagered":    {{12, 80000}, {11, 50000}},
+		},
+		chips: map[int64]int64{11: 1000, 12: 1000},
+	}
+
+	season, paid, err := finalizeSeasonInTx(context.Background(), tx, 3, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if season == nil || tx.season.ClosedAt == nil {
+		t.Fatal("season was not closed")
+	}
+	if len(paid) != 2 {
+		t.Fatalf("paid %d users, want 2", len(paid))
+	}
+
+	// Only the reward board carries rewards and badges
+	if len(tx.results) != 5 {
+		t.Fatalf("archived %d results, want 5", len(tx.results))
+	}
+	badges := map[int64]string{}
+	for _, r := range tx.results {
+		if r.Badge != "" {
+			badges[r.UserID] = r.Badge
+		}
+	}
+	if len(badges) != 1 || badges[11] != "🥇" {
+		t.Errorf("badges = %v, want only the winner's 🥇", badges)
+	}
+
+	if len(tx.ledger) != 2 {
+		t.Fatalf("ledger has %d rows, want 2", len(tx.ledger))
+	}
+	for i, want := range []struct {
+		user         int64
+		delta, after int64
+		note         string
+	}{{11, 500, 1500, "Season 3 rank 1"}, {12, 200, 1200, "Season 3 rank 2"}} {
+		got := tx.ledger[i]
+		if got.UserID != want.user || got.Delta != want.delta || got.BalanceAfter != want.after || got.Reason != TxReasonSeason || got.Note != want.note {
+			t.Errorf("ledger row %d = %+v, want %+v", i, got, want)
+		}
+	}
+
+	// A second run, e.g. after a restart mid-job, must not pay again
+	season, paid, err = finalizeSeasonInTx(context.Background(), tx, 3, cfg)
+	if err != nil || season != nil || len(paid) != 0 {
+		t.Fatalf("second finalize: season %v, paid %d, err %v", season, len(paid), err)
+	}
+	if len(tx.results) != 5 || len(tx.ledger) != 2 || tx.chips[11] != 1500 {
+		t.Errorf("second finalize wrote again: %d results, %d ledger rows, %d chips", len(tx.results), len(tx.ledger), tx.chips[11])
+	}
+}
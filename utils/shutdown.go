@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ShutdownNotice is shown on the messages of games stopped for maintenance
+const ShutdownNotice = "🔧 The bot is restarting for maintenance."
+
+// ErrShuttingDown refuses new rounds once shutdown has begun
+var ErrShuttingDown = errors.New("🔧 The casino is closing for maintenance. Please try again in a few minutes.")
+
+var shuttingDown atomic.Bool
+
+// BeginShutdown stops new games from starting; games in progress can still be played out
+func BeginShutdown() {
+	shuttingDown.Store(true)
+}
+
+// ShuttingDown reports whether BeginShutdown has been called
+func ShuttingDown() bool {
+	return shuttingDown.Load()
+}
+
+// activeRounds counts games in progress, both tracked and run by their modules
+func activeRounds() int {
+	n := GameStateMgr.GetGameStats()["total"]
+	for _, m := range GameModules() {
+		if m.Active != nil {
+			n += m.Active()
+		}
+	}
+	return n
+}
+
+// DrainGames waits for games in progress to finish until ctx is done, then stops the
+// rest. Resumable games are saved to continue after the restart, other stakes are
+// refunded, and each game's message is edited to ShutdownNotice.
+func DrainGames(ctx context.Context, s *discordgo.Session) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+wait:
+	for activeRounds() > 0 {
+		select {
+		case <-ctx.Done():
+			break wait
+		case <-ticker.C:
+		}
+	}
+
+	games := GameStateMgr.untrackAll()
+	for _, game := range games {
+		stopGame(s, game)
+	}
+	for _, m := range GameModules() {
+		if m.Stop != nil {
+			m.Stop(s)
+		}
+	}
+	if len(games) > 0 {
+		log.Printf("🔧 Stopped %d games still in progress at shutdown", len(games))
+	}
+}
+
+// stopGame saves or refunds one game left running at shutdown and updates its message
+func stopGame(s *discordgo.Session, game GameState) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("⚠️ Stopping %s game %s panicked: %v", game.GetGameType(), game.GetGameID(), r)
+		}
+	}()
+
+	var notice string
+	if _, ok := game.(PersistentGame); ok && DB != nil {
+		SaveGameSnapshot(game)
+		notice = ShutdownNotice + " This game will pick up where it left off once the bot is back."
+	} else if refunder, ok := game.(interface{ RefundStake() (*User, error) }); ok {
+		if _, err := refunder.RefundStake(); err != nil {
+			log.Printf("⚠️ Shutdown refund failed for %s game %s: %v", game.GetGameType(), game.GetGameID(), err)
+		}
+		notice = ShutdownNotice + " Your bet has been refunded."
+	} else {
+		runGameCleanup(game)
+		return
+	}
+
+	if g, ok := game.(interface {
+		GetInteraction() *discordgo.InteractionCreate
+	}); ok && g.GetInteraction() != nil && s != nil {
+		embed := CreateBrandedEmbed(GameDisplayName(game.GetGameType()), notice, 0x95A5A6)
+		if err := EditOriginalInteraction(s, g.GetInteraction(), embed, []discordgo.MessageComponent{}); err != nil {
+			log.Printf("⚠️ Failed to post shutdown notice for %s game %s: %v", game.GetGameType(), game.GetGameID(), err)
+		}
+	}
+}
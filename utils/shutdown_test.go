@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type drainTestGame struct {
+	id       string
+	cleanups int
+}
+
+func (g *drainTestGame) GetGameID() string       { return g.id }
+func (g *drainTestGame) GetUserID() int64        { return 1 }
+func (g *drainTestGame) GetGameType() string     { return "drain_test" }
+func (g *drainTestGame) GetCreatedAt() time.Time { return time.Time{} }
+func (g *drainTestGame) GetExpiresAt() time.Time { return time.Time{} }
+func (g *drainTestGame) IsExpired() bool         { return false }
+func (g *drainTestGame) Cleanup()                { g.cleanups++ }
+
+func TestShutdownRefusesNewBetsAndStopsGames(t *testing.T) {
+	BeginShutdown()
+	defer shuttingDown.Store(false)
+
+	if _, _, err := ReserveBet(1, "drain_test", "x", 100); !errors.Is(err, ErrShuttingDown) || !IsPlayBlocked(err) {
+		t.Fatalf("ReserveBet during shutdown = %v, want ErrShuttingDown", err)
+	}
+
+	game := &drainTestGame{id: "drain"}
+	GameStateMgr.RegisterGame(game)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	DrainGames(ctx, nil)
+
+	if game.cleanups != 1 {
+		t.Errorf("game left at the deadline cleaned up %d times, want 1", game.cleanups)
+	}
+	if _, ok := GameStateMgr.GetGame("drain_test", "drain"); ok {
+		t.Error("stopped game is still tracked")
+	}
+}
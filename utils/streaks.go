@@ -0,0 +1,308 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jackc/pgx/v5"
+)
+
+// StreakType identifies a claim streak
+type StreakType string
+
+const (
+	StreakDaily  StreakType = "daily"
+	StreakWeekly StreakType = "weekly"
+	StreakVote   StreakType = "vote"
+)
+
+// MaxStreakFreezes is how many streak freezes a user can hold at once
+const MaxStreakFreezes = 3
+
+// Streak is a user's run of consecutive claims of one bonus
+type Streak struct {
+	UserID      int64
+	Type        StreakType
+	Current     int
+	Best        int
+	TimesBroken int
+	LastClaim   *time.Time
+}
+
+// StreakMilestone pays out when a streak reaches Length
+type StreakMilestone struct {
+	Length  int
+	Chips   int64
+	Freezes int
+}
+
+// streakRule is how often a streak must be extended and what it pays along the way
+type streakRule struct {
+	bonus      BonusType     // the bonus whose cooldown sets the period
+	grace      time.Duration // slack after the cooldown before the streak breaks
+	unit       string
+	milestones []StreakMilestone
+}
+
+var streakRules = map[StreakType]streakRule{
+	StreakDaily: {bonus: BonusDaily, grace: 24 * time.Hour, unit: "day", milestones: []StreakMilestone{
+		{Length: 7, Chips: 5000, Freezes: 1},
+		{Length: 30, Chips: 25000, Freezes: 1},
+		{Length: 100, Chips: 150000, Freezes: 2},
+	}},
+	StreakWeekly: {bonus: BonusWeekly, grace: 3 * 24 * time.Hour, unit: "week", milestones: []StreakMilestone{
+		{Length: 4, Chips: 10000, Freezes: 1},
+		{Length: 12, Chips: 40000, Freezes: 1},
+		{Length: 52, Chips: 250000, Freezes: 2},
+	}},
+	StreakVote: {bonus: BonusVote, grace: 12 * time.Hour, unit: "vote", milestones: []StreakMilestone{
+		{Length: 14, Chips: 5000, Freezes: 1},
+		{Length: 60, Chips: 25000, Freezes: 1},
+		{Length: 200, Chips: 150000, Freezes: 2},
+	}},
+}
+
+// period is the cooldown of the bonus that extends the streak
+func (r streakRule) period() time.Duration {
+	rule, _ := BonusMgr.Rule(r.bonus)
+	return rule.Cooldown
+}
+
+// StreakTypeForBonus returns the streak a bonus claim extends, if any
+func StreakTypeForBonus(bonusType BonusType) (StreakType, bool) {
+	switch bonusType {
+	case BonusDaily:
+		return StreakDaily, true
+	case BonusWeekly:
+		return StreakWeekly, true
+	case BonusVote:
+		return StreakVote, true
+	}
+	return "", false
+}
+
+// StreakUnit is the singular noun a streak is counted in ("day", "week", "vote")
+func StreakUnit(streakType StreakType) string {
+	return streakRules[streakType].unit
+}
+
+// StreakUpdate describes what one claim did to a streak
+type StreakUpdate struct {
+	Streak      Streak
+	BrokenFrom  int // length of the streak this claim broke, if any
+	FreezesUsed int
+	FreezesLeft int
+	Milestone   *StreakMilestone
+}
+
+// Alive reports whether the streak is still running at now, counting available freezes
+func (s *Streak) Alive(now time.Time, freezes int) bool {
+	if s.Current == 0 || s.LastClaim == nil {
+		return false
+	}
+	needed, ok := freezesNeeded(now.Sub(*s.LastClaim), streakRules[s.Type])
+	return !ok || needed <= freezes
+}
+
+// freezesNeeded is how many freezes bridge a gap between claims; ok is false when the
+// claim falls inside the same period and does not extend the streak
+func freezesNeeded(gap time.Duration, rule streakRule) (int, bool) {
+	period := rule.period()
+	if gap < period {
+		return 0, false
+	}
+	allowed := period + rule.grace
+	if gap <= allowed {
+		return 0, true
+	}
+	missed := (gap - allowed + period - 1) / period
+	return int(missed), true
+}
+
+// advanceStreak applies a claim at now to s, spending freezes to bridge missed periods
+func advanceStreak(s Streak, freezes int, now time.Time) StreakUpdate {
+	rule := streakRules[s.Type]
+	update := StreakUpdate{FreezesLeft: freezes}
+
+	switch {
+	case s.Current == 0 || s.LastClaim == nil:
+		s.Current = 1
+	default:
+		needed, ok := freezesNeeded(now.Sub(*s.LastClaim), rule)
+		if !ok {
+			// A repeat claim inside the same period leaves the streak alone
+			update.Streak = s
+			return update
+		}
+		if needed <= freezes {
+			s.Current++
+			update.FreezesUsed = needed
+			update.FreezesLeft -= needed
+		} else {
+			update.BrokenFrom = s.Current
+			s.TimesBroken++
+			s.Current = 1
+		}
+	}
+
+	claimed := now
+	s.LastClaim = &claimed
+	if s.Current > s.Best {
+		s.Best = s.Current
+	}
+	for idx := range rule.milestones {
+		if rule.milestones[idx].Length == s.Current {
+			m := rule.milestones[idx]
+			update.Milestone = &m
+			update.FreezesLeft += m.Freezes
+			if update.FreezesLeft > MaxStreakFreezes {
+				update.FreezesLeft = MaxStreakFreezes
+			}
+		}
+	}
+	update.Streak = s
+	return update
+}
+
+// RecordStreakClaim extends the streak a bonus claim belongs to, spending freezes to cover
+// missed periods and paying any milestone reached, all in one transaction
+func RecordStreakClaim(userID int64, bonusType BonusType, now time.Time, session *discordgo.Session, interaction *discordgo.InteractionCreate) (*StreakUpdate, error) {
+	streakType, ok := StreakTypeForBonus(bonusType)
+	if !ok || DB == nil {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO user_streaks (user_id, streak_type) VALUES ($1, $2)
+		ON CONFLICT (user_id, streak_type) DO NOTHING`, userID, streakType); err != nil {
+		return nil, fmt.Errorf("failed to record streak: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO streak_freezes (user_id) VALUES ($1)
+		ON CONFLICT (user_id) DO NOTHING`, userID); err != nil {
+		return nil, fmt.Errorf("failed to record streak: %w", err)
+	}
+
+	s := Streak{UserID: userID, Type: streakType}
+	err = tx.QueryRow(ctx, `
+		SELECT current_streak, best_streak, times_broken, last_claim
+		FROM user_streaks WHERE user_id = $1 AND streak_type = $2 FOR UPDATE`, userID, streakType).
+		Scan(&s.Current, &s.Best, &s.TimesBroken, &s.LastClaim)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load streak: %w", err)
+	}
+	var freezes int
+	if err := tx.QueryRow(ctx, `SELECT freezes FROM streak_freezes WHERE user_id = $1 FOR UPDATE`, userID).Scan(&freezes); err != nil {
+		return nil, fmt.Errorf("failed to load streak freezes: %w", err)
+	}
+
+	update := advanceStreak(s, freezes, now)
+	s = update.Streak
+	if _, err := tx.Exec(ctx, `
+		UPDATE user_streaks
+		SET current_streak = $3, best_streak = $4, times_broken = $5, last_claim = $6, updated_at = NOW()
+		WHERE user_id = $1 AND streak_type = $2`,
+		userID, streakType, s.Current, s.Best, s.TimesBroken, s.LastClaim); err != nil {
+		return nil, fmt.Errorf("failed to update streak: %w", err)
+	}
+	if update.FreezesLeft != freezes {
+		if _, err := tx.Exec(ctx, `UPDATE streak_freezes SET freezes = $2, updated_at = NOW() WHERE user_id = $1`, userID, update.FreezesLeft); err != nil {
+			return nil, fmt.Errorf("failed to update streak freezes: %w", err)
+		}
+	}
+
+	var user *User
+	if update.Milestone != nil && update.Milestone.Chips > 0 {
+		updates := UserUpdateData{
+			ChipsIncrement: update.Milestone.Chips,
+			Reason:         TxReasonBonus,
+			Note:           fmt.Sprintf("%s streak %d", streakType, s.Current),
+		}
+		setParts, args := buildUserUpdateSet(userID, updates)
+		if user, err = updateUserInTx(ctx, tx, setParts, args); err != nil {
+			return nil, err
+		}
+		if err := recordChipTransaction(ctx, tx, user, updates); err != nil {
+			PutUserToPool(user)
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit streak: %w", err)
+	}
+
+	if user != nil {
+		afterUserUpdate(user, session, interaction)
+	}
+	EmitAchievementEvent(session, interaction, AchievementEvent{
+		Type:        EventStreakUpdated,
+		UserID:      userID,
+		StreakType:  streakType,
+		Streak:      s.Current,
+		BrokenFrom:  update.BrokenFrom,
+		TimesBroken: s.TimesBroken,
+		At:          now,
+	})
+	return &update, nil
+}
+
+// GetStreaks returns a user's streaks keyed by type, zeroed for streaks never started,
+// along with how many freezes they hold
+func GetStreaks(userID int64) (map[StreakType]*Streak, int, error) {
+	streaks := make(map[StreakType]*Streak, len(streakRules))
+	for streakType := range streakRules {
+		streaks[streakType] = &Streak{UserID: userID, Type: streakType}
+	}
+	if DB == nil {
+		return streaks, 0, nil
+	}
+
+	ctx := context.Background()
+	rows, err := DB.Query(ctx, `
+		SELECT streak_type, current_streak, best_streak, times_broken, last_claim
+		FROM user_streaks WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get streaks: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var s Streak
+		if err := rows.Scan(&s.Type, &s.Current, &s.Best, &s.TimesBroken, &s.LastClaim); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan streak: %w", err)
+		}
+		s.UserID = userID
+		streaks[s.Type] = &s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to get streaks: %w", err)
+	}
+
+	var freezes int
+	err = DB.QueryRow(ctx, `SELECT freezes FROM streak_freezes WHERE user_id = $1`, userID).Scan(&freezes)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, 0, fmt.Errorf("failed to get streak freezes: %w", err)
+	}
+	return streaks, freezes, nil
+}
+
+// formatStreak renders a streak for bonus embeds, e.g. "🔥 12 days (best 30)"
+func formatStreak(current, best int, unit string) string {
+	plural := func(n int) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, unit)
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+	return fmt.Sprintf("🔥 %s (best %d)", plural(current), best)
+}
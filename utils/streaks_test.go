@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdvanceStreakGraceFreezesAndBreaks(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := Streak{Type: StreakDaily}
+
+	u := advanceStreak(s, 0, start)
+	if u.Streak.Current != 1 || u.Streak.Best != 1 {
+		t.Fatalf("first claim: %+v", u.Streak)
+	}
+
+	// A second claim in the same day does not count
+	u = advanceStreak(u.Streak, 0, start.Add(2*time.Hour))
+	if u.Streak.Current != 1 {
+		t.Fatalf("same-day claim: current=%d", u.Streak.Current)
+	}
+
+	// Late but inside the grace window still extends
+	u = advanceStreak(u.Streak, 0, start.Add(47*time.Hour))
+	if u.Streak.Current != 2 || u.FreezesUsed != 0 {
+		t.Fatalf("grace claim: %+v", u)
+	}
+
+	// Missing a whole day spends a freeze
+	last := *u.Streak.LastClaim
+	u = advanceStreak(u.Streak, 1, last.Add(60*time.Hour))
+	if u.Streak.Current != 3 || u.FreezesUsed != 1 || u.FreezesLeft != 0 {
+		t.Fatalf("frozen claim: %+v", u)
+	}
+
+	// Without freezes the streak breaks and is recorded
+	last = *u.Streak.LastClaim
+	u = advanceStreak(u.Streak, 0, last.Add(72*time.Hour))
+	if u.Streak.Current != 1 || u.BrokenFrom != 3 || u.Streak.TimesBroken != 1 || u.Streak.Best != 3 {
+		t.Fatalf("broken claim: %+v", u)
+	}
+}
+
+func TestAdvanceStreakMilestoneGrantsFreeze(t *testing.T) {
+	last := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := Streak{Type: StreakDaily, Current: 6, Best: 6, LastClaim: &last}
+
+	u := advanceStreak(s, MaxStreakFreezes-1, last.Add(25*time.Hour))
+	if u.Milestone == nil || u.Milestone.Length != 7 {
+		t.Fatalf("expected 7-day milestone, got %+v", u.Milestone)
+	}
+	if u.FreezesLeft != MaxStreakFreezes {
+		t.Fatalf("freezes left = %d", u.FreezesLeft)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 	"time"
 )
 
@@ -21,9 +20,8 @@ type TopGGVoteResponse struct {
 	Voted int `json:"voted"` // 0 = hasn't voted, 1 = has voted
 }
 
-// NewTopGGClient creates a new Top.gg client
-func NewTopGGClient(botID string) *TopGGClient {
-	apiToken := os.Getenv("TOPGG_TOKEN")
+// NewTopGGClient creates a new Top.gg client, or nil without an API token
+func NewTopGGClient(botID, apiToken string) *TopGGClient {
 	if apiToken == "" {
 		return nil
 	}
@@ -83,8 +81,8 @@ func (c *TopGGClient) GetVoteURL() string {
 var GlobalTopGGClient *TopGGClient
 
 // InitializeTopGGClient initializes the global Top.gg client
-func InitializeTopGGClient(botID string) {
-	GlobalTopGGClient = NewTopGGClient(botID)
+func InitializeTopGGClient(botID, apiToken string) {
+	GlobalTopGGClient = NewTopGGClient(botID, apiToken)
 	if GlobalTopGGClient != nil {
 	}
 }
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// TopGGVotePayload is the body Top.gg posts to the webhook for each vote
+type TopGGVotePayload struct {
+	Bot       string `json:"bot"`
+	User      string `json:"user"`
+	Type      string `json:"type"` // "upvote", or "test" from the dashboard's test button
+	IsWeekend bool   `json:"isWeekend"`
+	Query     string `json:"query"`
+}
+
+// TopGGWebhook credits the vote bonus as soon as Top.gg reports a vote, so players are
+// paid even if they never run /vote. Retried deliveries for a vote already credited are
+// acknowledged without paying again.
+type TopGGWebhook struct {
+	Secret            string        // must match the Authorization header set in the Top.gg dashboard
+	WeekendMultiplier float64       // scales weekend votes; 0 or 1 pays weekends like any other day
+	VoteCooldown      time.Duration // a repeat delivery inside this window is not paid again
+	Credit            func(userID int64, scale float64) (*BonusResult, error)
+	Notify            func(userID string, result *BonusResult, weekend bool)
+
+	mu       sync.Mutex
+	credited map[string]time.Time
+}
+
+// NewTopGGWebhook wires a webhook to the bonus system, DMing voters through the session
+// returned by getSession once the bot is connected
+func NewTopGGWebhook(secret string, weekendMultiplier float64, voteCooldown time.Duration, getSession func() *discordgo.Session) *TopGGWebhook {
+	return &TopGGWebhook{
+		Secret:            secret,
+		WeekendMultiplier: weekendMultiplier,
+		VoteCooldown:      voteCooldown,
+		Credit: func(userID int64, scale float64) (*BonusResult, error) {
+			// Restricted players' votes are acknowledged but not paid; if restrictions can't be
+			// read the delivery fails so Top.gg retries it
+			if err := CheckPlayAllowed(userID); errors.Is(err, ErrRestrictionUnavailable) {
+				return nil, err
+			} else if err != nil {
+				return nil, nil
+			}
+			user, err := GetCachedUser(userID)
+			if err != nil {
+				return nil, err
+			}
+			return BonusMgr.ClaimScaledBonus(user, BonusVote, scale, nil, nil)
+		},
+		Notify: func(userID string, result *BonusResult, weekend bool) {
+			if s := getSession(); s != nil {
+				if err := SendVoteRewardDM(s, userID, result, weekend); err != nil {
+					log.Printf("⚠️ Vote reward DM to %s failed: %v", userID, err)
+				}
+			}
+		},
+	}
+}
+
+func (h *TopGGWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.Secret == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(h.Secret)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var vote TopGGVotePayload
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&vote); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	userID, err := strconv.ParseInt(vote.User, 10, 64)
+	if err != nil {
+		http.Error(w, "invalid user", http.StatusBadRequest)
+		return
+	}
+	if vote.Type == "test" {
+		log.Printf("Top.gg test webhook received for user %s", vote.User)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !h.claim(vote.User) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	scale := 1.0
+	if vote.IsWeekend && h.WeekendMultiplier > 1 {
+		scale = h.WeekendMultiplier
+	}
+	result, err := h.Credit(userID, scale)
+	if err != nil {
+		// Let Top.gg retry the delivery
+		h.release(vote.User)
+		log.Printf("⚠️ Top.gg vote credit failed for user %s: %v", vote.User, err)
+		http.Error(w, "credit failed", http.StatusInternalServerError)
+		return
+	}
+	if result != nil && result.Success && h.Notify != nil {
+		go h.Notify(vote.User, result, scale > 1)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// claim marks a user's vote as being credited, returning false for a delivery already
+// handled inside the vote cooldown
+func (h *TopGGWebhook) claim(userID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if h.credited == nil {
+		h.credited = make(map[string]time.Time)
+	}
+	for id, at := range h.credited {
+		if now.Sub(at) >= h.VoteCooldown {
+			delete(h.credited, id)
+		}
+	}
+	if _, dup := h.credited[userID]; dup {
+		return false
+	}
+	h.credited[userID] = now
+	return true
+}
+
+func (h *TopGGWebhook) release(userID string) {
+	h.mu.Lock()
+	delete(h.credited, userID)
+	h.mu.Unlock()
+}
+
+// SendVoteRewardDM thanks a voter by DM and tells them what the webhook credited
+func SendVoteRewardDM(session *discordgo.Session, userID string, result *BonusResult, weekend bool) error {
+	if result == nil || result.BonusInfo == nil {
+		return nil
+	}
+
+	description := fmt.Sprintf("Thank you for voting! Your vote bonus has been added automatically.\n\nYou gained **%s** %s chips.",
+		FormatChips(result.BonusInfo.ActualAmount), ChipsEmoji)
+	if weekend {
+		description += "\n\n🎉 Weekend votes pay double!"
+	}
+	if update := result.BonusInfo.Streak; update != nil {
+		description += "\n\n" + formatStreak(update.Streak.Current, update.Streak.Best, StreakUnit(update.Streak.Type))
+	}
+	description += fmt.Sprintf("\n\nYou can vote again <t:%d:R>.", result.BonusInfo.NextAvailable.Unix())
+	embed := CreateBrandedEmbed("🗳️ Vote Bonus Credited!", description, 0x00FF00)
+
+	dmChannel, err := session.UserChannelCreate(userID)
+	if err != nil {
+		return fmt.Errorf("failed to create DM channel: %w", err)
+	}
+	if _, err := session.ChannelMessageSendEmbed(dmChannel.ID, embed); err != nil {
+		return fmt.Errorf("failed to send DM notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTopGGWebhookCreditsOncePerVote(t *testing.T) {
+	var credits []float64
+	hook := &TopGGWebhook{
+		Secret:            "s3cret",
+		WeekendMultiplier: 2,
+		VoteCooldown:      time.Hour,
+		Credit: func(userID int64, scale float64) (*BonusResult, error) {
+			if userID != 42 {
+				t.Fatalf("credited user %d", userID)
+			}
+			credits = append(credits, scale)
+			return &BonusResult{Success: true}, nil
+		},
+	}
+	srv := httptest.NewServer(hook)
+	defer srv.Close()
+
+	post := func(auth, body string) int {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(body))
+		req.Header.Set("Authorization", auth)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	vote := `{"bot":"1","user":"42","type":"upvote","isWeekend":true}`
+	if code := post("wrong", vote); code != http.StatusUnauthorized {
+		t.Fatalf("bad secret: status %d", code)
+	}
+	if code := post("s3cret", `{"user":"42","type":"test"}`); code != http.StatusNoContent {
+		t.Fatalf("test vote: status %d", code)
+	}
+	if code := post("s3cret", vote); code != http.StatusNoContent {
+		t.Fatalf("vote: status %d", code)
+	}
+	// Top.gg retries are acknowledged without paying again
+	if code := post("s3cret", vote); code != http.StatusNoContent {
+		t.Fatalf("retry: status %d", code)
+	}
+
+	if len(credits) != 1 || credits[0] != 2 {
+		t.Fatalf("credits = %v, want one weekend credit", credits)
+	}
+}
@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Ledger reasons describing why a user's chip balance moved
+const (
+	TxReasonGame        = "game"
+	TxReasonBet         = "bet"
+	TxReasonRefund      = "refund"
+	TxReasonPayout      = "payout"
+	TxReasonJackpot     = "jackpot"
+	TxReasonBonus       = "bonus"
+	TxReasonAchievement = "achievement"
+	TxReasonAdminGrant  = "admin_grant"
+	TxReasonPrestige    = "prestige"
+	TxReasonAdjustment  = "adjustment"
+	TxReasonTransferOut = "transfer_out"
+	TxReasonTransferIn  = "transfer_in"
+	TxReasonSeason      = "season_reward"
+)
+
+// Default and maximum page sizes for ledger queries
+const (
+	DefaultTransactionLimit = 25
+	MaxTransactionLimit     = 500
+)
+
+// ChipTransaction is a single append-only entry in the chip ledger
+type ChipTransaction struct {
+	ID           int64
+	UserID       int64
+	Delta        int64
+	BalanceAfter int64
+	Reason       string
+	GameType     string
+	GameID       string
+	Note         string
+	CreatedAt    time.Time
+}
+
+// TransactionFilter narrows a GetUserTransactions query; zero values are ignored
+type TransactionFilter struct {
+	Reason   string
+	GameType string
+	GameID   string
+	Since    *time.Time
+	Until    *time.Time
+	Limit    int
+	Offset   int
+}
+
+// recordChipTransaction appends a ledger row inside the caller's transaction so the
+// entry commits or rolls back together with the users update
+func recordChipTransaction(ctx context.Context, tx pgx.Tx, user *User, updates UserUpdateData) error {
+	reason := updates.Reason
+	if reason == "" {
+		reason = TxReasonAdjustment
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO chip_transactions (user_id, delta, balance_after, reason, game_type, game_id, note)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''))`,
+		user.UserID, updates.ChipsIncrement, user.Chips, reason, updates.GameType, updates.GameID, updates.Note)
+	if err != nil {
+		return fmt.Errorf("failed to record chip transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserTransactions returns a user's ledger entries, newest first
+func GetUserTransactions(userID int64, filter TransactionFilter) ([]ChipTransaction, error) {
+	if DB == nil {
+		return []ChipTransaction{}, nil
+	}
+
+	ctx := context.Background()
+
+	where := []string{"user_id = $1"}
+	args := []interface{}{userID}
+	addCond := func(cond string, value interface{}) {
+		args = append(args, value)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.Reason != "" {
+		addCond("reason = $%d", filter.Reason)
+	}
+	if filter.GameType != "" {
+		addCond("game_type = $%d", filter.GameType)
+	}
+	if filter.GameID != "" {
+		addCond("game_id = $%d", filter.GameID)
+	}
+	if filter.Since != nil {
+		addCond("created_at >= $%d", *filter.Since)
+	}
+	if filter.Until != nil {
+		addCond("created_at < $%d", *filter.Until)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultTransactionLimit
+	}
+	if limit > MaxTransactionLimit {
+		limit = MaxTransactionLimit
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, user_id, delta, balance_after, reason,
+			   COALESCE(game_type, ''), COALESCE(game_id, ''), COALESCE(note, ''), created_at
+		FROM chip_transactions
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT %d OFFSET %d`,
+		strings.Join(where, " AND "), limit, offset)
+
+	rows, err := DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chip transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := make([]ChipTransaction, 0, limit)
+	for rows.Next() {
+		var t ChipTransaction
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Delta, &t.BalanceAfter, &t.Reason,
+			&t.GameType, &t.GameID, &t.Note, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan chip transaction: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate chip transactions: %w", err)
+	}
+
+	return transactions, nil
+}
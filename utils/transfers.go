@@ -0,0 +1,260 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jackc/pgx/v5"
+)
+
+// Chip transfer errors, shown to players as-is
+var (
+	ErrTransferSelf              = errors.New("you can't give chips to yourself")
+	ErrTransferTooSmall          = errors.New("transfer is below the minimum")
+	ErrTransferInsufficientChips = errors.New("you don't have enough chips")
+	ErrTransferUnknownRecipient  = errors.New("that player hasn't played yet")
+	ErrTransferSendCap           = errors.New("you've reached your daily sending limit")
+	ErrTransferReceiveCap        = errors.New("that player has reached their daily receiving limit")
+	ErrTransferSenderIneligible  = errors.New("your account is too new to send chips")
+	ErrTransferRecipientNew      = errors.New("that player's account is too new to receive chips")
+)
+
+// TransferConfig sets the house rules for player-to-player transfers
+type TransferConfig struct {
+	TaxRate          float64       `yaml:"tax_rate"`          // share of each transfer kept by the house
+	MinAmount        int64         `yaml:"min_amount"`        // smallest transfer allowed
+	DailySendCap     int64         `yaml:"daily_send_cap"`    // most a player may send in a rolling 24 hours
+	DailyReceiveCap  int64         `yaml:"daily_receive_cap"` // most a player may receive in a rolling 24 hours
+	MinAccountAge    time.Duration `yaml:"min_account_age"`   // both accounts must be at least this old
+	MinGamesPlayed   int           `yaml:"min_games_played"`  // both accounts must have finished this many games
+	ConfirmThreshold int64         `yaml:"confirm_threshold"` // transfers this large ask the sender to confirm
+}
+
+// DefaultTransferConfig returns the built-in transfer rules
+func DefaultTransferConfig() TransferConfig {
+	return TransferConfig{
+		TaxRate:          0.05,
+		MinAmount:        100,
+		DailySendCap:     250000,
+		DailyReceiveCap:  250000,
+		MinAccountAge:    7 * 24 * time.Hour,
+		MinGamesPlayed:   25,
+		ConfirmThreshold: 50000,
+	}
+}
+
+// TransferTax is the house cut of a transfer
+func (c TransferConfig) TransferTax(amount int64) int64 {
+	return int64(float64(amount) * c.TaxRate)
+}
+
+// TransferResult is a completed transfer
+type TransferResult struct {
+	Amount    int64 // debited from the sender
+	Tax       int64
+	Received  int64 // credited to the recipient
+	Sender    *User
+	Recipient *User
+}
+
+// transferParty is the locked state a transfer is checked against
+type transferParty struct {
+	chips     int64
+	games     int
+	createdAt time.Time
+}
+
+// eligible applies the anti-alt account age and activity checks
+func (c TransferConfig) eligible(p transferParty, now time.Time) bool {
+	return now.Sub(p.createdAt) >= c.MinAccountAge && p.games >= c.MinGamesPlayed
+}
+
+// check applies every transfer rule to the locked parties, given how much each has already
+// sent or received in the last 24 hours
+func (c TransferConfig) check(sender, recipient transferParty, amount, sent, received int64, now time.Time) error {
+	switch {
+	case !c.eligible(sender, now):
+		return ErrTransferSenderIneligible
+	case !c.eligible(recipient, now):
+		return ErrTransferRecipientNew
+	case sender.chips < amount:
+		return ErrTransferInsufficientChips
+	case sent+amount > c.DailySendCap:
+		return ErrTransferSendCap
+	case received+amount-c.TransferTax(amount) > c.DailyReceiveCap:
+		return ErrTransferReceiveCap
+	}
+	return nil
+}
+
+// ledger builds the sender's debit and the recipient's credit; the tax is the difference
+func (c TransferConfig) ledger(fromID, toID, amount int64) (debit, credit UserUpdateData) {
+	tax := c.TransferTax(amount)
+	debit = UserUpdateData{ChipsIncrement: -amount, Reason: TxReasonTransferOut, Note: fmt.Sprintf("to %d (tax %d)", toID, tax)}
+	credit = UserUpdateData{ChipsIncrement: amount - tax, Reason: TxReasonTransferIn, Note: fmt.Sprintf("from %d", fromID)}
+	return debit, credit
+}
+
+// TransferChips moves chips from one player to another in a single transaction, keeping
+// the house tax and enforcing the daily caps and anti-alt checks under row locks
+func (c TransferConfig) TransferChips(fromID, toID, amount int64, session *discordgo.Session, interaction *discordgo.InteractionCreate) (*TransferResult, error) {
+	if fromID == toID {
+		return nil, ErrTransferSelf
+	}
+	if amount < c.MinAmount || amount <= 0 {
+		return nil, ErrTransferTooSmall
+	}
+	if DB == nil {
+		return nil, fmt.Errorf("database not connected")
+	}
+
+	ctx := context.Background()
+	tx, err := DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock both rows in a fixed order so opposing transfers cannot deadlock
+	rows, err := tx.Query(ctx, `
+		SELECT user_id, chips, wins + losses, created_at FROM users
+		WHERE user_id = ANY($1) ORDER BY user_id FOR UPDATE`, []int64{fromID, toID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock transfer users: %w", err)
+	}
+	parties := make(map[int64]transferParty, 2)
+	for rows.Next() {
+		var id int64
+		var p transferParty
+		if err := rows.Scan(&id, &p.chips, &p.games, &p.createdAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan transfer user: %w", err)
+		}
+		parties[id] = p
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to lock transfer users: %w", err)
+	}
+
+	sender, ok := parties[fromID]
+	if !ok {
+		return nil, ErrTransferInsufficientChips
+	}
+	recipient, ok := parties[toID]
+	if !ok {
+		return nil, ErrTransferUnknownRecipient
+	}
+
+	now := time.Now()
+	sent, err := transferredSince(ctx, tx, fromID, TxReasonTransferOut, now.Add(-24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	got, err := transferredSince(ctx, tx, toID, TxReasonTransferIn, now.Add(-24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	if err := c.check(sender, recipient, amount, sent, got, now); err != nil {
+		return nil, err
+	}
+
+	debit, credit := c.ledger(fromID, toID, amount)
+	result := &TransferResult{Amount: amount, Tax: amount - credit.ChipsIncrement, Received: credit.ChipsIncrement}
+	if result.Sender, err = applyUserUpdateInTx(ctx, tx, fromID, debit); err != nil {
+		return nil, err
+	}
+	if result.Recipient, err = applyUserUpdateInTx(ctx, tx, toID, credit); err != nil {
+		PutUserToPool(result.Sender)
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		PutUserToPool(result.Sender)
+		PutUserToPool(result.Recipient)
+		return nil, fmt.Errorf("failed to commit transfer: %w", err)
+	}
+
+	afterUserUpdate(result.Sender, session, interaction)
+	afterUserUpdate(result.Recipient, nil, nil)
+	EmitAchievementEvent(session, interaction, AchievementEvent{Type: EventChipsGiven, UserID: fromID, Bet: amount, At: now})
+	return result, nil
+}
+
+// applyUserUpdateInTx writes a user update, and a ledger row for any chip change, inside tx
+func applyUserUpdateInTx(ctx context.Context, tx pgx.Tx, userID int64, updates UserUpdateData) (*User, error) {
+	setParts, args := buildUserUpdateSet(userID, updates)
+	user, err := updateUserInTx(ctx, tx, setParts, args)
+	if err != nil {
+		return nil, err
+	}
+	if updates.ChipsIncrement != 0 {
+		if err := recordChipTransaction(ctx, tx, user, updates); err != nil {
+			PutUserToPool(user)
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// transferredSince totals a user's transfer ledger entries of one direction since a time
+func transferredSince(ctx context.Context, tx pgx.Tx, userID int64, reason string, since time.Time) (int64, error) {
+	var total int64
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(ABS(delta)), 0) FROM chip_transactions
+		WHERE user_id = $1 AND reason = $2 AND created_at > $3`,
+		userID, reason, since).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to total transfers: %w", err)
+	}
+	return total, nil
+}
+
+// TransferConfirmWindow is how long a large transfer waits for the sender to confirm it
+const TransferConfirmWindow = 5 * time.Minute
+
+// PendingTransfer is a large transfer waiting for the sender's confirmation
+type PendingTransfer struct {
+	FromID    int64
+	ToID      int64
+	Amount    int64
+	ExpiresAt time.Time
+}
+
+var pendingTransfers = struct {
+	sync.Mutex
+	byToken map[string]PendingTransfer
+}{byToken: make(map[string]PendingTransfer)}
+
+// HoldTransfer parks a transfer until the sender confirms it and returns its token
+func HoldTransfer(fromID, toID, amount int64, now time.Time) string {
+	var b [8]byte
+	_, _ = rand.Read(b[:]) // crypto/rand.Read never returns an error
+	token := hex.EncodeToString(b[:])
+
+	pendingTransfers.Lock()
+	defer pendingTransfers.Unlock()
+	for t, p := range pendingTransfers.byToken {
+		if now.After(p.ExpiresAt) {
+			delete(pendingTransfers.byToken, t)
+		}
+	}
+	pendingTransfers.byToken[token] = PendingTransfer{FromID: fromID, ToID: toID, Amount: amount, ExpiresAt: now.Add(TransferConfirmWindow)}
+	return token
+}
+
+// TakeTransfer removes a pending transfer and returns it, so a token is only ever
+// honoured once; expired or already taken tokens report false
+func TakeTransfer(token string, now time.Time) (PendingTransfer, bool) {
+	pendingTransfers.Lock()
+	defer pendingTransfers.Unlock()
+	p, ok := pendingTransfers.byToken[token]
+	delete(pendingTransfers.byToken, token)
+	return p, ok && !now.After(p.ExpiresAt)
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTransferConfigTaxAndEligibility(t *testing.T) {
+	cfg := TransferConfig{TaxRate: 0.05, MinAccountAge: 7 * 24 * time.Hour, MinGamesPlayed: 25}
+	if tax := cfg.TransferTax(10000); tax != 500 {
+		t.Fatalf("tax = %d, want 500", tax)
+	}
+
+	now := time.Now()
+	veteran := transferParty{games: 30, createdAt: now.Add(-30 * 24 * time.Hour)}
+	if !cfg.eligible(veteran, now) {
+		t.Fatal("veteran account should be eligible")
+	}
+	fresh := transferParty{games: 100, createdAt: now.Add(-time.Hour)}
+	if cfg.eligible(fresh, now) {
+		t.Fatal("new account should not be eligible")
+	}
+	idle := transferParty{games: 3, createdAt: now.Add(-30 * 24 * time.Hour)}
+	if cfg.eligible(idle, now) {
+		t.Fatal("account with few games should not be eligible")
+	}
+}
+
+func TestTransferConfigEnforcesDailyCaps(t *testing.T) {
+	cfg := TransferConfig{TaxRate: 0.05, DailySendCap: 10000, DailyReceiveCap: 9000}
+	now := time.Now()
+	rich := transferParty{chips: 1000000, createdAt: now.Add(-time.Hour)}
+
+	if err := cfg.check(rich, rich, 5000, 5000, 0, now); err != nil {
+		t.Fatalf("transfer up to the send cap refused: %v", err)
+	}
+	if err := cfg.check(rich, rich, 5001, 5000, 0, now); err != ErrTransferSendCap {
+		t.Fatalf("err = %v, want ErrTransferSendCap", err)
+	}
+	// The receive cap counts what arrives after tax: 4,750 + 4,250 fits, one more chip doesn't
+	if err := cfg.check(rich, rich, 5000, 0, 4250, now); err != nil {
+		t.Fatalf("transfer up to the receive cap refused: %v", err)
+	}
+	if err := cfg.check(rich, rich, 5000, 0, 4251, now); err != ErrTransferReceiveCap {
+		t.Fatalf("err = %v, want ErrTransferReceiveCap", err)
+	}
+	poor := transferParty{chips: 100, createdAt: now.Add(-time.Hour)}
+	if err := cfg.check(poor, rich, 5000, 0, 0, now); err != ErrTransferInsufficientChips {
+		t.Fatalf("err = %v, want ErrTransferInsufficientChips", err)
+	}
+}
+
+func TestTransferLedgerRowsKeepTheTax(t *testing.T) {
+	cfg := TransferConfig{TaxRate: 0.05}
+	debit, credit := cfg.ledger(1, 2, 10000)
+	if debit.ChipsIncrement != -10000 || debit.Reason != TxReasonTransferOut {
+		t.Errorf("debit = %+v", debit)
+	}
+	if credit.ChipsIncrement != 9500 || credit.Reason != TxReasonTransferIn {
+		t.Errorf("credit = %+v", credit)
+	}
+	if debit.Note != "to 2 (tax 500)" || credit.Note != "from 1" {
+		t.Errorf("notes = %q, %q", debit.Note, credit.Note)
+	}
+}
+
+func TestPendingTransferIsSingleUse(t *testing.T) {
+	now := time.Now()
+	token := HoldTransfer(1, 2, 60000, now)
+	p, ok := TakeTransfer(token, now)
+	if !ok || p.FromID != 1 || p.ToID != 2 || p.Amount != 60000 {
+		t.Fatalf("TakeTransfer = %+v, %v", p, ok)
+	}
+	if _, ok := TakeTransfer(token, now); ok {
+		t.Fatal("a confirmed transfer was taken twice")
+	}
+
+	expired := HoldTransfer(1, 2, 60000, now)
+	if _, ok := TakeTransfer(expired, now.Add(TransferConfirmWindow+time.Second)); ok {
+		t.Fatal("an expired transfer was taken")
+	}
+}
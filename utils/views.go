@@ -1,10 +1,12 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -107,6 +109,7 @@ func CreateSelectMenu(customID, placeholder string, options []discordgo.SelectMe
 type BlackjackView struct {
 	UserID    int64
 	GameID    string
+	Prefix    string // custom ID prefix routing the buttons; "blackjack_" when empty
 	CanHit    bool
 	CanStand  bool
 	CanDouble bool
@@ -119,6 +122,7 @@ func NewBlackjackView(userID int64, gameID string) *BlackjackView {
 	return &BlackjackView{
 		UserID:    userID,
 		GameID:    gameID,
+		Prefix:    "blackjack_",
 		CanHit:    true,
 		CanStand:  true,
 		CanDouble: false,
@@ -133,7 +137,7 @@ func (bv *BlackjackView) GetComponents() []discordgo.MessageComponent {
 
 	// Hit button
 	hitButton := CreateButton(
-		"blackjack_hit",
+		bv.customID("hit"),
 		"Hit",
 		discordgo.PrimaryButton,
 		!bv.CanHit,
@@ -143,7 +147,7 @@ func (bv *BlackjackView) GetComponents() []discordgo.MessageComponent {
 
 	// Stand button
 	standButton := CreateButton(
-		"blackjack_stand",
+		bv.customID("stand"),
 		"Stand",
 		discordgo.SecondaryButton,
 		!bv.CanStand,
@@ -154,7 +158,7 @@ func (bv *BlackjackView) GetComponents() []discordgo.MessageComponent {
 	// Double button
 	if bv.CanDouble {
 		doubleButton := CreateButton(
-			"blackjack_double",
+			bv.customID("double"),
 			"Double Down",
 			discordgo.SuccessButton,
 			false,
@@ -166,7 +170,7 @@ func (bv *BlackjackView) GetComponents() []discordgo.MessageComponent {
 	// Split button
 	if bv.CanSplit {
 		splitButton := CreateButton(
-			"blackjack_split",
+			bv.customID("split"),
 			"Split",
 			discordgo.SuccessButton,
 			false,
@@ -178,7 +182,7 @@ func (bv *BlackjackView) GetComponents() []discordgo.MessageComponent {
 	// Insurance button
 	if bv.CanInsure {
 		insuranceButton := CreateButton(
-			"blackjack_insurance",
+			bv.customID("insurance"),
 			"Insurance",
 			discordgo.SecondaryButton,
 			false,
@@ -190,6 +194,13 @@ func (bv *BlackjackView) GetComponents() []discordgo.MessageComponent {
 	return []discordgo.MessageComponent{CreateActionRow(buttons...)}
 }
 
+func (bv *BlackjackView) customID(action string) string {
+	if bv.Prefix == "" {
+		return "blackjack_" + action
+	}
+	return bv.Prefix + action
+}
+
 // DisableAllButtons disables all buttons in the view
 func (bv *BlackjackView) DisableAllButtons() []discordgo.MessageComponent {
 	bv.CanHit = false
@@ -715,6 +726,7 @@ type AsyncTaskManager struct {
 	taskQueue chan func()
 	workers   int
 	done      chan bool
+	running   atomic.Int64 // tasks a worker is executing
 }
 
 var TaskMgr *AsyncTaskManager
@@ -738,7 +750,9 @@ func (atm *AsyncTaskManager) worker() {
 	for {
 		select {
 		case task := <-atm.taskQueue:
+			atm.running.Add(1)
 			func() {
+				defer atm.running.Add(-1)
 				defer func() {
 					if r := recover(); r != nil {
 						BotLogf("ASYNC_TASK", "Task panicked: %v", r)
@@ -763,6 +777,26 @@ func (atm *AsyncTaskManager) SubmitTask(task func()) bool {
 	}
 }
 
+// QueueDepth is how many submitted tasks are waiting for a worker
+func (atm *AsyncTaskManager) QueueDepth() int {
+	return len(atm.taskQueue)
+}
+
+// Drain waits until the queue is empty and no task is running, returning false if ctx
+// ends first
+func (atm *AsyncTaskManager) Drain(ctx context.Context) bool {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for atm.QueueDepth() > 0 || atm.running.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
+
 // SubmitHighPriorityTask processes a task immediately in a new goroutine
 func (atm *AsyncTaskManager) SubmitHighPriorityTask(task func()) {
 	go func() {